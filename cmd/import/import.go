@@ -1,13 +1,55 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"strings"
+	"swift-codes/internal/country"
 	"swift-codes/internal/db"
+	"swift-codes/internal/middleware"
+	"swift-codes/internal/model"
 	"swift-codes/internal/parser"
+	"text/tabwriter"
+	"time"
 )
 
+const usage = `Użycie: swift-codes-import [flagi] [polecenie] [argumenty]
+
+Polecenia:
+  import                wczytuje plik CSV jako nową wersję danych, sprawdza ją
+                        i aktywuje (domyślne polecenie)
+  snapshots             lista wersji danych
+  diff OD [DO]          różnice między wersjami (DO domyślnie: wersja aktywna)
+  activate NAZWA        aktywuje wersję, np. aby wrócić do poprzedniej
+  delete NAZWA          usuwa nieaktywną wersję
+  bank-codes PLIK       wczytuje tabelę krajowych identyfikatorów banków
+                        (numer rozliczeniowy -> kod SWIFT), zastępując wpisy
+                        krajów obecnych w pliku
+
+Flagi:
+`
+
 func main() {
+	flag.Bool("import", true, "importuje dane z pliku CSV (zachowane dla zgodności z entrypoint.sh)")
+	filePath := flag.String("file", "data/swiftcodes_data.csv", "ścieżka do pliku CSV")
+	snapshot := flag.String("snapshot", "", "nazwa nowej wersji danych (domyślnie data i czas importu)")
+	activate := flag.Bool("activate", true, "aktywuje wersję po udanej walidacji")
+	minRatio := flag.Float64("min-ratio", 0.5, "najmniejszy stosunek liczby rekordów nowej wersji do aktywnej")
+	force := flag.Bool("force", false, "aktywuje wersję mimo zmian wprowadzonych przez API w wersji aktywnej (zostają w poprzedniej wersji)")
+	purgeURL := flag.String("cache-purge-url", os.Getenv("CACHE_PURGE_URL"), "adres DELETE /v1/admin/cache serwera, którego cache należy wyczyścić po aktywacji")
+	apiKey := flag.String("api-key", os.Getenv("SWIFT_API_KEY"), "klucz API z rolą admin wysyłany przy czyszczeniu cache serwera")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	connStr := os.Getenv("DB_CONN")
 	if connStr == "" {
 		log.Fatal("Brak ustawionej zmiennej środowiskowej DB_CONN")
@@ -19,16 +61,209 @@ func main() {
 	}
 	defer database.Close()
 
-	filePath := "data/swiftcodes_data.csv"
+	args := flag.Args()
+	command := "import"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	activated := false
+	switch {
+	case command == "import" && len(args) == 0:
+		name := *snapshot
+		if name == "" {
+			name = time.Now().UTC().Format("20060102-150405")
+		}
+		activated, err = importSnapshot(database, *filePath, name, *activate, *force, *minRatio)
+	case command == "snapshots" && len(args) == 0:
+		err = listSnapshots(database)
+	case command == "diff" && (len(args) == 1 || len(args) == 2):
+		err = diffSnapshots(database, args)
+	case command == "activate" && len(args) == 1:
+		err = activateSnapshot(database, args[0], *force)
+		activated = err == nil
+	case command == "bank-codes" && len(args) == 1:
+		err = importBankCodes(database, args[0])
+	case command == "delete" && len(args) == 1:
+		err = db.DeleteSnapshot(database, args[0])
+		if errors.Is(err, db.ErrSnapshotState) {
+			err = fmt.Errorf("wersji %s nie można usunąć (wersja aktywna lub w trakcie wczytywania)", args[0])
+		} else if err == nil {
+			log.Printf("Usunięto wersję %s.", args[0])
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Błąd: %v", err)
+	}
+
+	if activated && *purgeURL != "" {
+		if err := purgeCache(*purgeURL, *apiKey); err != nil {
+			log.Printf("Nie udało się wyczyścić cache serwera: %v", err)
+		} else {
+			log.Println("Cache serwera został wyczyszczony.")
+		}
+	}
+}
+
+// importSnapshot wczytuje plik CSV do nowej wersji danych i ją sprawdza.
+// Zwraca true, jeśli wersja została aktywowana.
+func importSnapshot(database *sql.DB, filePath, name string, activate, force bool, minRatio float64) (bool, error) {
 	swiftRecords, err := parser.ParseCSV(filePath)
 	if err != nil {
-		log.Fatalf("Błąd parsowania CSV: %v", err)
+		return false, fmt.Errorf("błąd parsowania CSV: %w", err)
+	}
+	records := snapshotRecords(swiftRecords)
+
+	if _, err := db.CreateSnapshot(database, name, filePath); err != nil {
+		return false, err
+	}
+	if err := db.LoadSnapshot(database, name, records); err != nil {
+		// Walidacja pustej wersji oznacza ją jako odrzuconą, więc można ją
+		// potem usunąć.
+		if _, verr := db.ValidateSnapshot(context.Background(), database, name, minRatio); verr != nil {
+			log.Printf("Nie udało się oznaczyć wersji %s jako odrzuconej: %v", name, verr)
+		}
+		return false, fmt.Errorf("błąd zapisu wersji %s: %w", name, err)
+	}
+	s, err := db.ValidateSnapshot(context.Background(), database, name, minRatio)
+	if err != nil {
+		return false, err
+	}
+	log.Printf("Wczytano wersję %s: %d rekordów.", name, s.Records)
+	if s.Status == model.SnapshotInvalid {
+		return false, fmt.Errorf("wersja %s nie przeszła walidacji: %s", name, strings.Join(s.Problems, "; "))
+	}
+	if !activate {
+		log.Printf("Wersja %s jest gotowa do aktywacji.", name)
+		return false, nil
+	}
+	return true, activateSnapshot(database, name, force)
+}
+
+// snapshotRecords zamienia wynik ParseCSV na płaską listę rekordów wersji:
+// oddziały zgrupowane przez ParseCSV pod centralami trafiają do niej obok
+// nich. Późniejszy wiersz z tym samym kodem zastępuje wcześniejszy, tak jak
+// przy zapisie rekord po rekordzie, a rekordy z nieznanym kodem kraju są
+// pomijane.
+func snapshotRecords(parsed []model.SwiftCode) []model.SwiftCode {
+	var records []model.SwiftCode
+	index := map[string]int{}
+	for _, record := range parser.Flatten(parsed) {
+		c, ok := country.Lookup(record.CountryISO2)
+		if !ok {
+			log.Printf("Pominięto rekord %s: nieznany kod kraju %q", record.SwiftCode, record.CountryISO2)
+			continue
+		}
+		record.CountryName = c.Name
+		if i, ok := index[record.SwiftCode]; ok {
+			records[i] = record
+			continue
+		}
+		index[record.SwiftCode] = len(records)
+		records = append(records, record)
+	}
+	return records
+}
+
+func activateSnapshot(database *sql.DB, name string, force bool) error {
+	a, err := db.ActivateSnapshot(database, name, force)
+	if errors.Is(err, db.ErrSnapshotState) {
+		return fmt.Errorf("wersji %s nie można aktywować (tylko wersje gotowe lub nieaktywne)", name)
+	}
+	if errors.Is(err, db.ErrSnapshotEdits) {
+		return fmt.Errorf("wersji %s nie aktywowano: %w; porównaj wersje poleceniem diff i powtórz zmiany albo użyj -force", name, err)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Aktywowano wersję %s (poprzednio %s): %d dodanych, %d usuniętych, %d zmienionych rekordów.",
+		a.Active.Name, a.Previous, a.Added, a.Removed, a.Changed)
+	return nil
+}
+
+func listSnapshots(database *sql.DB) error {
+	snapshots, err := db.ListSnapshots(database)
+	if err != nil {
+		return err
 	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAZWA\tSTAN\tREKORDY\tUTWORZONA\tPROBLEMY")
+	for _, s := range snapshots {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", s.Name, s.Status, s.Records,
+			s.CreatedAt.UTC().Format(time.DateTime), strings.Join(s.Problems, "; "))
+	}
+	return tw.Flush()
+}
 
-	for _, record := range swiftRecords {
-		if err := db.InsertSwiftCode(database, record); err != nil {
-			log.Printf("Błąd wstawiania rekordu %s: %v", record.SwiftCode, err)
+func diffSnapshots(database *sql.DB, args []string) error {
+	from, to := args[0], ""
+	if len(args) == 2 {
+		to = args[1]
+	} else {
+		active, err := db.GetActiveSnapshot(database)
+		if err != nil {
+			return err
 		}
+		to = active.Name
+	}
+	diff, err := db.DiffSnapshots(database, from, to)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, sc := range diff.Added {
+		fmt.Fprintf(tw, "+\t%s\t%s\t%s\n", sc.SwiftCode, sc.BankName, sc.CountryISO2)
+	}
+	for _, sc := range diff.Removed {
+		fmt.Fprintf(tw, "-\t%s\t%s\t%s\n", sc.SwiftCode, sc.BankName, sc.CountryISO2)
+	}
+	for _, c := range diff.Changed {
+		fmt.Fprintf(tw, "~\t%s\t%s\t%s\n", c.After.SwiftCode, c.After.BankName, c.After.CountryISO2)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	log.Printf("%s → %s: %d dodanych, %d usuniętych, %d zmienionych rekordów.",
+		from, to, len(diff.Added), len(diff.Removed), len(diff.Changed))
+	return nil
+}
+
+func importBankCodes(database *sql.DB, filePath string) error {
+	codes, err := parser.ParseBankCodesCSV(filePath)
+	if err != nil {
+		return fmt.Errorf("błąd parsowania CSV: %w", err)
+	}
+	for _, bc := range codes {
+		if _, ok := country.Lookup(bc.CountryISO2); !ok {
+			return fmt.Errorf("nieznany kod kraju %q dla identyfikatora %s", bc.CountryISO2, bc.BankCode)
+		}
+	}
+	if err := db.ReplaceBankCodes(database, codes); err != nil {
+		return err
+	}
+	log.Printf("Wczytano %d krajowych identyfikatorów banków.", len(codes))
+	return nil
+}
+
+// purgeCache wywołuje DELETE /v1/admin/cache serwera. Serwer z API_KEYS
+// wymaga klucza z rolą admin.
+func purgeCache(url, apiKey string) error {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nieoczekiwany status odpowiedzi: %s", resp.Status)
 	}
-	log.Println("Dane z pliku CSV zostały wstawione do bazy danych.")
+	return nil
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"swift-codes/internal/cache"
+	"swift-codes/internal/handlers"
+	"swift-codes/internal/middleware"
+	"swift-codes/internal/model"
+
+	"github.com/gorilla/mux"
+)
+
+func TestPurgeCache(t *testing.T) {
+	codes := cache.New[model.SwiftCode](10, time.Minute)
+	router := mux.NewRouter()
+	router.Use(middleware.Auth(middleware.AuthConfig{
+		APIKeys:  map[string]string{"klucz-admin": middleware.RoleAdmin, "klucz-r": middleware.RoleReader},
+		Required: func(r *http.Request) string { return middleware.RoleAdmin },
+	}))
+	router.HandleFunc("/v1/admin/cache", handlers.PurgeCacheHandler(codes)).Methods("DELETE")
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := server.URL + "/v1/admin/cache"
+
+	codes.Set("AAISALTRXXX", model.SwiftCode{SwiftCode: "AAISALTRXXX"})
+	for _, key := range []string{"", "klucz-r"} {
+		if err := purgeCache(url, key); err == nil {
+			t.Errorf("Czyszczenie cache z kluczem %q powinno zostać odrzucone", key)
+		}
+	}
+	if codes.Stats().Size != 1 {
+		t.Fatal("Cache nie powinien zostać wyczyszczony bez klucza admin")
+	}
+
+	if err := purgeCache(url, "klucz-admin"); err != nil {
+		t.Fatalf("purgeCache nie powiodło się: %v", err)
+	}
+	if codes.Stats().Size != 0 {
+		t.Error("Oczekiwano pustego cache po imporcie")
+	}
+}
+
+func TestSnapshotRecords_IncludesBranchesOfHeadquarters(t *testing.T) {
+	parsed := []model.SwiftCode{
+		{SwiftCode: "BPKOPLPWXXX", CountryISO2: "PL", IsHeadquarter: true, Branches: []model.SwiftCode{
+			{SwiftCode: "BPKOPLPWBIA", CountryISO2: "PL", Address: "STARY ADRES"},
+			{SwiftCode: "BPKOPLPWBIA", CountryISO2: "PL", Address: "NOWY ADRES"},
+		}},
+		{SwiftCode: "ALBPPLPWCUS", CountryISO2: "PL"},
+		{SwiftCode: "XXXXZZZZXXX", CountryISO2: "ZZ", IsHeadquarter: true},
+	}
+
+	records := snapshotRecords(parsed)
+	var codes []string
+	for _, sc := range records {
+		codes = append(codes, sc.SwiftCode)
+		if sc.Branches != nil {
+			t.Errorf("Rekord %s nie powinien mieć zagnieżdżonych oddziałów", sc.SwiftCode)
+		}
+	}
+	if want := []string{"BPKOPLPWXXX", "BPKOPLPWBIA", "ALBPPLPWCUS"}; strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Fatalf("Oczekiwano rekordów %v, otrzymano %v", want, codes)
+	}
+	if records[1].Address != "NOWY ADRES" || records[1].CountryName != "POLAND" {
+		t.Errorf("Oczekiwano ostatniego wiersza oddziału z nazwą kraju z rejestru, otrzymano %+v", records[1])
+	}
+}
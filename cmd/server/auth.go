@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"swift-codes/internal/middleware"
+	pb "swift-codes/pkg/swiftcodesv1"
+
+	"github.com/gorilla/mux"
+)
+
+// readOnlyPosts to trasy POST, które niczego nie zmieniają.
+var readOnlyPosts = map[string]bool{
+	"/v1/swift-codes/lookup": true,
+	"/graphql":               true,
+}
+
+func requiredRole(r *http.Request) string {
+	var template string
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ = route.GetPathTemplate()
+	}
+	return routeRole(r.Method, template)
+}
+
+// routeRole zwraca rolę potrzebną do wywołania trasy: admin dla tras
+// administracyjnych i webhooków, writer dla zmian w katalogu, reader dla
+// pozostałych.
+func routeRole(method, template string) string {
+	if strings.HasPrefix(template, "/v1/admin/") || strings.HasPrefix(template, "/v1/webhooks") {
+		return middleware.RoleAdmin
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return middleware.RoleReader
+	}
+	if readOnlyPosts[template] {
+		return middleware.RoleReader
+	}
+	return middleware.RoleWriter
+}
+
+// grpcRoutes przypisuje metodom gRPC trasy REST o tym samym działaniu, aby
+// obowiązywały je te same role i klasy limitów.
+var grpcRoutes = map[string]struct{ method, template string }{
+	pb.SwiftCodeService_Get_FullMethodName:           {http.MethodGet, "/v1/swift-codes/{swiftCode}"},
+	pb.SwiftCodeService_ListByCountry_FullMethodName: {http.MethodGet, "/v1/swift-codes/country/{countryISO2code}"},
+	pb.SwiftCodeService_BatchLookup_FullMethodName:   {http.MethodPost, "/v1/swift-codes/lookup"},
+	pb.SwiftCodeService_Export_FullMethodName:        {http.MethodGet, "/v1/swift-codes/export"},
+	pb.SwiftCodeService_Create_FullMethodName:        {http.MethodPost, "/v1/swift-codes"},
+	pb.SwiftCodeService_Delete_FullMethodName:        {http.MethodDelete, "/v1/swift-codes/{swiftCode}"},
+}
+
+// grpcRoute zwraca rolę i klasę limitu metody gRPC. Usługi health
+// i refleksji są dostępne dla roli reader bez limitu.
+func grpcRoute(fullMethod string) (role, class string) {
+	route, ok := grpcRoutes[fullMethod]
+	if !ok {
+		return middleware.RoleReader, ""
+	}
+	return routeRole(route.method, route.template), classifyRoute(route.method, route.template)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"swift-codes/internal/handlers"
+	"swift-codes/internal/middleware"
+	"swift-codes/internal/outbox"
+	"swift-codes/internal/tlsreload"
+	"swift-codes/internal/webhook"
+)
+
+type config struct {
+	cacheSize      int
+	cacheTTL       time.Duration
+	lookupMaxBatch int
+	batchMaxItems  int
+	deleteMode     string
+	grpcPort       int
+	webhook        webhook.WorkerConfig
+	changesPoll    time.Duration
+	outbox         outbox.Config
+	outboxSinks    []string
+	outboxFile     string
+	rateLimits     map[string]middleware.RateLimit
+	dailyQuota     int64
+	apiKeys        map[string]string
+	cors           middleware.CORSConfig
+	tls            tlsreload.Config
+	certRoles      map[string]string
+	authDisabled   bool
+}
+
+func defaultConfig() config {
+	return config{
+		cacheSize:      10000,
+		cacheTTL:       5 * time.Minute,
+		lookupMaxBatch: 1000,
+		batchMaxItems:  1000,
+		deleteMode:     handlers.DeleteRefuse,
+		grpcPort:       9090,
+		webhook:        webhook.DefaultWorkerConfig(),
+		changesPoll:    time.Second,
+		outbox:         outbox.DefaultConfig(),
+		outboxSinks:    []string{"webhook"},
+		outboxFile:     "changes.ndjson",
+		rateLimits: map[string]middleware.RateLimit{
+			middleware.RouteLookup: {Rate: 50, Burst: 100},
+			middleware.RouteSearch: {Rate: 10, Burst: 20},
+			middleware.RouteWrite:  {Rate: 5, Burst: 10},
+			middleware.RouteExport: {Rate: 0.1, Burst: 2},
+		},
+		cors: middleware.DefaultCORSConfig(),
+		tls:  tlsreload.Config{Interval: 10 * time.Second},
+	}
+}
+
+func loadConfig() (config, error) {
+	cfg := defaultConfig()
+	var err error
+	if cfg.cacheSize, err = envInt("CACHE_SIZE", cfg.cacheSize); err != nil {
+		return cfg, err
+	}
+	if cfg.cacheTTL, err = envDuration("CACHE_TTL", cfg.cacheTTL); err != nil {
+		return cfg, err
+	}
+	if cfg.lookupMaxBatch, err = envInt("LOOKUP_MAX_BATCH", cfg.lookupMaxBatch); err != nil {
+		return cfg, err
+	}
+	if cfg.batchMaxItems, err = envInt("BATCH_MAX_ITEMS", cfg.batchMaxItems); err != nil {
+		return cfg, err
+	}
+	if cfg.grpcPort, err = envInt("GRPC_PORT", cfg.grpcPort); err != nil {
+		return cfg, err
+	}
+	if cfg.webhook.MaxAttempts, err = envInt("WEBHOOK_MAX_ATTEMPTS", cfg.webhook.MaxAttempts); err != nil {
+		return cfg, err
+	}
+	if cfg.webhook.Backoff, err = envDuration("WEBHOOK_BACKOFF", cfg.webhook.Backoff); err != nil {
+		return cfg, err
+	}
+	if cfg.webhook.Timeout, err = envDuration("WEBHOOK_TIMEOUT", cfg.webhook.Timeout); err != nil {
+		return cfg, err
+	}
+	if v := os.Getenv("WEBHOOK_ALLOW_PRIVATE"); v != "" {
+		if cfg.webhook.AllowPrivateTargets, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("nieprawidłowa wartość WEBHOOK_ALLOW_PRIVATE: %w", err)
+		}
+	}
+	if cfg.changesPoll, err = envDuration("CHANGES_POLL_INTERVAL", cfg.changesPoll); err != nil {
+		return cfg, err
+	}
+	if cfg.outbox.PollInterval, err = envDuration("OUTBOX_POLL_INTERVAL", cfg.outbox.PollInterval); err != nil {
+		return cfg, err
+	}
+	if v, ok := os.LookupEnv("OUTBOX_SINKS"); ok {
+		cfg.outboxSinks = nil
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case "":
+				continue
+			case "log", "webhook", "file":
+				cfg.outboxSinks = append(cfg.outboxSinks, name)
+			default:
+				return cfg, fmt.Errorf("nieprawidłowa wartość OUTBOX_SINKS: nieznany odbiorca %q", name)
+			}
+		}
+	}
+	if v := os.Getenv("OUTBOX_FILE"); v != "" {
+		cfg.outboxFile = v
+	}
+	for class, name := range map[string]string{
+		middleware.RouteLookup: "RATE_LIMIT_LOOKUP",
+		middleware.RouteSearch: "RATE_LIMIT_SEARCH",
+		middleware.RouteWrite:  "RATE_LIMIT_WRITE",
+		middleware.RouteExport: "RATE_LIMIT_EXPORT",
+	} {
+		if v := os.Getenv(name); v != "" {
+			limit, err := middleware.ParseRateLimit(v)
+			if err != nil {
+				return cfg, fmt.Errorf("nieprawidłowa wartość %s: %w", name, err)
+			}
+			cfg.rateLimits[class] = limit
+		}
+	}
+	quota, err := envInt("RATE_LIMIT_DAILY_QUOTA", int(cfg.dailyQuota))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.dailyQuota = int64(quota)
+	if cfg.apiKeys, err = middleware.ParseAPIKeys(os.Getenv("API_KEYS")); err != nil {
+		return cfg, fmt.Errorf("nieprawidłowa wartość API_KEYS: %w", err)
+	}
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			cfg.cors.AllowedOrigins = append(cfg.cors.AllowedOrigins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+	if cfg.cors.MaxAge, err = envDuration("CORS_MAX_AGE", cfg.cors.MaxAge); err != nil {
+		return cfg, err
+	}
+	if err := loadTLSConfig(&cfg); err != nil {
+		return cfg, err
+	}
+	if v := os.Getenv("DELETE_HIERARCHY"); v != "" {
+		if !handlers.ValidDeleteMode(v) {
+			return cfg, fmt.Errorf("nieprawidłowa wartość DELETE_HIERARCHY: %q", v)
+		}
+		cfg.deleteMode = v
+	}
+	if v := os.Getenv("AUTH_DISABLED"); v != "" {
+		if cfg.authDisabled, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("nieprawidłowa wartość AUTH_DISABLED: %w", err)
+		}
+	}
+	if cfg.authDisabled && (len(cfg.apiKeys) > 0 || len(cfg.certRoles) > 0) {
+		return cfg, fmt.Errorf("AUTH_DISABLED=true wyklucza API_KEYS i TLS_CLIENT_ROLES")
+	}
+	return cfg, nil
+}
+
+// auth zwraca ustawienia kontroli dostępu wspólne dla REST i gRPC.
+func (cfg config) auth() middleware.AuthConfig {
+	return middleware.AuthConfig{APIKeys: cfg.apiKeys, CertRoles: cfg.certRoles, Disabled: cfg.authDisabled}
+}
+
+// loadTLSConfig odczytuje ustawienia TLS; bez TLS_CERT_FILE serwer działa
+// bez szyfrowania.
+func loadTLSConfig(cfg *config) error {
+	cfg.tls.CertFile = os.Getenv("TLS_CERT_FILE")
+	cfg.tls.KeyFile = os.Getenv("TLS_KEY_FILE")
+	cfg.tls.ClientCAFile = os.Getenv("TLS_CLIENT_CA_FILE")
+	if (cfg.tls.CertFile == "") != (cfg.tls.KeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE i TLS_KEY_FILE muszą być ustawione razem")
+	}
+	if cfg.tls.CertFile == "" && cfg.tls.ClientCAFile != "" {
+		return fmt.Errorf("TLS_CLIENT_CA_FILE wymaga TLS_CERT_FILE i TLS_KEY_FILE")
+	}
+	if cfg.tls.ClientCAFile != "" {
+		cfg.tls.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	if v := os.Getenv("TLS_CLIENT_AUTH"); v != "" {
+		mode, err := tlsreload.ParseClientAuth(v)
+		if err != nil {
+			return fmt.Errorf("nieprawidłowa wartość TLS_CLIENT_AUTH: %w", err)
+		}
+		if mode != tls.NoClientCert && cfg.tls.ClientCAFile == "" {
+			return fmt.Errorf("TLS_CLIENT_AUTH=%s wymaga TLS_CLIENT_CA_FILE", v)
+		}
+		cfg.tls.ClientAuth = mode
+	}
+	var err error
+	if cfg.tls.Interval, err = envDuration("TLS_RELOAD_INTERVAL", cfg.tls.Interval); err != nil {
+		return err
+	}
+	if cfg.tls.Interval <= 0 {
+		return fmt.Errorf("TLS_RELOAD_INTERVAL musi być dodatni")
+	}
+	if cfg.certRoles, err = middleware.ParseCertRoles(os.Getenv("TLS_CLIENT_ROLES")); err != nil {
+		return fmt.Errorf("nieprawidłowa wartość TLS_CLIENT_ROLES: %w", err)
+	}
+	if len(cfg.certRoles) > 0 && cfg.tls.ClientAuth == tls.NoClientCert {
+		return fmt.Errorf("TLS_CLIENT_ROLES wymaga weryfikacji certyfikatów klientów (TLS_CLIENT_CA_FILE)")
+	}
+	return nil
+}
+
+func envInt(name string, def int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def, fmt.Errorf("nieprawidłowa wartość %s: %w", name, err)
+	}
+	return n, nil
+}
+
+func envDuration(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def, fmt.Errorf("nieprawidłowa wartość %s: %w", name, err)
+	}
+	return d, nil
+}
@@ -1,16 +1,39 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"swift-codes/internal/cache"
 	"swift-codes/internal/db"
+	"swift-codes/internal/graph"
+	"swift-codes/internal/grpcserver"
 	"swift-codes/internal/handlers"
+	"swift-codes/internal/middleware"
+	"swift-codes/internal/model"
+	"swift-codes/internal/openapi"
+	"swift-codes/internal/outbox"
+	"swift-codes/internal/tlsreload"
+	"swift-codes/internal/ui"
+	"swift-codes/internal/webhook"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+// usageFlushInterval to odstęp zapisywania statystyk żądań do bazy.
+const usageFlushInterval = 10 * time.Second
+
 func main() {
+	logger := slog.New(middleware.NewContextHandler(slog.NewJSONHandler(os.Stdout, nil)))
+	slog.SetDefault(logger)
+
 	connStr := os.Getenv("DB_CONN")
 	if connStr == "" {
 		log.Fatal("Brak ustawionej zmiennej środowiskowej DB_CONN")
@@ -22,13 +45,137 @@ func main() {
 	}
 	defer database.Close()
 
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji: %v", err)
+	}
+	if cfg.authDisabled {
+		log.Println("Kontrola dostępu wyłączona (AUTH_DISABLED=true): każdy klient ma rolę admin")
+	}
+	codes := cache.New[model.SwiftCode](cfg.cacheSize, cfg.cacheTTL)
+
+	var tlsConfig *tls.Config
+	if cfg.tls.CertFile != "" {
+		reloader, err := tlsreload.New(cfg.tls)
+		if err != nil {
+			log.Fatalf("Błąd konfiguracji TLS: %v", err)
+		}
+		go reloader.Run(context.Background())
+		tlsConfig = reloader.ServerConfig()
+	}
+
+	limiter := newRateLimiter(cfg)
+	router := newRouter(logger, database, codes, limiter, cfg)
+
+	go handlers.FollowChanges(context.Background(), database, codes, cfg.changesPoll)
+	go webhook.NewWorker(database, cfg.webhook).Run(context.Background())
+	go limiter.FlushUsage(context.Background(), usageFlushInterval, func(usage []model.APIUsage) error {
+		return db.AddAPIUsage(database, usage)
+	})
+	sinks, err := outboxSinks(database, cfg)
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji odbiorców zmian: %v", err)
+	}
+	go outbox.NewDispatcher(database, cfg.outbox, sinks...).Run(context.Background())
+
+	grpcServer := grpcserver.New(database, codes, grpcserver.Config{
+		LookupMaxBatch: cfg.lookupMaxBatch,
+		DeletePolicy:   cfg.deleteMode,
+		TLS:            tlsConfig,
+		Auth:           cfg.auth(),
+		Limiter:        limiter,
+		Route:          grpcRoute,
+	})
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.grpcPort))
+	if err != nil {
+		log.Fatalf("Błąd uruchamiania serwera gRPC: %v", err)
+	}
+	go func() {
+		log.Printf("Serwer gRPC uruchomiony na porcie %d", cfg.grpcPort)
+		log.Fatal(grpcServer.Serve(listener))
+	}()
+
+	server := &http.Server{
+		Addr:      ":8080",
+		Handler:   middleware.CORS(cfg.cors)(router),
+		TLSConfig: tlsConfig,
+	}
+	if tlsConfig != nil {
+		log.Println("Serwer HTTPS uruchomiony na porcie 8080")
+		log.Fatal(server.ListenAndServeTLS("", ""))
+	}
+	log.Println("Serwer uruchomiony na porcie 8080")
+	log.Fatal(server.ListenAndServe())
+}
+
+// outboxSinks tworzy odbiorców zmian wybranych w OUTBOX_SINKS.
+func outboxSinks(database *sql.DB, cfg config) ([]outbox.Sink, error) {
+	var sinks []outbox.Sink
+	for _, name := range cfg.outboxSinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, outbox.LogSink{})
+		case "webhook":
+			sinks = append(sinks, outbox.NewWebhookSink(database))
+		case "file":
+			sink, err := outbox.NewFileSink(cfg.outboxFile)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		}
+	}
+	return sinks, nil
+}
+
+func newRouter(logger *slog.Logger, database *sql.DB, codes *cache.Cache[model.SwiftCode], limiter *middleware.RateLimiter, cfg config) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(middleware.Logging(logger))
+	router.Use(limiter.Middleware)
+	auth := cfg.auth()
+	auth.Required = requiredRole
+	router.Use(middleware.Auth(auth))
+	router.NotFoundHandler = middleware.Logging(logger)(http.NotFoundHandler())
 
-	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.GetSwiftCodeHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/search", handlers.SearchSwiftCodesHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/export", handlers.ExportSwiftCodesHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.GetSwiftCodeHandler(database, codes)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}/headquarter", handlers.GetHeadquarterHandler(database, codes)).Methods("GET")
 	router.HandleFunc("/v1/swift-codes/country/{countryISO2code}", handlers.GetSwiftCodesByCountryHandler(database)).Methods("GET")
-	router.HandleFunc("/v1/swift-codes", handlers.CreateSwiftCodeHandler(database)).Methods("POST")
-	router.HandleFunc("/v1/swift-codes/{swift-code}", handlers.DeleteSwiftCodeHandler(database)).Methods("DELETE")
+	router.HandleFunc("/v1/swift-codes", handlers.CreateSwiftCodeHandler(database, codes)).Methods("POST")
+	router.HandleFunc("/v1/swift-codes/lookup", handlers.LookupSwiftCodesHandler(database, cfg.lookupMaxBatch)).Methods("POST")
+	router.HandleFunc("/v1/swift-codes/batch", handlers.BatchSwiftCodesHandler(database, codes, cfg.batchMaxItems)).Methods("POST")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.UpdateSwiftCodeHandler(database, codes)).Methods("PUT")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.PatchSwiftCodeHandler(database, codes)).Methods("PATCH")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.DeleteSwiftCodeHandler(database, codes, cfg.deleteMode)).Methods("DELETE")
+	router.HandleFunc("/v1/countries", handlers.ListCountriesHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/banks", handlers.ListBanksHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/banks/{bic8}", handlers.GetBankHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/iban/{iban}", handlers.GetIBANHandler(database)).Methods("GET")
+	router.HandleFunc("/graphql", graph.Handler(database)).Methods("GET", "POST")
+	router.HandleFunc("/v1/changes", handlers.ListChangesHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/changes/stream", handlers.StreamChangesHandler(database, cfg.changesPoll)).Methods("GET")
+	router.HandleFunc("/v1/webhooks", handlers.CreateWebhookHandler(database)).Methods("POST")
+	router.HandleFunc("/v1/webhooks", handlers.ListWebhooksHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/webhooks/dead-letters", handlers.ListDeadLettersHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/webhooks/dead-letters/{id}/retry", handlers.RetryDeadLetterHandler(database)).Methods("POST")
+	router.HandleFunc("/v1/webhooks/{id}", handlers.GetWebhookHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/webhooks/{id}", handlers.DeleteWebhookHandler(database)).Methods("DELETE")
+	router.HandleFunc("/v1/auth/whoami", handlers.WhoAmIHandler()).Methods("GET")
+	router.HandleFunc("/v1/admin/usage", handlers.UsageReportHandler(database, cfg.dailyQuota)).Methods("GET")
+	router.HandleFunc("/v1/admin/outbox", handlers.OutboxHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/admin/integrity", handlers.IntegrityHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/admin/cache", handlers.CacheStatsHandler(codes)).Methods("GET")
+	router.HandleFunc("/v1/admin/cache", handlers.PurgeCacheHandler(codes)).Methods("DELETE")
+	router.HandleFunc("/v1/admin/snapshots", handlers.ListSnapshotsHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/admin/snapshots/diff", handlers.DiffSnapshotsHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/admin/snapshots/{name}", handlers.GetSnapshotHandler(database)).Methods("GET")
+	router.HandleFunc("/v1/admin/snapshots/{name}", handlers.DeleteSnapshotHandler(database)).Methods("DELETE")
+	router.HandleFunc("/v1/admin/snapshots/{name}/activate", handlers.ActivateSnapshotHandler(database, codes)).Methods("POST")
 
-	log.Println("Serwer uruchomiony na porcie 8080")
-	log.Fatal(http.ListenAndServe(":8080", router))
+	router.HandleFunc("/openapi.json", openapi.SpecHandler()).Methods("GET")
+	router.PathPrefix("/docs").Handler(openapi.DocsHandler()).Methods("GET")
+	router.PathPrefix("/ui").Handler(ui.Handler()).Methods("GET")
+
+	return router
 }
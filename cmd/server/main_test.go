@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"swift-codes/internal/middleware"
+	"swift-codes/internal/openapi"
+	pb "swift-codes/pkg/swiftcodesv1"
+
+	"github.com/gorilla/mux"
+)
+
+func TestOpenAPISpecCoversAllRoutes(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec(), &spec); err != nil {
+		t.Fatalf("Błąd dekodowania specyfikacji OpenAPI: %v", err)
+	}
+
+	router := newRouter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, newRateLimiter(defaultConfig()), defaultConfig())
+	routes := 0
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			t.Errorf("Trasa %s nie ma zadeklarowanych metod HTTP", path)
+			return nil
+		}
+		for _, method := range methods {
+			routes++
+			if _, ok := spec.Paths[path][strings.ToLower(method)]; !ok {
+				t.Errorf("Brak %s %s w specyfikacji OpenAPI", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Błąd przeglądania tras: %v", err)
+	}
+	if routes == 0 {
+		t.Fatal("Nie znaleziono żadnych zarejestrowanych tras")
+	}
+}
+
+// Trasy administracyjne nie mogą być dostępne bez kluczy tylko dlatego, że
+// serwer nie ma skonfigurowanej kontroli dostępu.
+func TestAdminRoutesClosedByDefault(t *testing.T) {
+	router := newRouter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, newRateLimiter(defaultConfig()), defaultConfig())
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/v1/admin/usage"},
+		{"DELETE", "/v1/admin/cache"},
+		{"POST", "/v1/admin/snapshots/20261018-020000/activate"},
+		{"POST", "/v1/swift-codes"},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(route.method, route.path, nil))
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s bez klucza - oczekiwano status 401, otrzymano %d", route.method, route.path, rr.Code)
+		}
+	}
+}
+
+func TestRouteClass(t *testing.T) {
+	router := newRouter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, newRateLimiter(defaultConfig()), defaultConfig())
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/v1/swift-codes/AAISALTRXXX", middleware.RouteLookup},
+		{"GET", "/v1/swift-codes/search?q=PKO", middleware.RouteSearch},
+		{"POST", "/v1/swift-codes/lookup", middleware.RouteSearch},
+		{"POST", "/graphql", middleware.RouteSearch},
+		{"GET", "/v1/swift-codes/export", middleware.RouteExport},
+		{"GET", "/v1/changes/stream", middleware.RouteExport},
+		{"POST", "/v1/swift-codes", middleware.RouteWrite},
+		{"DELETE", "/v1/swift-codes/AAISALTRXXX", middleware.RouteWrite},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		var match mux.RouteMatch
+		if !router.Match(req, &match) {
+			t.Fatalf("Brak trasy dla %s %s", tt.method, tt.path)
+		}
+		template, _ := match.Route.GetPathTemplate()
+		if got := classifyRoute(tt.method, template); got != tt.want {
+			t.Errorf("%s %s - oczekiwano klasy %s, otrzymano %s", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestRouteRole(t *testing.T) {
+	tests := []struct {
+		method, template, want string
+	}{
+		{"GET", "/v1/swift-codes/{swiftCode}", middleware.RoleReader},
+		{"POST", "/v1/swift-codes/lookup", middleware.RoleReader},
+		{"POST", "/graphql", middleware.RoleReader},
+		{"GET", "/ui", middleware.RoleReader},
+		{"POST", "/v1/swift-codes", middleware.RoleWriter},
+		{"PUT", "/v1/swift-codes/{swiftCode}", middleware.RoleWriter},
+		{"PATCH", "/v1/swift-codes/{swiftCode}", middleware.RoleWriter},
+		{"DELETE", "/v1/swift-codes/{swiftCode}", middleware.RoleWriter},
+		{"GET", "/v1/webhooks", middleware.RoleAdmin},
+		{"GET", "/v1/admin/usage", middleware.RoleAdmin},
+	}
+	for _, tt := range tests {
+		if got := routeRole(tt.method, tt.template); got != tt.want {
+			t.Errorf("%s %s - oczekiwano roli %s, otrzymano %s", tt.method, tt.template, tt.want, got)
+		}
+	}
+}
+
+func TestGRPCRoutes(t *testing.T) {
+	desc := pb.SwiftCodeService_ServiceDesc
+	var methods []string
+	for _, m := range desc.Methods {
+		methods = append(methods, m.MethodName)
+	}
+	for _, s := range desc.Streams {
+		methods = append(methods, s.StreamName)
+	}
+	for _, name := range methods {
+		if _, ok := grpcRoutes["/"+desc.ServiceName+"/"+name]; !ok {
+			t.Errorf("Metoda gRPC %s nie ma przypisanej trasy REST", name)
+		}
+	}
+
+	tests := []struct {
+		method, role, class string
+	}{
+		{pb.SwiftCodeService_Get_FullMethodName, middleware.RoleReader, middleware.RouteLookup},
+		{pb.SwiftCodeService_ListByCountry_FullMethodName, middleware.RoleReader, middleware.RouteSearch},
+		{pb.SwiftCodeService_BatchLookup_FullMethodName, middleware.RoleReader, middleware.RouteSearch},
+		{pb.SwiftCodeService_Export_FullMethodName, middleware.RoleReader, middleware.RouteExport},
+		{pb.SwiftCodeService_Create_FullMethodName, middleware.RoleWriter, middleware.RouteWrite},
+		{pb.SwiftCodeService_Delete_FullMethodName, middleware.RoleWriter, middleware.RouteWrite},
+		{"/grpc.health.v1.Health/Check", middleware.RoleReader, ""},
+	}
+	for _, tt := range tests {
+		if role, class := grpcRoute(tt.method); role != tt.role || class != tt.class {
+			t.Errorf("%s - oczekiwano roli %s i klasy %q, otrzymano %s i %q", tt.method, tt.role, tt.class, role, class)
+		}
+	}
+}
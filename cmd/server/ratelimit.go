@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+
+	"swift-codes/internal/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// routeClasses przypisuje trasy do klas limitów. Pozostałe trasy należą do
+// klasy lookup (odczyt) albo write (pozostałe metody).
+var routeClasses = map[string]string{
+	"/v1/swift-codes/search":                    middleware.RouteSearch,
+	"/v1/swift-codes/lookup":                    middleware.RouteSearch,
+	"/v1/swift-codes/country/{countryISO2code}": middleware.RouteSearch,
+	"/v1/countries":                             middleware.RouteSearch,
+	"/v1/banks":                                 middleware.RouteSearch,
+	"/graphql":                                  middleware.RouteSearch,
+	"/v1/swift-codes/export":                    middleware.RouteExport,
+	"/v1/changes":                               middleware.RouteExport,
+	"/v1/changes/stream":                        middleware.RouteExport,
+}
+
+func routeClass(r *http.Request) string {
+	var template string
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ = route.GetPathTemplate()
+	}
+	return classifyRoute(r.Method, template)
+}
+
+func classifyRoute(method, template string) string {
+	if class, ok := routeClasses[template]; ok {
+		return class
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return middleware.RouteLookup
+	}
+	return middleware.RouteWrite
+}
+
+func newRateLimiter(cfg config) *middleware.RateLimiter {
+	return middleware.NewRateLimiter(middleware.RateLimitConfig{
+		Limits:     cfg.rateLimits,
+		DailyQuota: cfg.dailyQuota,
+		Classify:   routeClass,
+		APIKeys:    cfg.apiKeys,
+	})
+}
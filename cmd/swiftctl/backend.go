@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/integrity"
+	"swift-codes/internal/model"
+	"swift-codes/internal/parser"
+	"swift-codes/pkg/client"
+)
+
+var errNotFound = errors.New("nie znaleziono wpisu")
+
+type backend interface {
+	Get(ctx context.Context, code string) (model.SwiftCode, error)
+	Country(ctx context.Context, iso2 string) ([]model.SwiftCode, error)
+	Search(ctx context.Context, phrase string, limit int) ([]model.SwiftCode, error)
+	Integrity(ctx context.Context) (model.IntegrityReport, error)
+	Close() error
+}
+
+type apiBackend struct {
+	client *client.Client
+}
+
+func (b *apiBackend) Get(ctx context.Context, code string) (model.SwiftCode, error) {
+	sc, err := b.client.GetSwiftCode(ctx, code)
+	if errors.Is(err, client.ErrNotFound) {
+		return sc, errNotFound
+	}
+	return sc, err
+}
+
+func (b *apiBackend) Country(ctx context.Context, iso2 string) ([]model.SwiftCode, error) {
+	result, err := b.client.ListByCountry(ctx, iso2)
+	return result.SwiftCodes, err
+}
+
+func (b *apiBackend) Search(ctx context.Context, phrase string, limit int) ([]model.SwiftCode, error) {
+	result, err := b.client.Search(ctx, phrase, limit)
+	return result.SwiftCodes, err
+}
+
+func (b *apiBackend) Integrity(ctx context.Context) (model.IntegrityReport, error) {
+	return b.client.Integrity(ctx)
+}
+
+func (b *apiBackend) Close() error { return nil }
+
+type dbBackend struct {
+	db *sql.DB
+}
+
+func (b *dbBackend) Get(ctx context.Context, code string) (model.SwiftCode, error) {
+	sc, err := db.GetSwiftCode(b.db, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sc, errNotFound
+	}
+	if err != nil || !sc.IsHeadquarter {
+		return sc, err
+	}
+	sc.Branches, err = db.GetBranchesByHeadquarter(b.db, sc.SwiftCode)
+	return sc, err
+}
+
+func (b *dbBackend) Country(ctx context.Context, iso2 string) ([]model.SwiftCode, error) {
+	return db.GetSwiftCodesByCountry(b.db, iso2)
+}
+
+func (b *dbBackend) Search(ctx context.Context, phrase string, limit int) ([]model.SwiftCode, error) {
+	return db.SearchSwiftCodes(b.db, phrase, limit)
+}
+
+func (b *dbBackend) Integrity(ctx context.Context) (model.IntegrityReport, error) {
+	checker := integrity.NewChecker()
+	err := db.StreamSwiftCodes(ctx, b.db, 500, func(sc model.SwiftCode) error {
+		checker.Add(sc)
+		return nil
+	})
+	return checker.Report(), err
+}
+
+func (b *dbBackend) Close() error { return b.db.Close() }
+
+// csvBackend trzyma cały plik w pamięci i odpowiada tak samo jak API,
+// więc narzędzie działa bez serwera i bazy danych.
+type csvBackend struct {
+	records []model.SwiftCode
+	byCode  map[string]int
+}
+
+func newCSVBackend(path string) (*csvBackend, error) {
+	parsed, err := parser.ParseCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	records := parser.Flatten(parsed)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].SwiftCode < records[j].SwiftCode })
+
+	b := &csvBackend{records: records, byCode: make(map[string]int, len(records))}
+	for i, sc := range records {
+		b.byCode[sc.SwiftCode] = i
+	}
+	return b, nil
+}
+
+func (b *csvBackend) Get(ctx context.Context, code string) (model.SwiftCode, error) {
+	i, ok := b.byCode[code]
+	if !ok {
+		return model.SwiftCode{}, errNotFound
+	}
+	sc := b.records[i]
+	if sc.IsHeadquarter && len(sc.SwiftCode) >= 8 {
+		for _, other := range b.records {
+			if !other.IsHeadquarter && strings.HasPrefix(other.SwiftCode, sc.SwiftCode[:8]) {
+				sc.Branches = append(sc.Branches, other)
+			}
+		}
+	}
+	return sc, nil
+}
+
+func (b *csvBackend) Country(ctx context.Context, iso2 string) ([]model.SwiftCode, error) {
+	iso2 = strings.ToUpper(iso2)
+	var result []model.SwiftCode
+	for _, sc := range b.records {
+		if sc.CountryISO2 == iso2 {
+			result = append(result, sc)
+		}
+	}
+	return result, nil
+}
+
+func (b *csvBackend) Search(ctx context.Context, phrase string, limit int) ([]model.SwiftCode, error) {
+	phrase = strings.ToUpper(phrase)
+	var result []model.SwiftCode
+	for _, sc := range b.records {
+		if len(result) >= limit {
+			break
+		}
+		if strings.Contains(strings.ToUpper(sc.SwiftCode), phrase) || strings.Contains(strings.ToUpper(sc.BankName), phrase) {
+			result = append(result, sc)
+		}
+	}
+	return result, nil
+}
+
+func (b *csvBackend) Integrity(ctx context.Context) (model.IntegrityReport, error) {
+	return integrity.Check(b.records), nil
+}
+
+func (b *csvBackend) Close() error { return nil }
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+	"swift-codes/pkg/client"
+)
+
+const usage = `Użycie: swiftctl [flagi] <polecenie> [argumenty]
+
+Polecenia:
+  get BIC               szczegóły kodu SWIFT (z oddziałami dla centrali)
+  country ISO2          kody SWIFT danego kraju (--hq-only: tylko centrale)
+  search FRAZA          wyszukiwanie w kodach SWIFT i nazwach banków
+  validate BIC          sprawdzenie formatu kodu SWIFT
+  integrity             raport spójności hierarchii (osierocone oddziały,
+                        niezgodne kraje, duplikaty); kod wyjścia 1 przy problemach
+
+Źródło danych (domyślnie plik data/swiftcodes_data.csv):
+  --api URL             serwer HTTP API (lub zmienna SWIFT_API_URL); przy HTTPS
+                        z mTLS także --cert, --key i --cacert
+  --csv PLIK            lokalny plik CSV w formacie SWIFT
+  --db CONN             bezpośrednie połączenie z bazą (lub zmienna DB_CONN)
+
+Flagi:
+`
+
+type options struct {
+	output  string
+	apiURL  string
+	apiKey  string
+	cert    string
+	key     string
+	caCert  string
+	csvPath string
+	dbConn  string
+	hqOnly  bool
+	limit   int
+	timeout time.Duration
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	var opts options
+	fs := flag.NewFlagSet("swiftctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.output, "o", "table", "format wyjścia: table, json, csv")
+	fs.StringVar(&opts.apiURL, "api", "", "adres serwera HTTP API")
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("SWIFT_API_KEY"), "klucz API wysyłany w nagłówku X-API-Key")
+	fs.StringVar(&opts.cert, "cert", os.Getenv("SWIFT_API_CERT"), "certyfikat klienta (PEM) dla serwera wymagającego mTLS")
+	fs.StringVar(&opts.key, "key", os.Getenv("SWIFT_API_CERT_KEY"), "klucz prywatny certyfikatu klienta (PEM)")
+	fs.StringVar(&opts.caCert, "cacert", os.Getenv("SWIFT_API_CACERT"), "pakiet CA (PEM) do weryfikacji certyfikatu serwera")
+	fs.StringVar(&opts.csvPath, "csv", "", "ścieżka do pliku CSV")
+	fs.StringVar(&opts.dbConn, "db", "", "parametry połączenia z bazą danych")
+	fs.BoolVar(&opts.hqOnly, "hq-only", false, "tylko centrale (polecenie country)")
+	fs.IntVar(&opts.limit, "limit", 50, "maksymalna liczba wyników (polecenie search)")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "limit czasu polecenia")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) == 1 && positional[0] == "integrity" {
+		positional = append(positional, "")
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return 2
+	}
+	command, arg := positional[0], strings.TrimSpace(positional[1])
+
+	if command == "validate" {
+		return validate(stdout, stderr, opts.output, arg)
+	}
+
+	b, err := openBackend(opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "swiftctl: %v\n", err)
+		return 1
+	}
+	defer b.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	if command == "integrity" {
+		report, err := b.Integrity(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "swiftctl: %v\n", err)
+			return 1
+		}
+		if err := writeIntegrity(stdout, opts.output, report); err != nil {
+			fmt.Fprintf(stderr, "swiftctl: %v\n", err)
+			return 1
+		}
+		if report.Issues() > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	var value any
+	var records []model.SwiftCode
+	switch command {
+	case "get":
+		sc, err := b.Get(ctx, bic.Canonical(arg))
+		if err != nil {
+			fmt.Fprintf(stderr, "swiftctl: %s: %v\n", arg, err)
+			return 1
+		}
+		value, records = sc, []model.SwiftCode{sc}
+	case "country":
+		records, err = b.Country(ctx, strings.ToUpper(arg))
+		if err != nil {
+			fmt.Fprintf(stderr, "swiftctl: %v\n", err)
+			return 1
+		}
+		if opts.hqOnly {
+			records = headquartersOnly(records)
+		}
+		value = records
+	case "search":
+		records, err = b.Search(ctx, arg, opts.limit)
+		if err != nil {
+			fmt.Fprintf(stderr, "swiftctl: %v\n", err)
+			return 1
+		}
+		value = records
+	default:
+		fmt.Fprintf(stderr, "swiftctl: nieznane polecenie %q\n", command)
+		fs.Usage()
+		return 2
+	}
+
+	if value == nil {
+		value = []model.SwiftCode{}
+	}
+	if err := writeRecords(stdout, opts.output, value, records); err != nil {
+		fmt.Fprintf(stderr, "swiftctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed pozwala podawać flagi także po argumentach polecenia,
+// np. "swiftctl country PL --hq-only".
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// clientTLSConfig wczytuje certyfikat klienta i pakiet CA podane w flagach.
+func clientTLSConfig(opts options) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.cert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.cert, opts.key)
+		if err != nil {
+			return nil, fmt.Errorf("błąd wczytywania certyfikatu klienta: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if opts.caCert != "" {
+		pem, err := os.ReadFile(opts.caCert)
+		if err != nil {
+			return nil, fmt.Errorf("błąd wczytywania pakietu CA: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("pakiet CA %s nie zawiera certyfikatów", opts.caCert)
+		}
+	}
+	return cfg, nil
+}
+
+func openBackend(opts options) (backend, error) {
+	apiURL, dbConn, csvPath := opts.apiURL, opts.dbConn, opts.csvPath
+	if apiURL == "" && dbConn == "" && csvPath == "" {
+		apiURL = os.Getenv("SWIFT_API_URL")
+		if apiURL == "" {
+			dbConn = os.Getenv("DB_CONN")
+		}
+		if apiURL == "" && dbConn == "" {
+			csvPath = "data/swiftcodes_data.csv"
+		}
+	}
+
+	switch {
+	case apiURL != "":
+		var clientOpts []client.Option
+		if opts.apiKey != "" {
+			clientOpts = append(clientOpts, client.WithAPIKey(opts.apiKey))
+		}
+		if opts.cert != "" || opts.caCert != "" {
+			tlsConfig, err := clientTLSConfig(opts)
+			if err != nil {
+				return nil, err
+			}
+			clientOpts = append(clientOpts, client.WithTLSConfig(tlsConfig))
+		}
+		return &apiBackend{client: client.New(apiURL, clientOpts...)}, nil
+	case csvPath != "":
+		return newCSVBackend(csvPath)
+	default:
+		database, err := db.InitDB(dbConn)
+		if err != nil {
+			return nil, err
+		}
+		return &dbBackend{db: database}, nil
+	}
+}
+
+func headquartersOnly(records []model.SwiftCode) []model.SwiftCode {
+	var result []model.SwiftCode
+	for _, sc := range records {
+		if sc.IsHeadquarter {
+			result = append(result, sc)
+		}
+	}
+	return result
+}
+
+type validationResult struct {
+	SwiftCode   string     `json:"swiftCode"`
+	Valid       bool       `json:"valid"`
+	Error       string     `json:"error,omitempty"`
+	Headquarter bool       `json:"headquarter"`
+	Parts       *bic.Parts `json:"parts,omitempty"`
+}
+
+func validate(stdout, stderr io.Writer, format, code string) int {
+	code = bic.Normalize(code)
+	result := validationResult{SwiftCode: code, Valid: true}
+	parts, err := bic.Parse(code)
+	if err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+	} else {
+		result.Headquarter = bic.IsHeadquarter(code)
+		result.Parts = &parts
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result)
+	case "csv":
+		cw := csv.NewWriter(stdout)
+		cw.Write([]string{"swiftCode", "valid", "error", "headquarter"})
+		cw.Write([]string{result.SwiftCode, fmt.Sprint(result.Valid), result.Error, fmt.Sprint(result.Headquarter)})
+		cw.Flush()
+	case "table":
+		tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "SWIFT CODE\t%s\n", result.SwiftCode)
+		if result.Valid {
+			fmt.Fprintf(tw, "VALID\ttak\n")
+			fmt.Fprintf(tw, "HEADQUARTER\t%v\n", result.Headquarter)
+			fmt.Fprintf(tw, "BANK\t%s\nCOUNTRY\t%s\nLOCATION\t%s\nBRANCH\t%s\n", parts.Bank, parts.Country, parts.Location, parts.Branch)
+		} else {
+			fmt.Fprintf(tw, "VALID\tnie\nERROR\t%s\n", result.Error)
+		}
+		tw.Flush()
+	default:
+		fmt.Fprintf(stderr, "swiftctl: nieznany format wyjścia %q (dostępne: table, json, csv)\n", format)
+		return 2
+	}
+
+	if !result.Valid {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"swift-codes/internal/model"
+)
+
+const testCSV = `COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE
+PL,BPKOPLPWXXX,BIC11,PKO BANK POLSKI S.A.,"PULAWSKA 15  WARSZAWA, MAZOWIECKIE, 02-515",WARSZAWA,POLAND,Europe/Warsaw
+PL,BPKOPLPWBIA,BIC11,PKO BANK POLSKI S.A.,"TYSIACLECIA PANSTWA POLSKIEGO 6  BIALYSTOK, PODLASKIE, 15-111",BIALYSTOK,POLAND,Europe/Warsaw
+PL,BREXPLPWXXX,BIC11,MBANK S.A. (FORMERLY BRE BANK S.A.),"PROSTA 18  WARSZAWA, MAZOWIECKIE, 00-850",WARSZAWA,POLAND,Europe/Warsaw
+BG,ABIEBGS1XXX,BIC11,ABV INVESTMENTS LTD,"TSAR ASEN 20  VARNA, VARNA, 9002",VARNA,BULGARIA,Europe/Sofia
+`
+
+func runWithCSV(t *testing.T, args ...string) (int, string) {
+	path := filepath.Join(t.TempDir(), "swift.csv")
+	if err := os.WriteFile(path, []byte(testCSV), 0o644); err != nil {
+		t.Fatalf("Nie udało się zapisać pliku CSV: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"--csv", path}, args...), &stdout, &stderr)
+	return code, stdout.String()
+}
+
+func TestRun_GetIncludesBranches(t *testing.T) {
+	code, out := runWithCSV(t, "get", "bpkoplpwxxx", "-o", "json")
+	if code != 0 {
+		t.Fatalf("Oczekiwano kodu wyjścia 0, otrzymano %d", code)
+	}
+
+	var sc model.SwiftCode
+	if err := json.Unmarshal([]byte(out), &sc); err != nil {
+		t.Fatalf("Błąd dekodowania wyjścia JSON: %v", err)
+	}
+	if len(sc.Branches) != 1 || sc.Branches[0].SwiftCode != "BPKOPLPWBIA" {
+		t.Errorf("Oczekiwano oddziału BPKOPLPWBIA, otrzymano %+v", sc.Branches)
+	}
+}
+
+func TestRun_CountryHeadquartersOnly(t *testing.T) {
+	code, out := runWithCSV(t, "country", "pl", "--hq-only", "-o", "csv")
+	if code != 0 {
+		t.Fatalf("Oczekiwano kodu wyjścia 0, otrzymano %d", code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Errorf("Oczekiwano nagłówka i 2 central, otrzymano %d wierszy:\n%s", len(lines), out)
+	}
+}
+
+func TestRun_Search(t *testing.T) {
+	code, out := runWithCSV(t, "search", "pko")
+	if code != 0 {
+		t.Fatalf("Oczekiwano kodu wyjścia 0, otrzymano %d", code)
+	}
+	if !strings.Contains(out, "BPKOPLPWXXX") || strings.Contains(out, "BREXPLPWXXX") {
+		t.Errorf("Nieoczekiwany wynik wyszukiwania:\n%s", out)
+	}
+}
+
+func TestRun_Validate(t *testing.T) {
+	if code, _ := runWithCSV(t, "validate", "BPKOPLPWXXX"); code != 0 {
+		t.Errorf("Oczekiwano kodu wyjścia 0 dla poprawnego kodu, otrzymano %d", code)
+	}
+	if code, _ := runWithCSV(t, "validate", "BPKO"); code != 1 {
+		t.Errorf("Oczekiwano kodu wyjścia 1 dla niepoprawnego kodu, otrzymano %d", code)
+	}
+}
+
+func TestRun_GetNotFound(t *testing.T) {
+	if code, _ := runWithCSV(t, "get", "AAAAAAAAXXX"); code != 1 {
+		t.Errorf("Oczekiwano kodu wyjścia 1, otrzymano %d", code)
+	}
+}
+
+func TestRun_Integrity(t *testing.T) {
+	if code, out := runWithCSV(t, "integrity"); code != 0 || !strings.Contains(out, "Sprawdzono 4 rekordów, znaleziono 0 problemów.") {
+		t.Errorf("Oczekiwano spójnych danych (kod 0), otrzymano %d:\n%s", code, out)
+	}
+
+	path := filepath.Join(t.TempDir(), "orphan.csv")
+	orphan := testCSV + "PL,ORPHPLPWKRK,BIC11,ORPHAN BANK,KRAKOW,KRAKOW,POLAND,Europe/Warsaw\n"
+	if err := os.WriteFile(path, []byte(orphan), 0o644); err != nil {
+		t.Fatalf("Nie udało się zapisać pliku CSV: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--csv", path, "-o", "csv", "integrity"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("Oczekiwano kodu wyjścia 1 przy problemach, otrzymano %d", code)
+	}
+	if !strings.Contains(stdout.String(), "orphanBranch,ORPHPLPWKRK,") {
+		t.Errorf("Oczekiwano osieroconego oddziału ORPHPLPWKRK w raporcie, otrzymano:\n%s", stdout.String())
+	}
+}
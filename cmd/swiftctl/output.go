@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"swift-codes/internal/model"
+)
+
+var csvHeader = []string{"swiftCode", "bankName", "address", "countryISO2", "countryName", "isHeadquarter"}
+
+func csvRow(sc model.SwiftCode) []string {
+	return []string{sc.SwiftCode, sc.BankName, sc.Address, sc.CountryISO2, sc.CountryName, strconv.FormatBool(sc.IsHeadquarter)}
+}
+
+// writeRecords wypisuje listę rekordów; dla formatów tabelarycznych oddziały
+// centrali są dopisywane jako kolejne wiersze.
+func writeRecords(w io.Writer, format string, value any, records []model.SwiftCode) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		for _, sc := range withBranches(records) {
+			cw.Write(csvRow(sc))
+		}
+		cw.Flush()
+		return cw.Error()
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SWIFT CODE\tHQ\tBANK NAME\tCOUNTRY\tADDRESS")
+		for _, sc := range withBranches(records) {
+			hq := ""
+			if sc.IsHeadquarter {
+				hq = "*"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", sc.SwiftCode, hq, sc.BankName, sc.CountryISO2, sc.Address)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("nieznany format wyjścia %q (dostępne: table, json, csv)", format)
+}
+
+func withBranches(records []model.SwiftCode) []model.SwiftCode {
+	var rows []model.SwiftCode
+	for _, sc := range records {
+		rows = append(rows, sc)
+		rows = append(rows, sc.Branches...)
+	}
+	return rows
+}
+
+// writeIntegrity wypisuje raport spójności; w formatach tabelarycznych każdy
+// problem to jeden wiersz.
+func writeIntegrity(w io.Writer, format string, report model.IntegrityReport) error {
+	var rows [][]string
+	for _, code := range report.OrphanBranches {
+		rows = append(rows, []string{"orphanBranch", code, "brak centrali " + code[:8] + "XXX"})
+	}
+	for _, m := range report.CountryMismatches {
+		rows = append(rows, []string{"countryMismatch", m.Branch, fmt.Sprintf("centrala %s w kraju %s, oddział w %s", m.Headquarter, m.HeadquarterCountry, m.BranchCountry)})
+	}
+	for _, d := range report.Duplicates {
+		rows = append(rows, []string{"duplicate", d.Normalized, strings.Join(d.SwiftCodes, " | ")})
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"issue", "swiftCode", "detail"})
+		cw.WriteAll(rows)
+		return cw.Error()
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ISSUE\tSWIFT CODE\tDETAIL")
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		fmt.Fprintf(tw, "\nSprawdzono %d rekordów, znaleziono %d problemów.\n", report.Checked, report.Issues())
+		return tw.Flush()
+	}
+	return fmt.Errorf("nieznany format wyjścia %q (dostępne: table, json, csv)", format)
+}
@@ -15,8 +15,10 @@ if [ "$table_exists" = "f" ]; then
         address TEXT NOT NULL,
         country_iso2 VARCHAR(2) NOT NULL,
         country_name TEXT NOT NULL,
-        is_headquarter BOOLEAN NOT NULL
-    ); CREATE INDEX IF NOT EXISTS idx_country_iso2 ON swift_codes(country_iso2);"
+        is_headquarter BOOLEAN NOT NULL,
+        version BIGINT NOT NULL DEFAULT 1,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    ); CREATE INDEX IF NOT EXISTS idx_country_iso2 ON swift_codes(country_iso2); CREATE INDEX IF NOT EXISTS idx_swift_code_normalized ON swift_codes ((UPPER(REGEXP_REPLACE(swift_code, '\\s+', '', 'g'))) text_pattern_ops); CREATE INDEX IF NOT EXISTS idx_swift_code_bic8 ON swift_codes ((LEFT(UPPER(REGEXP_REPLACE(swift_code, '\\s+', '', 'g')), 8)));"
     echo "Schemat utworzony."
 else
     echo "Tabela swift_codes już istnieje."
@@ -28,6 +30,8 @@ row_count=$(psql "$DB_CONN" -t -c "SELECT COUNT(*) FROM swift_codes;" | xargs)
 if [ "$row_count" -eq 0 ]; then
     echo "Baza jest pusta. Importowanie danych z CSV..."
     ./swift-codes-import --import
+    echo "Importowanie krajowych identyfikatorów banków..."
+    ./swift-codes-import bank-codes data/bank_codes.csv
 else
     echo "Baza już zawiera dane. Pominę import."
 fi
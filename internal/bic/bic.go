@@ -0,0 +1,100 @@
+package bic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrLength   = errors.New("kod SWIFT musi mieć 8 lub 11 znaków")
+	ErrBank     = errors.New("kod banku (znaki 1-4) musi składać się z liter")
+	ErrCountry  = errors.New("kod kraju (znaki 5-6) musi składać się z liter")
+	ErrLocation = errors.New("kod lokalizacji (znaki 7-8) musi składać się z liter lub cyfr")
+	ErrBranch   = errors.New("kod oddziału (znaki 9-11) musi składać się z liter lub cyfr")
+)
+
+type Parts struct {
+	Bank     string `json:"bank"`
+	Country  string `json:"country"`
+	Location string `json:"location"`
+	Branch   string `json:"branch,omitempty"`
+}
+
+// Parse sprawdza format kodu BIC (ISO 9362) i zwraca jego części.
+// Kod musi być już zapisany wielkimi literami.
+func Parse(code string) (Parts, error) {
+	if len(code) != 8 && len(code) != 11 {
+		return Parts{}, fmt.Errorf("%w, otrzymano %d", ErrLength, len(code))
+	}
+
+	parts := Parts{
+		Bank:     code[0:4],
+		Country:  code[4:6],
+		Location: code[6:8],
+	}
+	if len(code) == 11 {
+		parts.Branch = code[8:11]
+	}
+
+	switch {
+	case !isLetters(parts.Bank):
+		return parts, ErrBank
+	case !isLetters(parts.Country):
+		return parts, ErrCountry
+	case !isAlnum(parts.Location):
+		return parts, ErrLocation
+	case !isAlnum(parts.Branch):
+		return parts, ErrBranch
+	}
+	return parts, nil
+}
+
+func Validate(code string) error {
+	_, err := Parse(code)
+	return err
+}
+
+// Normalize usuwa z kodu wszystkie białe znaki (także wewnątrz, np.
+// "BPKO PL PW") i zamienia litery na wielkie.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, code))
+}
+
+// Canonical normalizuje kod i zamienia BIC8 na kod centrali (BIC8 + "XXX"),
+// czyli na postać, w jakiej kody są przechowywane w katalogu.
+func Canonical(code string) string {
+	code = Normalize(code)
+	if len(code) == 8 {
+		return code + "XXX"
+	}
+	return code
+}
+
+func IsHeadquarter(code string) bool {
+	return len(code) == 8 || (len(code) == 11 && code[8:] == "XXX")
+}
+
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 'A' || s[i] > 'Z') && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
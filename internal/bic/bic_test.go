@@ -0,0 +1,67 @@
+package bic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code string
+		err  error
+	}{
+		{"AAISALTRXXX", nil},
+		{"AAISALTR", nil},
+		{"BREXPLPWMBK", nil},
+		{"AAISALT", ErrLength},
+		{"AAISALTRXX", ErrLength},
+		{"AA1SALTRXXX", ErrBank},
+		{"AAIS1LTRXXX", ErrCountry},
+		{"AAISAL-RXXX", ErrLocation},
+		{"AAISALTRXX_", ErrBranch},
+		{"aaisaltrxxx", ErrBank},
+	}
+
+	for _, tc := range tests {
+		_, err := Parse(tc.code)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("Parse(%q) - oczekiwano błędu %v, otrzymano %v", tc.code, tc.err, err)
+		}
+	}
+
+	parts, _ := Parse("BREXPLPWMBK")
+	if parts.Bank != "BREX" || parts.Country != "PL" || parts.Location != "PW" || parts.Branch != "MBK" {
+		t.Errorf("Nieprawidłowy podział kodu BREXPLPWMBK: %+v", parts)
+	}
+}
+
+func TestIsHeadquarter(t *testing.T) {
+	for code, want := range map[string]bool{
+		"AAISALTRXXX": true,
+		"AAISALTR":    true,
+		"BREXPLPWMBK": false,
+	} {
+		if got := IsHeadquarter(code); got != want {
+			t.Errorf("IsHeadquarter(%q) - oczekiwano %v, otrzymano %v", code, want, got)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := map[string]string{
+		"AAISALTRXXX":    "AAISALTRXXX",
+		" aaisaltrxxx\t": "AAISALTRXXX",
+		"AAISALTR":       "AAISALTRXXX",
+		"bpko pl pw":     "BPKOPLPWXXX",
+		"BPKO PLPW BIA":  "BPKOPLPWBIA",
+		"BPKO":           "BPKO",
+	}
+	for input, want := range tests {
+		if got := Canonical(input); got != want {
+			t.Errorf("Canonical(%q): oczekiwano %q, otrzymano %q", input, want, got)
+		}
+	}
+	if got := Normalize(" aaisaltr "); got != "AAISALTR" {
+		t.Errorf("Normalize nie powinien dopisywać XXX, otrzymano %q", got)
+	}
+}
@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type Stats struct {
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	SharedLoads uint64  `json:"sharedLoads"`
+	Evictions   uint64  `json:"evictions"`
+	Expirations uint64  `json:"expirations"`
+	Size        int     `json:"size"`
+	Capacity    int     `json:"capacity"`
+	TTLSeconds  float64 `json:"ttlSeconds"`
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// Cache to ograniczony cache LRU z czasem życia wpisów. Równoległe chybienia
+// dla tego samego klucza są łączone w jedno wywołanie funkcji ładującej.
+// Metody można wywoływać na nil, wtedy cache jest po prostu pomijany.
+type Cache[V any] struct {
+	mu         sync.Mutex
+	capacity   int
+	ttl        time.Duration
+	ll         *list.List
+	items      map[string]*list.Element
+	generation uint64
+	group      singleflight.Group
+	now        func() time.Time
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	sharedLoads atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+}
+
+func New[V any](capacity int, ttl time.Duration) *Cache[V] {
+	if capacity <= 0 {
+		return nil
+	}
+	return &Cache[V]{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (c *Cache[V]) Get(key string) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return zero, false
+	}
+	e := el.Value.(*entry[V])
+	if c.ttl > 0 && c.now().After(e.expiresAt) {
+		c.removeElement(el)
+		c.expirations.Add(1)
+		c.misses.Add(1)
+		return zero, false
+	}
+	c.ll.MoveToFront(el)
+	c.hits.Add(1)
+	return e.value, true
+}
+
+func (c *Cache[V]) Set(key string, value V) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value)
+}
+
+func (c *Cache[V]) set(key string, value V) {
+	expiresAt := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// GetOrLoad zwraca wartość z cache lub ładuje ją funkcją load. Błędy nie są
+// zapamiętywane. Wynik ładowania, które trwało w chwili unieważnienia, nie
+// trafia do cache, żeby nie przywrócić nieaktualnych danych.
+func (c *Cache[V]) GetOrLoad(key string, load func() (V, error)) (V, error) {
+	if c == nil {
+		return load()
+	}
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	value, err, shared := c.group.Do(key, func() (any, error) {
+		value, err := load()
+		if err != nil {
+			return value, err
+		}
+		c.mu.Lock()
+		if c.generation == generation {
+			c.set(key, value)
+		}
+		c.mu.Unlock()
+		return value, nil
+	})
+	if shared {
+		c.sharedLoads.Add(1)
+	}
+	return value.(V), err
+}
+
+func (c *Cache[V]) Invalidate(key string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	c.group.Forget(key)
+}
+
+func (c *Cache[V]) InvalidatePrefix(prefix string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+			c.group.Forget(key)
+		}
+	}
+}
+
+// InvalidateFunc usuwa wpisy, dla których match zwraca true.
+func (c *Cache[V]) InvalidateFunc(match func(key string, value V) bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for key, el := range c.items {
+		if match(key, el.Value.(*entry[V]).value) {
+			c.removeElement(el)
+			c.group.Forget(key)
+		}
+	}
+}
+
+func (c *Cache[V]) Purge() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for key := range c.items {
+		c.group.Forget(key)
+	}
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *Cache[V]) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+
+	c.mu.Lock()
+	size := c.ll.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		SharedLoads: c.sharedLoads.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+		Size:        size,
+		Capacity:    c.capacity,
+		TTLSeconds:  c.ttl.Seconds(),
+	}
+}
+
+func (c *Cache[V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[V]).key)
+}
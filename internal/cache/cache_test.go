@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[string](2, time.Minute)
+	c.Set("A", "a")
+	c.Set("B", "b")
+	c.Get("A")
+	c.Set("C", "c")
+
+	if _, ok := c.Get("B"); ok {
+		t.Error("Oczekiwano, że wpis B zostanie usunięty jako najdawniej używany")
+	}
+	if _, ok := c.Get("A"); !ok {
+		t.Error("Oczekiwano, że wpis A pozostanie w cache")
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("Oczekiwano 1 usunięcia i rozmiaru 2, otrzymano %d i %d", stats.Evictions, stats.Size)
+	}
+}
+
+func TestCache_ExpiresEntries(t *testing.T) {
+	c := New[string](10, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.Set("A", "a")
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("A"); ok {
+		t.Error("Oczekiwano, że wpis wygaśnie po upływie TTL")
+	}
+	if stats := c.Stats(); stats.Expirations != 1 {
+		t.Errorf("Oczekiwano 1 wygaśnięcia, otrzymano %d", stats.Expirations)
+	}
+}
+
+func TestCache_GetOrLoadDeduplicatesConcurrentMisses(t *testing.T) {
+	c := New[string](10, time.Minute)
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.GetOrLoad("A", func() (string, error) {
+				calls.Add(1)
+				<-release
+				return "a", nil
+			})
+			if err != nil || value != "a" {
+				t.Errorf("Oczekiwano wartości 'a', otrzymano '%s' (%v)", value, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Oczekiwano 1 wywołania funkcji ładującej, otrzymano %d", calls.Load())
+	}
+	if _, ok := c.Get("A"); !ok {
+		t.Error("Oczekiwano, że załadowana wartość trafi do cache")
+	}
+}
+
+func TestCache_GetOrLoadDoesNotCacheErrors(t *testing.T) {
+	c := New[string](10, time.Minute)
+	_, err := c.GetOrLoad("A", func() (string, error) {
+		return "", errors.New("brak")
+	})
+	if err == nil {
+		t.Fatal("Oczekiwano błędu z funkcji ładującej")
+	}
+	if _, ok := c.Get("A"); ok {
+		t.Error("Oczekiwano, że błąd nie zostanie zapamiętany")
+	}
+}
+
+func TestCache_InvalidatePrefix(t *testing.T) {
+	c := New[string](10, time.Minute)
+	c.Set("AAISALTRXXX", "hq")
+	c.Set("AAISALTR001", "branch")
+	c.Set("ABIEBGS1XXX", "other")
+
+	c.InvalidatePrefix("AAISALTR")
+
+	if _, ok := c.Get("AAISALTRXXX"); ok {
+		t.Error("Oczekiwano usunięcia centrali z cache")
+	}
+	if _, ok := c.Get("AAISALTR001"); ok {
+		t.Error("Oczekiwano usunięcia oddziału z cache")
+	}
+	if _, ok := c.Get("ABIEBGS1XXX"); !ok {
+		t.Error("Oczekiwano, że wpis innego banku pozostanie w cache")
+	}
+}
+
+func TestCache_InvalidateDuringLoadDropsStaleValue(t *testing.T) {
+	c := New[string](10, time.Minute)
+	_, err := c.GetOrLoad("A", func() (string, error) {
+		c.Invalidate("A")
+		return "stara", nil
+	})
+	if err != nil {
+		t.Fatalf("GetOrLoad nie powiodło się: %v", err)
+	}
+	if _, ok := c.Get("A"); ok {
+		t.Error("Oczekiwano, że wartość załadowana przed unieważnieniem nie trafi do cache")
+	}
+}
+
+func TestCache_NilIsPassThrough(t *testing.T) {
+	var c *Cache[string]
+	value, err := c.GetOrLoad("A", func() (string, error) { return "a", nil })
+	if err != nil || value != "a" {
+		t.Errorf("Oczekiwano wartości 'a', otrzymano '%s' (%v)", value, err)
+	}
+	c.Invalidate("A")
+	c.Purge()
+}
@@ -0,0 +1,52 @@
+// Package country zawiera wbudowany rejestr krajów ISO 3166-1 alpha-2.
+// Nazwy są zapisane wielkimi literami, tak jak w pliku źródłowym SWIFT.
+package country
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"sort"
+	"strings"
+)
+
+//go:embed iso3166.csv
+var registryCSV string
+
+type Country struct {
+	ISO2 string `json:"countryISO2"`
+	Name string `json:"countryName"`
+}
+
+var (
+	byCode = map[string]Country{}
+	all    []Country
+)
+
+func init() {
+	records, err := csv.NewReader(strings.NewReader(registryCSV)).ReadAll()
+	if err != nil {
+		panic("country: uszkodzony rejestr ISO 3166: " + err.Error())
+	}
+	for _, record := range records {
+		c := Country{ISO2: record[0], Name: record[1]}
+		byCode[c.ISO2] = c
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ISO2 < all[j].ISO2 })
+}
+
+// Lookup zwraca kraj o podanym kodzie (bez rozróżniania wielkości liter).
+func Lookup(iso2 string) (Country, bool) {
+	c, ok := byCode[strings.ToUpper(strings.TrimSpace(iso2))]
+	return c, ok
+}
+
+// Name zwraca kanoniczną nazwę kraju albo pusty napis dla nieznanego kodu.
+func Name(iso2 string) string {
+	return byCode[strings.ToUpper(strings.TrimSpace(iso2))].Name
+}
+
+// All zwraca wszystkie kraje rejestru posortowane po kodzie.
+func All() []Country {
+	return append([]Country(nil), all...)
+}
@@ -0,0 +1,52 @@
+package country
+
+import (
+	"encoding/csv"
+	"os"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	c, ok := Lookup(" pl ")
+	if !ok || c.ISO2 != "PL" || c.Name != "POLAND" {
+		t.Errorf("Oczekiwano kraju PL/POLAND, otrzymano %+v (%v)", c, ok)
+	}
+	if _, ok := Lookup("AA"); ok {
+		t.Error("Oczekiwano, że kod AA nie należy do rejestru")
+	}
+	if name := Name("XX"); name != "" {
+		t.Errorf("Oczekiwano pustej nazwy dla nieznanego kodu, otrzymano %q", name)
+	}
+}
+
+func TestAll_SortedAndComplete(t *testing.T) {
+	countries := All()
+	if len(countries) != 249 {
+		t.Errorf("Oczekiwano 249 krajów, otrzymano %d", len(countries))
+	}
+	for i := 1; i < len(countries); i++ {
+		if countries[i-1].ISO2 >= countries[i].ISO2 {
+			t.Fatalf("Rejestr nie jest posortowany: %s przed %s", countries[i-1].ISO2, countries[i].ISO2)
+		}
+	}
+}
+
+// Nazwy krajów w danych źródłowych muszą zgadzać się z rejestrem, inaczej
+// import zmieniałby je przy każdym wczytaniu pliku.
+func TestRegistryMatchesSourceData(t *testing.T) {
+	file, err := os.Open("../../data/swiftcodes_data.csv")
+	if err != nil {
+		t.Fatalf("Nie udało się otworzyć danych źródłowych: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Błąd odczytu CSV: %v", err)
+	}
+	for _, record := range records[1:] {
+		if name := Name(record[0]); name != record[6] {
+			t.Errorf("Kraj %s: rejestr %q, dane źródłowe %q", record[0], name, record[6])
+		}
+	}
+}
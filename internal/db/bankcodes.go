@@ -0,0 +1,83 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+
+	"swift-codes/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ReplaceBankCodes zastępuje wpisy tabeli krajowych identyfikatorów banków
+// dla krajów obecnych w codes, więc wpis usunięty z pliku znika z bazy.
+// Wpisy innych krajów pozostają bez zmian.
+func ReplaceBankCodes(db *sql.DB, codes []model.BankCode) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	countries := map[string]bool{}
+	for _, bc := range codes {
+		countries[bc.CountryISO2] = true
+	}
+	list := make([]string, 0, len(countries))
+	for c := range countries {
+		list = append(list, c)
+	}
+	sort.Strings(list)
+	if _, err := tx.Exec(`DELETE FROM national_bank_codes WHERE country_iso2 = ANY($1)`, pq.Array(list)); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("national_bank_codes", "country_iso2", "bank_code", "swift_code", "bank_name"))
+	if err != nil {
+		return err
+	}
+	for _, bc := range codes {
+		if _, err := stmt.Exec(bc.CountryISO2, bc.BankCode, bc.SwiftCode, bc.BankName); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return err
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindBankCodes zwraca wpisy kraju iso2 z najdłuższym identyfikatorem, którym
+// zaczyna się nationalID. Dzięki temu wpis dla całego numeru rozliczeniowego
+// oddziału ma pierwszeństwo przed wpisem dla numeru banku.
+func FindBankCodes(db *sql.DB, iso2, nationalID string) ([]model.BankCode, error) {
+	query := `
+		SELECT country_iso2, bank_code, swift_code, bank_name
+		FROM national_bank_codes
+		WHERE country_iso2 = $1 AND bank_code = LEFT($2, LENGTH(bank_code))
+		ORDER BY LENGTH(bank_code) DESC, swift_code
+	`
+	rows, err := db.Query(query, iso2, nationalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []model.BankCode
+	for rows.Next() {
+		var bc model.BankCode
+		if err := rows.Scan(&bc.CountryISO2, &bc.BankCode, &bc.SwiftCode, &bc.BankName); err != nil {
+			return nil, err
+		}
+		if len(codes) > 0 && len(bc.BankCode) < len(codes[0].BankCode) {
+			break
+		}
+		codes = append(codes, bc)
+	}
+	return codes, rows.Err()
+}
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+// changeLogLock to klucz blokady doradczej, która szereguje dopisywanie do
+// dziennika zmian.
+const changeLogLock = 4402
+
+// newChange tworzy zmianę typu changeType dla rekordu sc. Oddziały nie są
+// częścią zmiany; oddział wskazuje tylko kod swojej centrali, więc zmiana
+// z przeniesienia oddziału różni się od poprzedniej odnośnikiem do centrali.
+func newChange(changeType string, sc model.SwiftCode) model.Change {
+	sc.Branches = nil
+	sc.Headquarter = nil
+	if sc.HeadquarterCode != "" {
+		sc.Headquarter = &model.HeadquarterRef{SwiftCode: sc.HeadquarterCode}
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return model.Change{
+		ID:         hex.EncodeToString(id),
+		Type:       changeType,
+		OccurredAt: time.Now().UTC(),
+		SwiftCode:  sc.SwiftCode,
+		Record:     sc,
+	}
+}
+
+func newChanges(changeType string, records []model.SwiftCode) []model.Change {
+	changes := make([]model.Change, len(records))
+	for i, sc := range records {
+		changes[i] = newChange(changeType, sc)
+	}
+	return changes
+}
+
+// withChanges wykonuje fn w transakcji i dopisuje zwrócone przez nią zmiany
+// do dziennika zmian, który jest jednocześnie outboxem dla dyspozytora
+// zdarzeń. Zmiany są zapisywane po wszystkich modyfikacjach rekordów, tuż
+// przed zatwierdzeniem: blokada dziennika jest brana jako ostatnia, więc
+// transakcje czekające na blokady wierszy jej nie trzymają.
+func withChanges(db *sql.DB, fn func(tx *sql.Tx) ([]model.Change, error)) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	changes, err := fn(tx)
+	if err != nil {
+		return err
+	}
+	if err := appendChanges(tx, changes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// appendChanges dopisuje zmiany do dziennika i ustawia ich Sequence
+// i OccurredAt. Blokada trzymana do końca transakcji tx sprawia, że numery
+// są zatwierdzane w kolejności rosnącej, więc czytelnik dziennika nie
+// pominie zmiany zatwierdzonej później z niższym numerem.
+func appendChanges(tx *sql.Tx, changes []model.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, changeLogLock); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO swift_code_changes (event_id, event_type, swift_code, record)
+		VALUES ($1, $2, $3, $4)
+		RETURNING seq, occurred_at
+	`
+	for i := range changes {
+		c := &changes[i]
+		record, err := json.Marshal(c.Record)
+		if err != nil {
+			return err
+		}
+		if err := tx.QueryRow(query, c.ID, c.Type, c.SwiftCode, string(record)).Scan(&c.Sequence, &c.OccurredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListChanges zwraca do limit zmian o numerach większych niż since,
+// w kolejności numerów.
+func ListChanges(db *sql.DB, since int64, limit int) ([]model.Change, error) {
+	query := `
+		SELECT seq, event_id, event_type, swift_code, record, occurred_at
+		FROM swift_code_changes
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`
+	rows, err := db.Query(query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.Change{}
+	for rows.Next() {
+		var c model.Change
+		var record string
+		if err := rows.Scan(&c.Sequence, &c.ID, &c.Type, &c.SwiftCode, &record, &c.OccurredAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(record), &c.Record); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// LatestChangeSequence zwraca numer ostatniej zmiany albo 0 dla pustego
+// dziennika.
+func LatestChangeSequence(db *sql.DB) (int64, error) {
+	var seq int64
+	err := db.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM swift_code_changes`).Scan(&seq)
+	return seq, err
+}
+
+// ClaimOutbox przedłuża (albo przejmuje po wygaśnięciu) dzierżawę odbiorcy
+// sink dla instancji owner i zwraca numer ostatniej przekazanej mu zmiany.
+// ok jest false, jeśli dzierżawę trzyma inna instancja. Nowy odbiorca zaczyna
+// od bieżącego końca dziennika, żeby nie dostać całej historii zmian.
+func ClaimOutbox(db *sql.DB, sink, owner string, lease time.Duration) (position int64, ok bool, err error) {
+	_, err = db.Exec(`
+		INSERT INTO outbox_cursors (sink, position)
+		SELECT $1, COALESCE(MAX(seq), 0) FROM swift_code_changes
+		ON CONFLICT (sink) DO NOTHING
+	`, sink)
+	if err != nil {
+		return 0, false, err
+	}
+	query := `
+		UPDATE outbox_cursors
+		SET lease_owner = $2, lease_until = now() + make_interval(secs => $3)
+		WHERE sink = $1 AND (lease_owner = $2 OR lease_until IS NULL OR lease_until < now())
+		RETURNING position
+	`
+	err = db.QueryRow(query, sink, owner, lease.Seconds()).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return position, err == nil, err
+}
+
+// SetOutboxPosition zapisuje, że odbiorca sink dostał wszystkie zmiany do
+// numeru position włącznie. Zwraca sql.ErrNoRows, jeśli instancja owner
+// straciła dzierżawę.
+func SetOutboxPosition(db *sql.DB, sink, owner string, position int64) error {
+	res, err := db.Exec(`UPDATE outbox_cursors SET position = $3, updated_at = now() WHERE sink = $1 AND lease_owner = $2`, sink, owner, position)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return noRows(err)
+	}
+	return nil
+}
+
+// ListOutboxCursors zwraca pozycje wszystkich odbiorców wraz z liczbą
+// zmian, które na nich czekają.
+func ListOutboxCursors(db *sql.DB) ([]model.OutboxCursor, error) {
+	rows, err := db.Query(`
+		SELECT c.sink, c.position, GREATEST(l.latest - c.position, 0), c.updated_at
+		FROM outbox_cursors c
+		CROSS JOIN (SELECT COALESCE(MAX(seq), 0) AS latest FROM swift_code_changes) l
+		ORDER BY c.sink
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cursors := []model.OutboxCursor{}
+	for rows.Next() {
+		var c model.OutboxCursor
+		if err := rows.Scan(&c.Sink, &c.Position, &c.Pending, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		cursors = append(cursors, c)
+	}
+	return cursors, rows.Err()
+}
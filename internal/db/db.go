@@ -1,15 +1,83 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
-	
+
+	"swift-codes/internal/bic"
 	"swift-codes/internal/model"
 
-	_ "github.com/lib/pq" 
+	"github.com/lib/pq"
+)
+
+var (
+	ErrConflict  = errors.New("rekord został zmieniony przez inną operację")
+	ErrDuplicate = errors.New("kod SWIFT już istnieje")
+	ErrInvalid   = errors.New("baza danych odrzuciła rekord")
 )
 
+// RecordError wskazuje rekord, na którym SaveSwiftCodes przerwało zapis.
+type RecordError struct {
+	Index     int
+	SwiftCode string
+	Err       error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("rekord %d (%s): %v", e.Index, e.SwiftCode, e.Err)
+}
+
+func (e *RecordError) Unwrap() error { return e.Err }
+
+const swiftCodeColumns = `swift_code, bank_name, address, country_iso2, country_name, is_headquarter, version, updated_at, headquarter_code`
+
+// normalizedCode to kod w postaci z bic.Normalize (bez białych znaków, wielkie
+// litery). Wyszukiwanie po kodzie porównuje to wyrażenie, więc znajduje też
+// rekordy zapisane przed wprowadzeniem normalizacji; indeks
+// idx_swift_code_normalized utrzymuje takie zapytania (także LIKE 'prefiks%')
+// na indeksie.
+const normalizedCode = `UPPER(REGEXP_REPLACE(swift_code, '\s+', '', 'g'))`
+
+// linkedBranches to warunek wybierający oddziały centrali $1: powiązane z nią
+// jawnie przez headquarter_code albo, bez jawnego powiązania, należące do jej
+// instytucji ($2 to jej BIC8). headquarter_code ustawia tylko przeniesienie
+// oddziałów (ReparentBranches); kody SWIFT się przy tym nie zmieniają.
+const linkedBranches = `NOT is_headquarter AND (headquarter_code = $1 OR (headquarter_code IS NULL AND LEFT(` + normalizedCode + `, 8) = $2))`
+
+// Querier pozwala wywoływać te same zapytania na *sql.DB i wewnątrz *sql.Tx.
+type Querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSwiftCode(row scanner) (model.SwiftCode, error) {
+	var sc model.SwiftCode
+	var hq sql.NullString
+	err := row.Scan(&sc.SwiftCode, &sc.BankName, &sc.Address, &sc.CountryISO2, &sc.CountryName, &sc.IsHeadquarter, &sc.Version, &sc.UpdatedAt, &hq)
+	sc.HeadquarterCode = hq.String
+	return sc, err
+}
+
+func scanSwiftCodes(rows *sql.Rows) ([]model.SwiftCode, error) {
+	var codes []model.SwiftCode
+	for rows.Next() {
+		sc, err := scanSwiftCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, sc)
+	}
+	return codes, rows.Err()
+}
+
 func InitDB(connStr string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -38,60 +106,280 @@ func createSchema(db *sql.DB) error {
 		is_headquarter BOOLEAN NOT NULL
 	);
 	CREATE INDEX IF NOT EXISTS idx_country_iso2 ON swift_codes(country_iso2);
+	ALTER TABLE swift_codes ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+	ALTER TABLE swift_codes ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+	CREATE INDEX IF NOT EXISTS idx_swift_code_normalized ON swift_codes ((` + normalizedCode + `) text_pattern_ops);
+	CREATE INDEX IF NOT EXISTS idx_swift_code_bic8 ON swift_codes ((LEFT(` + normalizedCode + `, 8)));
+	ALTER TABLE swift_codes ADD COLUMN IF NOT EXISTS headquarter_code VARCHAR(20);
+	CREATE INDEX IF NOT EXISTS idx_swift_code_headquarter ON swift_codes (headquarter_code);
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id BIGSERIAL PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		country_iso2 VARCHAR(2) NOT NULL DEFAULT '',
+		bic8 VARCHAR(8) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id BIGSERIAL PRIMARY KEY,
+		subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		swift_code TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		dead BOOLEAN NOT NULL DEFAULT FALSE,
+		next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE NOT dead;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event ON webhook_deliveries (subscription_id, event_id);
+	CREATE TABLE IF NOT EXISTS swift_code_changes (
+		seq BIGSERIAL PRIMARY KEY,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		swift_code TEXT NOT NULL,
+		record TEXT NOT NULL,
+		occurred_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE TABLE IF NOT EXISTS outbox_cursors (
+		sink TEXT PRIMARY KEY,
+		position BIGINT NOT NULL,
+		lease_owner TEXT NOT NULL DEFAULT '',
+		lease_until TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE TABLE IF NOT EXISTS api_usage (
+		day DATE NOT NULL,
+		client TEXT NOT NULL,
+		route_class TEXT NOT NULL,
+		requests BIGINT NOT NULL DEFAULT 0,
+		rejected BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (day, client, route_class)
+	);
+	CREATE TABLE IF NOT EXISTS dataset_snapshots (
+		id BIGSERIAL PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		source TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		records INT NOT NULL DEFAULT 0,
+		problems TEXT NOT NULL DEFAULT '[]',
+		integrity TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		activated_at TIMESTAMPTZ
+	);
+	ALTER TABLE dataset_snapshots ADD COLUMN IF NOT EXISTS activated_seq BIGINT;
+	CREATE TABLE IF NOT EXISTS national_bank_codes (
+		country_iso2 VARCHAR(2) NOT NULL,
+		bank_code TEXT NOT NULL,
+		swift_code VARCHAR(20) NOT NULL,
+		bank_name TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (country_iso2, bank_code, swift_code)
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_dataset_snapshots_active ON dataset_snapshots (status) WHERE status = 'active';
+	INSERT INTO dataset_snapshots (name, status, activated_at)
+	SELECT 'initial', 'active', now()
+	WHERE NOT EXISTS (SELECT 1 FROM dataset_snapshots WHERE status = 'active')
+	ON CONFLICT DO NOTHING;
+	UPDATE dataset_snapshots SET activated_seq = (SELECT COALESCE(MAX(seq), 0) FROM swift_code_changes)
+	WHERE status = 'active' AND activated_seq IS NULL;
 	`
-	_, err := db.Exec(schema)
-	return err
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Tabele nieaktywnych wersji danych utworzone przed dodaniem kolumny
+	// headquarter_code.
+	rows, err := db.Query(`SELECT id FROM dataset_snapshots WHERE status <> 'active'`)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, fmt.Sprintf("swift_codes_snapshot_%d", id))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		var exists bool
+		if err := db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN IF NOT EXISTS headquarter_code VARCHAR(20)`); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func GetSwiftCode(db *sql.DB, code string) (model.SwiftCode, error) {
-	var sc model.SwiftCode
 	query := `
-		SELECT swift_code, bank_name, address, country_iso2, country_name, is_headquarter
+		SELECT ` + swiftCodeColumns + `
 		FROM swift_codes
-		WHERE swift_code = $1
+		WHERE ` + normalizedCode + ` = $1
 	`
-	row := db.QueryRow(query, code)
-	err := row.Scan(&sc.SwiftCode, &sc.BankName, &sc.Address, &sc.CountryISO2, &sc.CountryName, &sc.IsHeadquarter)
+	return scanSwiftCode(db.QueryRow(query, code))
+}
+
+
+// BIC8 zwraca kod instytucji, czyli pierwsze 8 znaków kodu SWIFT.
+func BIC8(code string) string {
+	if len(code) >= 8 {
+		return code[:8]
+	}
+	return code
+}
+
+// GetBranchesByHeadquarter zwraca oddziały powiązane z centralą. Po
+// przeniesieniu oddziałów mogą one mieć inny BIC8 niż centrala.
+func GetBranchesByHeadquarter(db *sql.DB, headquarterCode string) ([]model.SwiftCode, error) {
+	query := `
+		SELECT ` + swiftCodeColumns + `
+		FROM swift_codes
+		WHERE ` + linkedBranches + `
+	`
+	code := bic.Normalize(headquarterCode)
+	rows, err := db.Query(query, code, BIC8(code))
 	if err != nil {
-		return sc, err
+		return nil, err
 	}
-	return sc, nil
+	defer rows.Close()
+
+	return scanSwiftCodes(rows)
 }
 
+// GetBranchesByHeadquarters zwraca jednym zapytaniem oddziały wielu central
+// (kodów w postaci znormalizowanej), posortowane po kodzie.
+func GetBranchesByHeadquarters(db *sql.DB, headquarterCodes []string) (map[string][]model.SwiftCode, error) {
+	byBIC8 := make(map[string][]string, len(headquarterCodes))
+	for _, code := range headquarterCodes {
+		byBIC8[BIC8(code)] = append(byBIC8[BIC8(code)], code)
+	}
+	bic8s := make([]string, 0, len(byBIC8))
+	for bic8 := range byBIC8 {
+		bic8s = append(bic8s, bic8)
+	}
 
-func GetBranchesByHeadquarter(db *sql.DB, headquarterCode string) ([]model.SwiftCode, error) {
-	var branches []model.SwiftCode
-	prefix := headquarterCode
-	if len(headquarterCode) >= 8 {
-		prefix = headquarterCode[:8]
+	query := `
+		SELECT ` + swiftCodeColumns + `
+		FROM swift_codes
+		WHERE NOT is_headquarter
+		  AND (headquarter_code = ANY($1) OR (headquarter_code IS NULL AND LEFT(` + normalizedCode + `, 8) = ANY($2)))
+		ORDER BY swift_code
+	`
+	rows, err := db.Query(query, pq.Array(headquarterCodes), pq.Array(bic8s))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	branches, err := scanSwiftCodes(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]model.SwiftCode, len(headquarterCodes))
+	for _, sc := range branches {
+		if sc.HeadquarterCode != "" {
+			result[sc.HeadquarterCode] = append(result[sc.HeadquarterCode], sc)
+			continue
+		}
+		for _, hq := range byBIC8[BIC8(bic.Normalize(sc.SwiftCode))] {
+			result[hq] = append(result[hq], sc)
+		}
+	}
+	return result, nil
+}
+
+// GetSwiftCodesByBIC8 zwraca wszystkie kody instytucji (centralę i oddziały),
+// posortowane po kodzie.
+func GetSwiftCodesByBIC8(db *sql.DB, bic8 string) ([]model.SwiftCode, error) {
+	query := `
+		SELECT ` + swiftCodeColumns + `
+		FROM swift_codes
+		WHERE ` + normalizedCode + ` LIKE $1
+		ORDER BY swift_code
+	`
+	rows, err := db.Query(query, BIC8(bic8)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSwiftCodes(rows)
+}
+
+// GetSwiftCodesByBIC8s zwraca jednym zapytaniem kody wielu instytucji,
+// posortowane po kodzie.
+func GetSwiftCodesByBIC8s(db *sql.DB, bic8s []string) ([]model.SwiftCode, error) {
+	query := `
+		SELECT ` + swiftCodeColumns + `
+		FROM swift_codes
+		WHERE LEFT(` + normalizedCode + `, 8) = ANY($1)
+		ORDER BY swift_code
+	`
+	rows, err := db.Query(query, pq.Array(bic8s))
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
+
+	return scanSwiftCodes(rows)
+}
 
+// ListBanks grupuje kody kraju po BIC8. Nazwa banku pochodzi z centrali,
+// a gdy jej brak - z pierwszego oddziału.
+func ListBanks(db *sql.DB, iso2 string) ([]model.BankSummary, error) {
+	iso2 = strings.ToUpper(iso2)
+	banks, err := ListBanksByCountry(db, []string{iso2})
+	return banks[iso2], err
+}
+
+// ListBanksByCountry działa jak ListBanks dla wielu krajów naraz i zwraca
+// banki pogrupowane po kodzie kraju.
+func ListBanksByCountry(db *sql.DB, iso2s []string) (map[string][]model.BankSummary, error) {
 	query := `
-		SELECT swift_code, bank_name, address, country_iso2, country_name, is_headquarter
+		SELECT country_iso2,
+		       LEFT(` + normalizedCode + `, 8) AS bic8,
+		       COALESCE(MIN(bank_name) FILTER (WHERE is_headquarter), MIN(bank_name)),
+		       BOOL_OR(is_headquarter),
+		       COUNT(*) FILTER (WHERE NOT is_headquarter)
 		FROM swift_codes
-		WHERE swift_code LIKE $1 AND is_headquarter = FALSE
+		WHERE country_iso2 = ANY($1)
+		GROUP BY country_iso2, bic8
+		ORDER BY country_iso2, bic8
 	`
-	rows, err := db.Query(query, prefix+"%")
+	rows, err := db.Query(query, pq.Array(iso2s))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	banks := map[string][]model.BankSummary{}
 	for rows.Next() {
-		var sc model.SwiftCode
-		if err := rows.Scan(&sc.SwiftCode, &sc.BankName, &sc.Address, &sc.CountryISO2, &sc.CountryName, &sc.IsHeadquarter); err != nil {
+		var iso2 string
+		var b model.BankSummary
+		if err := rows.Scan(&iso2, &b.BIC8, &b.BankName, &b.HasHeadquarter, &b.Branches); err != nil {
 			return nil, err
 		}
-		branches = append(branches, sc)
+		banks[iso2] = append(banks[iso2], b)
 	}
-	return branches, nil
+	return banks, rows.Err()
 }
 
-
 func GetSwiftCodesByCountry(db *sql.DB, iso2 string) ([]model.SwiftCode, error) {
-	var codes []model.SwiftCode
 	query := `
-		SELECT swift_code, bank_name, address, country_iso2, country_name, is_headquarter
+		SELECT ` + swiftCodeColumns + `
 		FROM swift_codes
 		WHERE country_iso2 = $1
 	`
@@ -101,36 +389,461 @@ func GetSwiftCodesByCountry(db *sql.DB, iso2 string) ([]model.SwiftCode, error)
 	}
 	defer rows.Close()
 
+	return scanSwiftCodes(rows)
+}
+
+
+func GetSwiftCodes(db *sql.DB, codes []string) ([]model.SwiftCode, error) {
+	query := `
+		SELECT ` + swiftCodeColumns + `
+		FROM swift_codes
+		WHERE ` + normalizedCode + ` = ANY($1)
+	`
+	rows, err := db.Query(query, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSwiftCodes(rows)
+}
+
+// CountSwiftCodesByCountry zwraca kraje obecne w katalogu z liczbą central
+// i oddziałów, posortowane po kodzie kraju.
+func CountSwiftCodesByCountry(db *sql.DB) ([]model.CountrySummary, error) {
+	query := `
+		SELECT country_iso2,
+		       MIN(country_name),
+		       COUNT(*) FILTER (WHERE is_headquarter),
+		       COUNT(*) FILTER (WHERE NOT is_headquarter)
+		FROM swift_codes
+		GROUP BY country_iso2
+		ORDER BY country_iso2
+	`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var countries []model.CountrySummary
 	for rows.Next() {
-		var sc model.SwiftCode
-		if err := rows.Scan(&sc.SwiftCode, &sc.BankName, &sc.Address, &sc.CountryISO2, &sc.CountryName, &sc.IsHeadquarter); err != nil {
+		var c model.CountrySummary
+		if err := rows.Scan(&c.CountryISO2, &c.CountryName, &c.Headquarters, &c.Branches); err != nil {
 			return nil, err
 		}
-		codes = append(codes, sc)
+		countries = append(countries, c)
+	}
+	return countries, rows.Err()
+}
+
+// SearchSwiftCodes szuka frazy (bez rozróżniania wielkości liter) w kodzie SWIFT
+// i nazwie banku.
+func SearchSwiftCodes(db *sql.DB, phrase string, limit int) ([]model.SwiftCode, error) {
+	query := `
+		SELECT ` + swiftCodeColumns + `
+		FROM swift_codes
+		WHERE swift_code ILIKE $1 OR bank_name ILIKE $1
+		ORDER BY swift_code
+		LIMIT $2
+	`
+	rows, err := db.Query(query, containsPattern(phrase), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSwiftCodes(rows)
+}
+
+// containsPattern zamienia frazę na wzorzec LIKE dopasowujący ją w dowolnym
+// miejscu tekstu; znaki specjalne LIKE są traktowane dosłownie.
+func containsPattern(phrase string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(phrase) + "%"
+}
+
+// filterClause buduje warunek WHERE (bez słowa kluczowego) i jego argumenty
+// dla filtra; pusty filtr daje warunek zawsze prawdziwy.
+func filterClause(f model.SwiftCodeFilter) (string, []any) {
+	conds := []string{"TRUE"}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.CountryISO2 != "" {
+		add("country_iso2 = $%d", strings.ToUpper(f.CountryISO2))
+	}
+	if f.BIC8 != "" {
+		add("LEFT("+normalizedCode+", 8) = $%d", BIC8(f.BIC8))
 	}
-	return codes, nil
+	if f.IsHeadquarter != nil {
+		add("is_headquarter = $%d", *f.IsHeadquarter)
+	}
+	if f.Phrase != "" {
+		add("(swift_code ILIKE $%[1]d OR bank_name ILIKE $%[1]d)", containsPattern(f.Phrase))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// ListSwiftCodes zwraca co najwyżej limit kodów spełniających filtr,
+// posortowanych po kodzie i następujących po kodzie after (stronicowanie
+// kluczem; pusty after oznacza pierwszą stronę).
+func ListSwiftCodes(db *sql.DB, f model.SwiftCodeFilter, after string, limit int) ([]model.SwiftCode, error) {
+	where, args := filterClause(f)
+	args = append(args, after, limit)
+	query := fmt.Sprintf(`
+		SELECT `+swiftCodeColumns+`
+		FROM swift_codes
+		WHERE %s AND swift_code > $%d
+		ORDER BY swift_code
+		LIMIT $%d
+	`, where, len(args)-1, len(args))
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSwiftCodes(rows)
+}
+
+func CountSwiftCodes(db *sql.DB, f model.SwiftCodeFilter) (int, error) {
+	where, args := filterClause(f)
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM swift_codes WHERE `+where, args...).Scan(&n)
+	return n, err
 }
 
+// ListSwiftCodesByCountries działa jak ListSwiftCodes osobno dla każdego
+// z krajów iso2s (pole CountryISO2 filtra jest pomijane), ale jednym
+// zapytaniem. Wynik jest pogrupowany po kodzie kraju.
+func ListSwiftCodesByCountries(db *sql.DB, f model.SwiftCodeFilter, iso2s []string, after string, limit int) (map[string][]model.SwiftCode, error) {
+	f.CountryISO2 = ""
+	where, args := filterClause(f)
+	args = append(args, pq.Array(iso2s), after, limit)
+	query := fmt.Sprintf(`
+		SELECT `+swiftCodeColumns+`
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY country_iso2 ORDER BY swift_code) AS page_row
+			FROM swift_codes
+			WHERE %s AND country_iso2 = ANY($%d) AND swift_code > $%d
+		) page
+		WHERE page_row <= $%d
+		ORDER BY country_iso2, swift_code
+	`, where, len(args)-2, len(args)-1, len(args))
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	records, err := scanSwiftCodes(rows)
+	if err != nil {
+		return nil, err
+	}
+	byCountry := map[string][]model.SwiftCode{}
+	for _, sc := range records {
+		byCountry[sc.CountryISO2] = append(byCountry[sc.CountryISO2], sc)
+	}
+	return byCountry, nil
+}
+
+// CountSwiftCodesByCountries działa jak CountSwiftCodes osobno dla każdego
+// z krajów iso2s, ale jednym zapytaniem. Kraje bez kodów nie występują
+// w wyniku.
+func CountSwiftCodesByCountries(db *sql.DB, f model.SwiftCodeFilter, iso2s []string) (map[string]int, error) {
+	f.CountryISO2 = ""
+	where, args := filterClause(f)
+	args = append(args, pq.Array(iso2s))
+	query := fmt.Sprintf(`
+		SELECT country_iso2, COUNT(*)
+		FROM swift_codes
+		WHERE %s AND country_iso2 = ANY($%d)
+		GROUP BY country_iso2
+	`, where, len(args))
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var iso2 string
+		var n int
+		if err := rows.Scan(&iso2, &n); err != nil {
+			return nil, err
+		}
+		counts[iso2] = n
+	}
+	return counts, rows.Err()
+}
+
+// StreamSwiftCodes przekazuje wszystkie rekordy, posortowane po kodzie, do fn.
+// Rekordy są pobierane kursorem po stronie serwera w porcjach po batchSize,
+// więc cały katalog nigdy nie jest trzymany w pamięci. Transakcja tylko do
+// odczytu z REPEATABLE READ daje spójny obraz danych na czas eksportu.
+func StreamSwiftCodes(ctx context.Context, db *sql.DB, batchSize int, fn func(model.SwiftCode) error) error {
+	return streamTable(ctx, db, "swift_codes", batchSize, fn)
+}
+
+// streamTable działa jak StreamSwiftCodes dla dowolnej tabeli z rekordami,
+// np. nieaktywnej wersji danych.
+func streamTable(ctx context.Context, db *sql.DB, table string, batchSize int, fn func(model.SwiftCode) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	declare := `
+		DECLARE export_cursor NO SCROLL CURSOR FOR
+		SELECT ` + swiftCodeColumns + `
+		FROM ` + table + `
+		ORDER BY swift_code
+	`
+	if _, err := tx.ExecContext(ctx, declare); err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM export_cursor", batchSize)
+	for {
+		rows, err := tx.QueryContext(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		batch, err := scanSwiftCodes(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		for _, sc := range batch {
+			if err := fn(sc); err != nil {
+				return err
+			}
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+// InsertSwiftCode dodaje nowy rekord. Jeśli kod już istnieje (także
+// w starszej postaci zapisu), zwraca ErrDuplicate i niczego nie nadpisuje.
 func InsertSwiftCode(db *sql.DB, sc model.SwiftCode) error {
+	sc.Version = 0
+	return SaveSwiftCodes(db, []model.SwiftCode{sc})
+}
+
+// SaveSwiftCodes zapisuje wszystkie rekordy w jednej transakcji albo żaden.
+// Rekord z zerową wersją jest wstawiany jako nowy (ErrDuplicate, jeśli kod
+// już istnieje), a rekord z wersją nadpisuje wiersz tylko wtedy, gdy ten ma
+// nadal tę wersję (ErrConflict w przeciwnym razie). Błąd zapisu jest
+// zwracany jako *RecordError z indeksem rekordu.
+func SaveSwiftCodes(db *sql.DB, records []model.SwiftCode) error {
+	return withChanges(db, func(tx *sql.Tx) ([]model.Change, error) {
+		changes := make([]model.Change, len(records))
+		for i, sc := range records {
+			change, err := saveSwiftCode(tx, sc)
+			if err != nil {
+				return nil, &RecordError{Index: i, SwiftCode: sc.SwiftCode, Err: err}
+			}
+			changes[i] = change
+		}
+		return changes, nil
+	})
+}
+
+func saveSwiftCode(q Querier, sc model.SwiftCode) (model.Change, error) {
+	if sc.Version != 0 {
+		updated, err := updateVersion(q, sc, sc.Version)
+		return newChange(model.ChangeUpdated, updated), constraintViolation(err)
+	}
 	query := `
 		INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (swift_code) DO UPDATE 
-		SET bank_name = EXCLUDED.bank_name,
-		    address = EXCLUDED.address,
-		    country_iso2 = EXCLUDED.country_iso2,
-		    country_name = EXCLUDED.country_name,
-		    is_headquarter = EXCLUDED.is_headquarter
-	`
-	_, err := db.Exec(query, sc.SwiftCode, sc.BankName, sc.Address, sc.CountryISO2, sc.CountryName, sc.IsHeadquarter)
-	return err
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE NOT EXISTS (SELECT 1 FROM swift_codes WHERE ` + normalizedCode + ` = $7)
+		RETURNING ` + swiftCodeColumns
+	inserted, err := scanSwiftCode(q.QueryRow(query, sc.SwiftCode, sc.BankName, sc.Address, sc.CountryISO2, sc.CountryName, sc.IsHeadquarter, bic.Normalize(sc.SwiftCode)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Change{}, ErrDuplicate
+	}
+	if err != nil {
+		return model.Change{}, constraintViolation(err)
+	}
+	return newChange(model.ChangeCreated, inserted), nil
 }
 
 func DeleteSwiftCode(db *sql.DB, code string) error {
-	query := `DELETE FROM swift_codes WHERE swift_code = $1`
-	_, err := db.Exec(query, code)
+	return withChanges(db, func(tx *sql.Tx) ([]model.Change, error) {
+		rows, err := tx.Query(`DELETE FROM swift_codes WHERE `+normalizedCode+` = $1 RETURNING `+swiftCodeColumns, code)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		deleted, err := scanSwiftCodes(rows)
+		return newChanges(model.ChangeDeleted, deleted), err
+	})
+}
+
+// UpdateSwiftCode nadpisuje istniejący rekord tylko wtedy, gdy jego wersja
+// nadal równa się expectedVersion; w przeciwnym razie zwraca ErrConflict.
+// Kod zapisany w starszej postaci jest przy tym normalizowany.
+func UpdateSwiftCode(db *sql.DB, sc model.SwiftCode, expectedVersion int64) error {
+	return withChanges(db, func(tx *sql.Tx) ([]model.Change, error) {
+		updated, err := updateVersion(tx, sc, expectedVersion)
+		if err != nil {
+			return nil, err
+		}
+		return []model.Change{newChange(model.ChangeUpdated, updated)}, nil
+	})
+}
+
+func updateVersion(q Querier, sc model.SwiftCode, expectedVersion int64) (model.SwiftCode, error) {
+	query := `
+		UPDATE swift_codes
+		SET swift_code = $1,
+		    bank_name = $2,
+		    address = $3,
+		    country_iso2 = $4,
+		    country_name = $5,
+		    is_headquarter = $6,
+		    version = version + 1,
+		    updated_at = now()
+		WHERE ` + normalizedCode + ` = $1 AND version = $7
+		RETURNING ` + swiftCodeColumns + `
+	`
+	updated, err := scanSwiftCode(q.QueryRow(query, sc.SwiftCode, sc.BankName, sc.Address, sc.CountryISO2, sc.CountryName, sc.IsHeadquarter, expectedVersion))
+	if errors.Is(err, sql.ErrNoRows) {
+		return updated, ErrConflict
+	}
+	return updated, err
+}
+
+func DeleteSwiftCodeVersion(db *sql.DB, code string, expectedVersion int64) error {
+	return withChanges(db, func(tx *sql.Tx) ([]model.Change, error) {
+		deleted, err := deleteVersion(tx, code, expectedVersion)
+		if err != nil {
+			return nil, err
+		}
+		return []model.Change{newChange(model.ChangeDeleted, deleted)}, nil
+	})
+}
+
+// DeleteHeadquarterCascade usuwa centralę (przy zgodnej wersji) razem ze
+// wszystkimi jej oddziałami w jednej transakcji. Zwraca liczbę usuniętych
+// oddziałów.
+func DeleteHeadquarterCascade(db *sql.DB, code string, expectedVersion int64) (int64, error) {
+	var deleted int64
+	err := withChanges(db, func(tx *sql.Tx) ([]model.Change, error) {
+		hq, err := deleteVersion(tx, code, expectedVersion)
+		if err != nil {
+			return nil, err
+		}
+		code := bic.Normalize(hq.SwiftCode)
+		rows, err := tx.Query(`DELETE FROM swift_codes WHERE `+linkedBranches+` RETURNING `+swiftCodeColumns, code, BIC8(code))
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		branches, err := scanSwiftCodes(rows)
+		if err != nil {
+			return nil, err
+		}
+		deleted = int64(len(branches))
+		return newChanges(model.ChangeDeleted, append([]model.SwiftCode{hq}, branches...)), nil
+	})
+	return deleted, err
+}
+
+// ReparentBranches usuwa centralę (przy zgodnej wersji) i wiąże jej oddziały
+// z centralą newHeadquarter. Kody i pozostałe dane oddziałów się nie
+// zmieniają, bo kod SWIFT jest nadawany zewnętrznie. Zwraca kody
+// przeniesionych oddziałów albo sql.ErrNoRows, jeśli nowej centrali nie ma.
+func ReparentBranches(db *sql.DB, code string, expectedVersion int64, newHeadquarter string) ([]string, error) {
+	var moved []string
+	err := withChanges(db, func(tx *sql.Tx) ([]model.Change, error) {
+		var target string
+		err := tx.QueryRow(`SELECT swift_code FROM swift_codes WHERE `+normalizedCode+` = $1 AND is_headquarter FOR SHARE`, newHeadquarter).Scan(&target)
+		if err != nil {
+			return nil, err
+		}
+		hq, err := deleteVersion(tx, code, expectedVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		query := `
+			UPDATE swift_codes
+			SET headquarter_code = $3,
+			    version = version + 1,
+			    updated_at = now()
+			WHERE ` + linkedBranches + `
+			RETURNING ` + swiftCodeColumns + `
+		`
+		code := bic.Normalize(hq.SwiftCode)
+		rows, err := tx.Query(query, code, BIC8(code), bic.Normalize(target))
+		if err != nil {
+			return nil, err
+		}
+		branches, err := scanSwiftCodes(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, sc := range branches {
+			moved = append(moved, sc.SwiftCode)
+		}
+		changes := []model.Change{newChange(model.ChangeDeleted, hq)}
+		return append(changes, newChanges(model.ChangeUpdated, branches)...), nil
+	})
+	return moved, err
+}
+
+// deleteVersion usuwa rekord o wersji expectedVersion i zwraca jego stan
+// sprzed usunięcia albo ErrConflict.
+func deleteVersion(q Querier, code string, expectedVersion int64) (model.SwiftCode, error) {
+	deleted, err := scanSwiftCode(q.QueryRow(`DELETE FROM swift_codes WHERE `+normalizedCode+` = $1 AND version = $2 RETURNING `+swiftCodeColumns, code, expectedVersion))
+	if errors.Is(err, sql.ErrNoRows) {
+		return deleted, ErrConflict
+	}
+	return deleted, err
+}
+
+// uniqueViolation zamienia naruszenie klucza głównego na ErrDuplicate.
+func uniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicate
+	}
 	return err
 }
 
+// constraintViolation działa jak uniqueViolation, a dane odrzucone przez
+// bazę z innego powodu (błędy klas 22 i 23, np. zbyt długa wartość) zamienia
+// na ErrInvalid z komunikatem bazy.
+func constraintViolation(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch class := pqErr.Code.Class(); {
+	case pqErr.Code == "23505":
+		return ErrDuplicate
+	case class == "22" || class == "23":
+		return fmt.Errorf("%w: %s", ErrInvalid, pqErr.Message)
+	}
+	return err
+}
 
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"os"
 	"testing"
 
@@ -169,3 +170,120 @@ func TestDeleteSwiftCode(t *testing.T) {
 		t.Error("Oczekiwano błędu przy pobieraniu usuniętego rekordu, ale błąd nie wystąpił")
 	}
 }
+
+func TestSaveSwiftCodes(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+	clearTable(db, t)
+
+	record := model.SwiftCode{
+		BankName:      "Upsert Bank",
+		Address:       "Upsert Address",
+		CountryISO2:   "UP",
+		CountryName:   "Upsertland",
+		IsHeadquarter: true,
+		SwiftCode:     "UPSERTSWIFTXXX",
+	}
+
+	if err := InsertSwiftCode(db, record); err != nil {
+		t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+	}
+	record.Address = "Nadpisany adres"
+	if err := InsertSwiftCode(db, record); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("Ponowne dodanie kodu - oczekiwano ErrDuplicate, otrzymano %v", err)
+	}
+
+	record.Version = 1
+	if err := SaveSwiftCodes(db, []model.SwiftCode{record}); err != nil {
+		t.Fatalf("SaveSwiftCodes nie powiodło się: %v", err)
+	}
+	if err := SaveSwiftCodes(db, []model.SwiftCode{record}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Zapis nieaktualnej wersji - oczekiwano ErrConflict, otrzymano %v", err)
+	}
+
+	retrieved, err := GetSwiftCode(db, record.SwiftCode)
+	if err != nil {
+		t.Fatalf("GetSwiftCode nie powiodło się: %v", err)
+	}
+	if retrieved.Version != 2 || retrieved.Address != "Nadpisany adres" {
+		t.Errorf("Oczekiwano wersji 2 z nowym adresem, otrzymano %+v", retrieved)
+	}
+
+	added := model.SwiftCode{BankName: "Nowy Bank", Address: "Adres", CountryISO2: "UP", CountryName: "Upsertland", IsHeadquarter: true, SwiftCode: "NEWBUPPWXXX"}
+	err = SaveSwiftCodes(db, []model.SwiftCode{added, added})
+	var recordErr *RecordError
+	if !errors.As(err, &recordErr) || recordErr.Index != 1 || !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("Powtórzony kod - oczekiwano RecordError z indeksem 1 i ErrDuplicate, otrzymano %v", err)
+	}
+	if _, err := GetSwiftCode(db, added.SwiftCode); err == nil {
+		t.Error("Powtórzony kod - oczekiwano wycofania całej transakcji")
+	}
+}
+
+func TestMutationsRecordChanges(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+	clearTable(db, t)
+
+	for _, sc := range []model.SwiftCode{
+		{BankName: "OLD BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "OLDBPLPWXXX"},
+		{BankName: "OLD BANK", Address: "KRK", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "OLDBPLPWKRK"},
+		{BankName: "NEW BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "NEWBPLPWXXX"},
+	} {
+		if err := InsertSwiftCode(db, sc); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+	start, err := LatestChangeSequence(db)
+	if err != nil {
+		t.Fatalf("LatestChangeSequence nie powiodło się: %v", err)
+	}
+
+	// Nieudany zapis porcji nie może zostawić w dzienniku żadnej zmiany.
+	err = SaveSwiftCodes(db, []model.SwiftCode{
+		{BankName: "NEW BANK", Address: "WAW", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "NEWBPLPWWAW"},
+		{BankName: "NEW BANK", Address: "X", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "KODDLUZSZYNIZDWADZIESCIA"},
+	})
+	if err == nil {
+		t.Fatal("Oczekiwano błędu zapisu zbyt długiego kodu")
+	}
+
+	hq, err := GetSwiftCode(db, "OLDBPLPWXXX")
+	if err != nil {
+		t.Fatalf("GetSwiftCode nie powiodło się: %v", err)
+	}
+	if _, err := ReparentBranches(db, hq.SwiftCode, hq.Version, "MISSPLPWXXX"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Przeniesienie do nieistniejącej centrali - oczekiwano sql.ErrNoRows, otrzymano %v", err)
+	}
+	moved, err := ReparentBranches(db, hq.SwiftCode, hq.Version, "NEWBPLPWXXX")
+	if err != nil {
+		t.Fatalf("ReparentBranches nie powiodło się: %v", err)
+	}
+	if len(moved) != 1 || moved[0] != "OLDBPLPWKRK" {
+		t.Errorf("Oczekiwano przeniesienia oddziału OLDBPLPWKRK bez zmiany kodu, otrzymano %v", moved)
+	}
+	branches, err := GetBranchesByHeadquarter(db, "NEWBPLPWXXX")
+	if err != nil || len(branches) != 1 || branches[0].SwiftCode != "OLDBPLPWKRK" {
+		t.Errorf("Oczekiwano oddziału OLDBPLPWKRK w nowej centrali, otrzymano %+v (%v)", branches, err)
+	}
+
+	changes, err := ListChanges(db, start, 10)
+	if err != nil {
+		t.Fatalf("ListChanges nie powiodło się: %v", err)
+	}
+	want := []struct{ typ, code string }{
+		{model.ChangeDeleted, "OLDBPLPWXXX"},
+		{model.ChangeUpdated, "OLDBPLPWKRK"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Oczekiwano %d zmian, otrzymano %+v", len(want), changes)
+	}
+	for i, c := range changes {
+		if c.Type != want[i].typ || c.SwiftCode != want[i].code || c.Sequence != start+int64(i)+1 {
+			t.Errorf("Zmiana %d - oczekiwano %s %s, otrzymano %+v", i, want[i].typ, want[i].code, c)
+		}
+	}
+	if r := changes[1].Record; r.BankName != "OLD BANK" || r.Headquarter == nil || r.Headquarter.SwiftCode != "NEWBPLPWXXX" {
+		t.Errorf("Przeniesiony oddział powinien zachować dane i wskazywać nową centralę, otrzymano %+v", r)
+	}
+}
@@ -0,0 +1,485 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"swift-codes/internal/integrity"
+	"swift-codes/internal/model"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrSnapshotExists = errors.New("wersja danych o tej nazwie już istnieje")
+	ErrSnapshotState  = errors.New("operacja niedozwolona w obecnym stanie wersji danych")
+	ErrSnapshotEdits  = errors.New("aktywna wersja danych ma zmiany wprowadzone po jej aktywacji")
+)
+
+// snapshotLock to klucz blokady doradczej, która szereguje aktywację
+// i usuwanie wersji danych.
+const snapshotLock = 4403
+
+// Tabela swift_codes zawsze zawiera aktywną wersję, więc odczyty nie wiedzą
+// nic o wersjach. Pozostałe wersje leżą w tabelach swift_codes_snapshot_<id>
+// o tej samej strukturze; aktywacja zamienia tabele nazwami w jednej
+// transakcji.
+const snapshotColumns = `id, name, source, status, records, problems, integrity, created_at, activated_at`
+
+// snapshotSelect odczytuje wersje z bieżącą liczbą rekordów aktywnej wersji,
+// która zmienia się przy zwykłych zapisach przez API.
+const snapshotSelect = `
+	SELECT id, name, source, status,
+	       CASE WHEN status = 'active' THEN (SELECT COUNT(*) FROM swift_codes)::int ELSE records END,
+	       problems, integrity, created_at, activated_at
+	FROM dataset_snapshots
+`
+
+func scanSnapshot(row scanner) (model.Snapshot, error) {
+	var s model.Snapshot
+	var problems, report string
+	var activatedAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.Name, &s.Source, &s.Status, &s.Records, &problems, &report, &s.CreatedAt, &activatedAt); err != nil {
+		return s, err
+	}
+	if activatedAt.Valid {
+		s.ActivatedAt = &activatedAt.Time
+	}
+	if err := json.Unmarshal([]byte(problems), &s.Problems); err != nil {
+		return s, fmt.Errorf("błąd odczytu problemów wersji %s: %w", s.Name, err)
+	}
+	if report != "" {
+		s.Integrity = &model.IntegrityReport{}
+		if err := json.Unmarshal([]byte(report), s.Integrity); err != nil {
+			return s, fmt.Errorf("błąd odczytu raportu wersji %s: %w", s.Name, err)
+		}
+	}
+	return s, nil
+}
+
+// snapshotTable zwraca tabelę z rekordami wersji s.
+func snapshotTable(s model.Snapshot) string {
+	if s.Status == model.SnapshotActive {
+		return "swift_codes"
+	}
+	return fmt.Sprintf("swift_codes_snapshot_%d", s.ID)
+}
+
+// CreateSnapshot tworzy pustą wersję w stanie SnapshotLoading.
+func CreateSnapshot(db *sql.DB, name, source string) (model.Snapshot, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	defer tx.Rollback()
+
+	s, err := scanSnapshot(tx.QueryRow(`
+		INSERT INTO dataset_snapshots (name, source, status)
+		VALUES ($1, $2, $3)
+		RETURNING `+snapshotColumns, name, source, model.SnapshotLoading))
+	if err != nil {
+		if errors.Is(uniqueViolation(err), ErrDuplicate) {
+			return s, ErrSnapshotExists
+		}
+		return s, err
+	}
+	if _, err := tx.Exec(`CREATE TABLE ` + snapshotTable(s) + ` (LIKE swift_codes INCLUDING ALL)`); err != nil {
+		return s, err
+	}
+	return s, tx.Commit()
+}
+
+// LoadSnapshot zapisuje rekordy do wersji w stanie SnapshotLoading.
+func LoadSnapshot(db *sql.DB, name string, records []model.SwiftCode) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	s, err := lockSnapshot(tx, name)
+	if err != nil {
+		return err
+	}
+	if s.Status != model.SnapshotLoading {
+		return ErrSnapshotState
+	}
+	stmt, err := tx.Prepare(pq.CopyIn(snapshotTable(s), "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter"))
+	if err != nil {
+		return err
+	}
+	for _, sc := range records {
+		if _, err := stmt.Exec(sc.SwiftCode, sc.BankName, sc.Address, sc.CountryISO2, sc.CountryName, sc.IsHeadquarter); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return uniqueViolation(err)
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ValidateSnapshot sprawdza spójność rekordów wersji i ustawia jej stan na
+// SnapshotReady albo SnapshotInvalid. minRatio to najmniejszy dozwolony
+// stosunek liczby rekordów wersji do liczby rekordów wersji aktywnej; chroni
+// przed aktywacją uciętego pliku.
+func ValidateSnapshot(ctx context.Context, db *sql.DB, name string, minRatio float64) (model.Snapshot, error) {
+	s, err := GetSnapshot(db, name)
+	if err != nil {
+		return s, err
+	}
+	switch s.Status {
+	case model.SnapshotLoading, model.SnapshotReady, model.SnapshotInvalid:
+	default:
+		return s, ErrSnapshotState
+	}
+
+	checker := integrity.NewChecker()
+	err = streamTable(ctx, db, snapshotTable(s), 1000, func(sc model.SwiftCode) error {
+		checker.Add(sc)
+		return nil
+	})
+	if err != nil {
+		return s, err
+	}
+	var active int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM swift_codes`).Scan(&active); err != nil {
+		return s, err
+	}
+	report := checker.Report()
+	problems := snapshotProblems(report, active, minRatio)
+	status := model.SnapshotReady
+	if len(problems) > 0 {
+		status = model.SnapshotInvalid
+	}
+
+	problemsJSON, err := json.Marshal(problems)
+	if err != nil {
+		return s, err
+	}
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return s, err
+	}
+	res, err := db.ExecContext(ctx, `
+		UPDATE dataset_snapshots
+		SET status = $2, records = $3, problems = $4, integrity = $5
+		WHERE id = $1 AND status = $6
+	`, s.ID, status, report.Checked, string(problemsJSON), string(reportJSON), s.Status)
+	if err != nil {
+		return s, err
+	}
+	if err := requireAffected(res); err != nil {
+		return s, err
+	}
+	s.Status, s.Records, s.Problems, s.Integrity = status, report.Checked, problems, &report
+	return s, nil
+}
+
+// snapshotProblems zwraca błędy, przez które wersji nie można aktywować.
+// Oddziały bez centrali występują w danych źródłowych, więc są tylko
+// odnotowane w raporcie.
+func snapshotProblems(report model.IntegrityReport, activeRecords int, minRatio float64) []string {
+	problems := []string{}
+	if report.Checked == 0 {
+		problems = append(problems, "wersja nie zawiera rekordów")
+	}
+	if n := len(report.Duplicates); n > 0 {
+		problems = append(problems, fmt.Sprintf("%d kodów powtarza się po normalizacji", n))
+	}
+	if n := len(report.CountryMismatches); n > 0 {
+		problems = append(problems, fmt.Sprintf("%d oddziałów ma inny kraj niż centrala", n))
+	}
+	if report.Checked > 0 && activeRecords > 0 && float64(report.Checked) < minRatio*float64(activeRecords) {
+		problems = append(problems, fmt.Sprintf("wersja ma %d rekordów, mniej niż %.0f%% z %d rekordów wersji aktywnej",
+			report.Checked, minRatio*100, activeRecords))
+	}
+	return problems
+}
+
+func GetSnapshot(db Querier, name string) (model.Snapshot, error) {
+	return scanSnapshot(db.QueryRow(snapshotSelect+`WHERE name = $1`, name))
+}
+
+// GetActiveSnapshot zwraca wersję, z której obecnie czytane są rekordy.
+func GetActiveSnapshot(db Querier) (model.Snapshot, error) {
+	return scanSnapshot(db.QueryRow(snapshotSelect + `WHERE status = 'active'`))
+}
+
+func ListSnapshots(db *sql.DB) ([]model.Snapshot, error) {
+	rows, err := db.Query(snapshotSelect + `ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []model.Snapshot{}
+	for rows.Next() {
+		s, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// lockSnapshot odczytuje wersję i blokuje jej wiersz do końca transakcji.
+func lockSnapshot(tx *sql.Tx, name string) (model.Snapshot, error) {
+	return scanSnapshot(tx.QueryRow(`SELECT `+snapshotColumns+` FROM dataset_snapshots WHERE name = $1 FOR UPDATE`, name))
+}
+
+// DiffSnapshots porównuje rekordy wersji from i to.
+func DiffSnapshots(db *sql.DB, from, to string) (model.SnapshotDiff, error) {
+	diff := model.SnapshotDiff{From: from, To: to}
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return diff, err
+	}
+	defer tx.Rollback()
+
+	var tables [2]string
+	for i, name := range []string{from, to} {
+		s, err := GetSnapshot(tx, name)
+		if err != nil {
+			return diff, err
+		}
+		if s.Status == model.SnapshotLoading {
+			return diff, ErrSnapshotState
+		}
+		tables[i] = snapshotTable(s)
+	}
+	added, removed, changed, err := diffTables(tx, tables[0], tables[1])
+	if err != nil {
+		return diff, err
+	}
+	diff.Added, diff.Removed, diff.Changed = added, removed, changed
+	return diff, nil
+}
+
+// columnsOf zwraca swiftCodeColumns poprzedzone aliasem tabeli.
+func columnsOf(alias string) string {
+	columns := strings.Split(swiftCodeColumns, ", ")
+	for i, c := range columns {
+		columns[i] = alias + "." + c
+	}
+	return strings.Join(columns, ", ")
+}
+
+// sameRecord porównuje treść rekordów f i t (z powiązaniem z centralą) bez
+// wersji i czasu zmiany.
+const sameRecord = `(f.bank_name, f.address, f.country_iso2, f.country_name, f.is_headquarter, f.headquarter_code)
+	IS NOT DISTINCT FROM (t.bank_name, t.address, t.country_iso2, t.country_name, t.is_headquarter, t.headquarter_code)`
+
+// diffTables zwraca rekordy tabeli to, których nie ma w from, rekordy from,
+// których nie ma w to, oraz rekordy obecne w obu o różnej treści.
+func diffTables(q Querier, from, to string) (added, removed []model.SwiftCode, changed []model.SwiftCodeChange, err error) {
+	missing := func(src, other string) ([]model.SwiftCode, error) {
+		rows, err := q.Query(`
+			SELECT ` + swiftCodeColumns + ` FROM ` + src + ` s
+			WHERE NOT EXISTS (SELECT 1 FROM ` + other + ` o WHERE o.swift_code = s.swift_code)
+			ORDER BY swift_code
+		`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		codes, err := scanSwiftCodes(rows)
+		if codes == nil {
+			codes = []model.SwiftCode{}
+		}
+		return codes, err
+	}
+	if added, err = missing(to, from); err != nil {
+		return
+	}
+	if removed, err = missing(from, to); err != nil {
+		return
+	}
+
+	rows, err := q.Query(`
+		SELECT ` + columnsOf("f") + `, ` + columnsOf("t") + `
+		FROM ` + from + ` f JOIN ` + to + ` t ON t.swift_code = f.swift_code
+		WHERE NOT ` + sameRecord + `
+		ORDER BY f.swift_code
+	`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	changed = []model.SwiftCodeChange{}
+	for rows.Next() {
+		var c model.SwiftCodeChange
+		b, a := &c.Before, &c.After
+		var bHQ, aHQ sql.NullString
+		if err = rows.Scan(
+			&b.SwiftCode, &b.BankName, &b.Address, &b.CountryISO2, &b.CountryName, &b.IsHeadquarter, &b.Version, &b.UpdatedAt, &bHQ,
+			&a.SwiftCode, &a.BankName, &a.Address, &a.CountryISO2, &a.CountryName, &a.IsHeadquarter, &a.Version, &a.UpdatedAt, &aHQ,
+		); err != nil {
+			return
+		}
+		b.HeadquarterCode, a.HeadquarterCode = bHQ.String, aHQ.String
+		changed = append(changed, c)
+	}
+	err = rows.Err()
+	return
+}
+
+// ActivateSnapshot czyni wersję name aktywną. Poprzednio aktywna wersja staje
+// się nieaktywna i można do niej wrócić kolejną aktywacją. Rekordy
+// o niezmienionej treści zachowują wersję i czas zmiany, więc ich ETagi
+// pozostają ważne; różnice trafiają do dziennika zmian.
+//
+// Zapisy przez API trafiają zawsze do wersji aktywnej, a wersja name
+// pochodzi z pliku, więc nie zawiera zmian wprowadzonych po aktywacji
+// bieżącej wersji. Jeśli takie zmiany są w dzienniku, aktywacja zwraca
+// ErrSnapshotEdits, chyba że force pozwala je porzucić; zostają wtedy
+// w poprzedniej wersji.
+func ActivateSnapshot(db *sql.DB, name string, force bool) (model.SnapshotActivation, error) {
+	var activation model.SnapshotActivation
+	tx, err := db.Begin()
+	if err != nil {
+		return activation, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, snapshotLock); err != nil {
+		return activation, err
+	}
+	target, err := lockSnapshot(tx, name)
+	if err != nil {
+		return activation, err
+	}
+	if target.Status != model.SnapshotReady && target.Status != model.SnapshotInactive {
+		return activation, ErrSnapshotState
+	}
+	previous, err := scanSnapshot(tx.QueryRow(`SELECT ` + snapshotColumns + ` FROM dataset_snapshots WHERE status = 'active' FOR UPDATE`))
+	if err != nil {
+		return activation, err
+	}
+	previous.Status = model.SnapshotInactive
+	previousTable := snapshotTable(previous)
+
+	if _, err := tx.Exec(`ALTER TABLE swift_codes RENAME TO ` + previousTable); err != nil {
+		return activation, err
+	}
+	if _, err := tx.Exec(`ALTER TABLE ` + snapshotTable(target) + ` RENAME TO swift_codes`); err != nil {
+		return activation, err
+	}
+	// Zmiana nazwy tabeli czeka na trwające zapisy i blokuje kolejne, więc
+	// dziennik zawiera już wszystkie zmiany poprzedniej wersji.
+	if !force {
+		var edits int
+		if err := tx.QueryRow(`
+			SELECT COUNT(*) FROM swift_code_changes
+			WHERE seq > (SELECT COALESCE(activated_seq, 0) FROM dataset_snapshots WHERE id = $1)
+		`, previous.ID).Scan(&edits); err != nil {
+			return activation, err
+		}
+		if edits > 0 {
+			return activation, fmt.Errorf("%w (liczba zmian: %d)", ErrSnapshotEdits, edits)
+		}
+	}
+	versions := []string{`
+		UPDATE swift_codes t SET version = f.version, updated_at = f.updated_at
+		FROM ` + previousTable + ` f
+		WHERE f.swift_code = t.swift_code AND ` + sameRecord + `
+		  AND (t.version, t.updated_at) IS DISTINCT FROM (f.version, f.updated_at)
+	`, `
+		UPDATE swift_codes t SET version = GREATEST(t.version, f.version) + 1, updated_at = now()
+		FROM ` + previousTable + ` f
+		WHERE f.swift_code = t.swift_code AND NOT ` + sameRecord + `
+	`}
+	for _, query := range versions {
+		if _, err := tx.Exec(query); err != nil {
+			return activation, err
+		}
+	}
+
+	added, removed, changed, err := diffTables(tx, previousTable, "swift_codes")
+	if err != nil {
+		return activation, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE dataset_snapshots SET status = $2, records = (SELECT COUNT(*) FROM `+previousTable+`)
+		WHERE id = $1
+	`, previous.ID, model.SnapshotInactive); err != nil {
+		return activation, err
+	}
+	activatedAt := time.Now().UTC()
+	if err := tx.QueryRow(`
+		UPDATE dataset_snapshots SET status = $2, activated_at = $3
+		WHERE id = $1
+		RETURNING (SELECT COUNT(*) FROM swift_codes)::int
+	`, target.ID, model.SnapshotActive, activatedAt).Scan(&target.Records); err != nil {
+		return activation, err
+	}
+
+	changes := newChanges(model.ChangeCreated, added)
+	changes = append(changes, newChanges(model.ChangeDeleted, removed)...)
+	for _, c := range changed {
+		changes = append(changes, newChange(model.ChangeUpdated, c.After))
+	}
+	if err := appendChanges(tx, changes); err != nil {
+		return activation, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE dataset_snapshots SET activated_seq = (SELECT COALESCE(MAX(seq), 0) FROM swift_code_changes)
+		WHERE id = $1
+	`, target.ID); err != nil {
+		return activation, err
+	}
+	if err := tx.Commit(); err != nil {
+		return activation, err
+	}
+
+	target.Status, target.ActivatedAt = model.SnapshotActive, &activatedAt
+	return model.SnapshotActivation{
+		Active:   target,
+		Previous: previous.Name,
+		Added:    len(added),
+		Removed:  len(removed),
+		Changed:  len(changed),
+	}, nil
+}
+
+// DeleteSnapshot usuwa wersję razem z jej rekordami. Usunąć można tylko
+// wersję sprawdzoną i nieaktywną; wersja w stanie SnapshotLoading należy
+// jeszcze do trwającego importu.
+func DeleteSnapshot(db *sql.DB, name string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, snapshotLock); err != nil {
+		return err
+	}
+	s, err := lockSnapshot(tx, name)
+	if err != nil {
+		return err
+	}
+	switch s.Status {
+	case model.SnapshotReady, model.SnapshotInvalid, model.SnapshotInactive:
+	default:
+		return ErrSnapshotState
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS ` + snapshotTable(s)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM dataset_snapshots WHERE id = $1`, s.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
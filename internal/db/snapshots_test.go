@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+func TestSnapshotProblems(t *testing.T) {
+	clean := model.IntegrityReport{Checked: 900, OrphanBranches: []string{"AAAAPLPW001"}}
+	if problems := snapshotProblems(clean, 1000, 0.5); len(problems) != 0 {
+		t.Errorf("Oddziały bez centrali nie powinny blokować aktywacji, otrzymano %v", problems)
+	}
+	if problems := snapshotProblems(model.IntegrityReport{Checked: 10}, 0, 0.5); len(problems) != 0 {
+		t.Errorf("Pierwszy import do pustej bazy powinien przejść walidację, otrzymano %v", problems)
+	}
+
+	cases := map[string]model.IntegrityReport{
+		"pusta wersja": {Checked: 0},
+		"duplikaty": {Checked: 900, Duplicates: []model.DuplicateGroup{
+			{Normalized: "AAAAPLPWXXX", SwiftCodes: []string{"AAAAPLPWXXX", "aaaaplpwxxx"}},
+		}},
+		"niezgodny kraj": {Checked: 900, CountryMismatches: []model.CountryMismatch{
+			{Headquarter: "AAAAPLPWXXX", HeadquarterCountry: "PL", Branch: "AAAAPLPW001", BranchCountry: "DE"},
+		}},
+		"ucięty plik": {Checked: 400},
+	}
+	for name, report := range cases {
+		if problems := snapshotProblems(report, 1000, 0.5); len(problems) != 1 {
+			t.Errorf("%s: oczekiwano jednego problemu, otrzymano %v", name, problems)
+		}
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+	clearTable(db, t)
+
+	hq := model.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", Address: "Warszawa", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	branch := model.SwiftCode{SwiftCode: "AAAAPLPW001", BankName: "Bank A", Address: "Kraków", CountryISO2: "PL", CountryName: "POLAND"}
+	removed := model.SwiftCode{SwiftCode: "BBBBPLPWXXX", BankName: "Bank B", Address: "Gdańsk", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	for _, sc := range []model.SwiftCode{hq, branch, removed} {
+		if err := InsertSwiftCode(db, sc); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+	before, err := GetSwiftCode(db, hq.SwiftCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, err := GetActiveSnapshot(db)
+	if err != nil {
+		t.Fatalf("GetActiveSnapshot nie powiodło się: %v", err)
+	}
+
+	name := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	if _, err := CreateSnapshot(db, name, "test.csv"); err != nil {
+		t.Fatalf("CreateSnapshot nie powiodło się: %v", err)
+	}
+	defer DeleteSnapshot(db, name)
+	if _, err := CreateSnapshot(db, name, "test.csv"); !errors.Is(err, ErrSnapshotExists) {
+		t.Errorf("Oczekiwano ErrSnapshotExists, otrzymano %v", err)
+	}
+	if err := DeleteSnapshot(db, name); !errors.Is(err, ErrSnapshotState) {
+		t.Errorf("Wczytywanej wersji nie powinno dać się usunąć, otrzymano %v", err)
+	}
+	changed := branch
+	changed.Address = "Kraków, Rynek 1"
+	added := model.SwiftCode{SwiftCode: "CCCCPLPWXXX", BankName: "Bank C", Address: "Poznań", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	if err := LoadSnapshot(db, name, []model.SwiftCode{hq, changed, added}); err != nil {
+		t.Fatalf("LoadSnapshot nie powiodło się: %v", err)
+	}
+	if _, err := ActivateSnapshot(db, name, false); !errors.Is(err, ErrSnapshotState) {
+		t.Errorf("Niesprawdzonej wersji nie powinno dać się aktywować, otrzymano %v", err)
+	}
+
+	s, err := ValidateSnapshot(context.Background(), db, name, 0.5)
+	if err != nil {
+		t.Fatalf("ValidateSnapshot nie powiodło się: %v", err)
+	}
+	if s.Status != model.SnapshotReady || s.Records != 3 {
+		t.Fatalf("Oczekiwano gotowej wersji z 3 rekordami, otrzymano %s z %d (%v)", s.Status, s.Records, s.Problems)
+	}
+
+	diff, err := DiffSnapshots(db, previous.Name, name)
+	if err != nil {
+		t.Fatalf("DiffSnapshots nie powiodło się: %v", err)
+	}
+	if len(diff.Added) != 1 || len(diff.Removed) != 1 || len(diff.Changed) != 1 {
+		t.Fatalf("Oczekiwano po jednym rekordzie dodanym, usuniętym i zmienionym, otrzymano %+v", diff)
+	}
+
+	// Rekordy dodane przez API przed aktywacją nie są w nowej wersji.
+	if _, err := ActivateSnapshot(db, name, false); !errors.Is(err, ErrSnapshotEdits) {
+		t.Fatalf("Aktywacja bez force - oczekiwano ErrSnapshotEdits, otrzymano %v", err)
+	}
+	activation, err := ActivateSnapshot(db, name, true)
+	if err != nil {
+		t.Fatalf("ActivateSnapshot nie powiodło się: %v", err)
+	}
+	if activation.Previous != previous.Name || activation.Added != 1 || activation.Removed != 1 || activation.Changed != 1 {
+		t.Errorf("Nieoczekiwany wynik aktywacji: %+v", activation)
+	}
+	after, err := GetSwiftCode(db, hq.SwiftCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != before.Version || !after.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("Niezmieniony rekord powinien zachować wersję %d, otrzymano %d", before.Version, after.Version)
+	}
+	if got, err := GetSwiftCode(db, branch.SwiftCode); err != nil || got.Address != changed.Address || got.Version <= before.Version {
+		t.Errorf("Oczekiwano zmienionego rekordu z nową wersją, otrzymano %+v (%v)", got, err)
+	}
+	if _, err := GetSwiftCode(db, removed.SwiftCode); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Rekord usunięty w nowej wersji nie powinien być dostępny, otrzymano %v", err)
+	}
+	if err := DeleteSnapshot(db, name); !errors.Is(err, ErrSnapshotState) {
+		t.Errorf("Aktywnej wersji nie powinno dać się usunąć, otrzymano %v", err)
+	}
+
+	rollback, err := ActivateSnapshot(db, previous.Name, false)
+	if err != nil {
+		t.Fatalf("Powrót do poprzedniej wersji nie powiódł się: %v", err)
+	}
+	if rollback.Previous != name {
+		t.Errorf("Oczekiwano poprzedniej wersji %s, otrzymano %s", name, rollback.Previous)
+	}
+	if _, err := GetSwiftCode(db, removed.SwiftCode); err != nil {
+		t.Errorf("Po powrocie rekord %s powinien być dostępny: %v", removed.SwiftCode, err)
+	}
+	if err := DeleteSnapshot(db, name); err != nil {
+		t.Errorf("DeleteSnapshot nie powiodło się: %v", err)
+	}
+	if _, err := GetSnapshot(db, name); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Oczekiwano usunięcia wersji, otrzymano %v", err)
+	}
+}
+
+func TestValidateSnapshotRejectsEmpty(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	name := fmt.Sprintf("test-empty-%d", time.Now().UnixNano())
+	if _, err := CreateSnapshot(db, name, ""); err != nil {
+		t.Fatalf("CreateSnapshot nie powiodło się: %v", err)
+	}
+	defer DeleteSnapshot(db, name)
+
+	s, err := ValidateSnapshot(context.Background(), db, name, 0.5)
+	if err != nil {
+		t.Fatalf("ValidateSnapshot nie powiodło się: %v", err)
+	}
+	if s.Status != model.SnapshotInvalid || len(s.Problems) == 0 {
+		t.Errorf("Pusta wersja powinna być odrzucona, otrzymano %s (%v)", s.Status, s.Problems)
+	}
+	if _, err := ActivateSnapshot(db, name, false); !errors.Is(err, ErrSnapshotState) {
+		t.Errorf("Odrzuconej wersji nie powinno dać się aktywować, otrzymano %v", err)
+	}
+}
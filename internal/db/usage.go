@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+
+	"swift-codes/internal/model"
+)
+
+// AddAPIUsage dolicza żądania do dziennych statystyk klientów.
+func AddAPIUsage(db *sql.DB, usage []model.APIUsage) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO api_usage (day, client, route_class, requests, rejected)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (day, client, route_class) DO UPDATE
+		SET requests = api_usage.requests + EXCLUDED.requests,
+		    rejected = api_usage.rejected + EXCLUDED.rejected
+	`
+	for _, u := range usage {
+		if _, err := tx.Exec(query, u.Date, u.Client, u.RouteClass, u.Requests, u.Rejected); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListAPIUsage zwraca statystyki żądań z dnia day (RRRR-MM-DD) posortowane
+// po kliencie i klasie tras.
+func ListAPIUsage(db *sql.DB, day string) ([]model.APIUsage, error) {
+	query := `
+		SELECT to_char(day, 'YYYY-MM-DD'), client, route_class, requests, rejected
+		FROM api_usage
+		WHERE day = $1
+		ORDER BY client, route_class
+	`
+	rows, err := db.Query(query, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []model.APIUsage{}
+	for rows.Next() {
+		var u model.APIUsage
+		if err := rows.Scan(&u.Date, &u.Client, &u.RouteClass, &u.Requests, &u.Rejected); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+const webhookSubscriptionColumns = `id, url, country_iso2, bic8, created_at`
+
+func scanWebhookSubscription(row scanner) (model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.CountryISO2, &sub.BIC8, &sub.CreatedAt)
+	return sub, err
+}
+
+// CreateWebhookSubscription zapisuje subskrypcję i zwraca ją z nadanym
+// identyfikatorem.
+func CreateWebhookSubscription(db *sql.DB, sub model.WebhookSubscription) (model.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, country_iso2, bic8)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := db.QueryRow(query, sub.URL, sub.Secret, sub.CountryISO2, sub.BIC8).Scan(&sub.ID, &sub.CreatedAt)
+	return sub, err
+}
+
+// ListWebhookSubscriptions zwraca subskrypcje bez sekretów.
+func ListWebhookSubscriptions(db *sql.DB) ([]model.WebhookSubscription, error) {
+	rows, err := db.Query(`SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []model.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func GetWebhookSubscription(db *sql.DB, id int64) (model.WebhookSubscription, error) {
+	row := db.QueryRow(`SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id)
+	return scanWebhookSubscription(row)
+}
+
+// DeleteWebhookSubscription usuwa subskrypcję razem z jej zaległymi
+// zdarzeniami. Zwraca sql.ErrNoRows, jeśli subskrypcja nie istnieje.
+func DeleteWebhookSubscription(db *sql.DB, id int64) error {
+	res, err := db.Exec(`DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return noRows(err)
+	}
+	return nil
+}
+
+// EnqueueWebhookEvent dodaje zdarzenie do kolejki każdej subskrypcji, której
+// filtry pasują do kodu (puste filtry pasują do wszystkiego). Zdarzenie już
+// obecne w kolejce subskrypcji jest pomijane, więc ponowne przekazanie tego
+// samego zdarzenia nie dubluje doręczeń. Zwraca liczbę subskrypcji, do których
+// trafiło zdarzenie.
+func EnqueueWebhookEvent(q Querier, eventID, eventType, swiftCode, countryISO2 string, payload []byte) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, swift_code, payload)
+		SELECT id, $1, $2, $3, $4
+		FROM webhook_subscriptions
+		WHERE (country_iso2 = '' OR country_iso2 = $5)
+		  AND (bic8 = '' OR bic8 = $6)
+		ON CONFLICT DO NOTHING
+	`
+	res, err := q.Exec(query, eventID, eventType, swiftCode, string(payload), countryISO2, BIC8(swiftCode))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ClaimWebhookDeliveries pobiera do limit zdarzeń, których termin doręczenia
+// minął, i przesuwa ich termin o lease, żeby inne instancje serwera nie
+// wysłały ich w tym czasie ponownie. Zdarzenia są zwracane w kolejności
+// dodania.
+func ClaimWebhookDeliveries(db *sql.DB, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id
+		  AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE NOT dead AND next_attempt_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING d.id, d.subscription_id, s.url, s.secret, d.event_id, d.event_type, d.swift_code, d.payload, d.attempts, d.created_at
+	`
+	rows, err := db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		var d model.WebhookDelivery
+		var payload string
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.URL, &d.Secret, &d.EventID, &d.EventType, &d.SwiftCode, &payload, &d.Attempts, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, rows.Err()
+}
+
+// CompleteWebhookDelivery usuwa doręczone zdarzenie z kolejki.
+func CompleteWebhookDelivery(db *sql.DB, id int64) error {
+	_, err := db.Exec(`DELETE FROM webhook_deliveries WHERE id = $1`, id)
+	return err
+}
+
+// FailWebhookDelivery zapisuje nieudaną próbę doręczenia. Zdarzenie wraca do
+// kolejki z terminem nextAttempt albo, gdy dead, trafia na listę
+// niedoręczonych.
+func FailWebhookDelivery(db *sql.DB, id int64, lastError string, nextAttempt time.Time, dead bool) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, dead = $4
+		WHERE id = $1
+	`
+	_, err := db.Exec(query, id, lastError, nextAttempt, dead)
+	return err
+}
+
+// ListDeadWebhookDeliveries zwraca niedoręczone zdarzenia, opcjonalnie tylko
+// jednej subskrypcji (subscriptionID > 0).
+func ListDeadWebhookDeliveries(db *sql.DB, subscriptionID int64) ([]model.WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.subscription_id, s.url, d.event_id, d.event_type, d.swift_code, d.attempts, d.last_error, d.created_at
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.dead AND ($1::BIGINT = 0 OR d.subscription_id = $1)
+		ORDER BY d.id
+	`
+	rows, err := db.Query(query, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		d := model.WebhookDelivery{Dead: true}
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.URL, &d.EventID, &d.EventType, &d.SwiftCode, &d.Attempts, &d.LastError, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// RetryWebhookDelivery przywraca niedoręczone zdarzenie do kolejki z nowym
+// limitem prób. Zwraca sql.ErrNoRows, jeśli nie ma takiego zdarzenia na
+// liście niedoręczonych.
+func RetryWebhookDelivery(db *sql.DB, id int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET dead = FALSE, attempts = 0, last_error = '', next_attempt_at = now()
+		WHERE id = $1 AND dead
+	`
+	res, err := db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return noRows(err)
+	}
+	return nil
+}
+
+func noRows(err error) error {
+	if err != nil {
+		return err
+	}
+	return sql.ErrNoRows
+}
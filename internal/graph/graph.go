@@ -0,0 +1,80 @@
+// Package graph udostępnia katalog kodów SWIFT przez GraphQL. Powiązania
+// (centrala, oddziały, bank, kraj) są wczytywane zbiorczo dla całej strony
+// wyników, więc zagnieżdżone zapytania nie generują zapytania do bazy na
+// każdy element listy.
+package graph
+
+import (
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// maxDepth ogranicza zagnieżdżenie zapytań (np. bank -> oddziały -> bank ...).
+const maxDepth = 10
+
+// maxRequestBytes ogranicza treść żądania POST; zapytania nie potrzebują
+// więcej niż kilku kilobajtów.
+const maxRequestBytes = 64 << 10
+
+// NewSchema parsuje schemat i łączy go z resolverami.
+func NewSchema() *graphql.Schema {
+	return graphql.MustParseSchema(schemaSDL, &Resolver{}, graphql.MaxDepth(maxDepth))
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Handler obsługuje zapytania GraphQL wysłane metodą POST (JSON z polami
+// query, operationName i variables) albo GET (te same pola jako parametry
+// adresu, variables zakodowane w JSON). Błędy zapytania są zwracane
+// w polu errors odpowiedzi ze statusem 200.
+func Handler(dbConn *sql.DB) http.HandlerFunc {
+	schema := NewSchema()
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if r.Method == http.MethodGet {
+			q := r.URL.Query()
+			req.Query = q.Get("query")
+			req.OperationName = q.Get("operationName")
+			if v := q.Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					http.Error(w, "Błędny format parametru variables", http.StatusBadRequest)
+					return
+				}
+			}
+		} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, fmt.Sprintf("Maksymalny rozmiar żądania to %d bajtów", maxRequestBytes), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "Błędny format danych", http.StatusBadRequest)
+			return
+		}
+		if req.Query == "" {
+			http.Error(w, "Brak zapytania GraphQL", http.StatusBadRequest)
+			return
+		}
+
+		ctx := withLoaders(r.Context(), newLoaders(dbConn))
+		response := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			slog.ErrorContext(r.Context(), "Błąd podczas kodowania odpowiedzi", "error", err)
+		}
+	}
+}
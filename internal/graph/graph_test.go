@@ -0,0 +1,350 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+func TestBatch_LoadsExpectedKeysTogether(t *testing.T) {
+	var calls [][]string
+	b := newBatch(func(keys []string) (map[string]int, error) {
+		calls = append(calls, keys)
+		values := map[string]int{}
+		for _, k := range keys {
+			if k != "MISSING" {
+				values[k] = len(k)
+			}
+		}
+		return values, nil
+	})
+
+	b.expect("BB", "A", "MISSING")
+	for _, key := range []string{"A", "BB", "MISSING", "A"} {
+		if _, err := b.load(key); err != nil {
+			t.Fatalf("load(%q) zwróciło błąd: %v", key, err)
+		}
+	}
+	if v, _ := b.load("CCC"); v != 3 {
+		t.Errorf("Oczekiwano wartości 3 dla CCC, otrzymano %d", v)
+	}
+
+	want := [][]string{{"A", "BB", "MISSING"}, {"CCC"}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Oczekiwano zapytań %v, otrzymano %v", want, calls)
+	}
+}
+
+func TestBatch_PrimedValuesSkipFetch(t *testing.T) {
+	b := newBatch(func(keys []string) (map[string]int, error) {
+		t.Fatalf("Nieoczekiwane zapytanie o %v", keys)
+		return nil, nil
+	})
+	b.prime(map[string]int{"PL": 1})
+	if v, err := b.load("PL"); err != nil || v != 1 {
+		t.Errorf("Oczekiwano wartości 1, otrzymano %d (%v)", v, err)
+	}
+}
+
+func TestCountrySwiftCodes_LoadedTogether(t *testing.T) {
+	l := newLoaders(nil)
+	var pageCalls, countCalls [][]string
+	l.listCountryPages = func(f model.SwiftCodeFilter, iso2s []string, after string, limit int) (map[string][]model.SwiftCode, error) {
+		pageCalls = append(pageCalls, iso2s)
+		pages := map[string][]model.SwiftCode{}
+		for _, iso2 := range iso2s {
+			for i := 0; i < limit; i++ {
+				pages[iso2] = append(pages[iso2], model.SwiftCode{SwiftCode: fmt.Sprintf("AAAA%sPW%03d", iso2, i), CountryISO2: iso2})
+			}
+		}
+		return pages, nil
+	}
+	l.countCountryCodes = func(f model.SwiftCodeFilter, iso2s []string) (map[string]int, error) {
+		countCalls = append(countCalls, iso2s)
+		return map[string]int{"DE": 4, "PL": 7}, nil
+	}
+	l.setListed([]string{"PL", "DE", "FR"})
+	ctx := withLoaders(context.Background(), l)
+
+	for _, iso2 := range []string{"PL", "DE", "FR"} {
+		conn, err := (&countryResolver{iso2: iso2}).SwiftCodes(ctx, swiftCodesArgs{First: 2})
+		if err != nil {
+			t.Fatalf("%s: SwiftCodes zwróciło błąd: %v", iso2, err)
+		}
+		n, err := conn.TotalCount(ctx)
+		if err != nil {
+			t.Fatalf("%s: TotalCount zwróciło błąd: %v", iso2, err)
+		}
+		if len(conn.records) != 2 || !conn.hasNext || (iso2 == "PL" && n != 7) || (iso2 == "FR" && n != 0) {
+			t.Errorf("%s: nieoczekiwana strona %+v z liczbą %d", iso2, conn, n)
+		}
+	}
+	want := [][]string{{"DE", "FR", "PL"}}
+	if !reflect.DeepEqual(pageCalls, want) || !reflect.DeepEqual(countCalls, want) {
+		t.Errorf("Oczekiwano jednego zapytania o strony i jednego o liczby dla wszystkich krajów, otrzymano %v i %v", pageCalls, countCalls)
+	}
+
+	// Inne argumenty pola to osobne zapytanie, ale nadal wspólne dla krajów.
+	hq := true
+	for _, iso2 := range []string{"PL", "DE"} {
+		if _, err := (&countryResolver{iso2: iso2}).SwiftCodes(ctx, swiftCodesArgs{First: 2, Filter: &filterInput{IsHeadquarter: &hq}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(pageCalls) != 2 {
+		t.Errorf("Oczekiwano drugiego zapytania dla innego filtra, otrzymano %v", pageCalls)
+	}
+}
+
+func TestCountrySwiftCodes_NodeLimit(t *testing.T) {
+	l := newLoaders(nil)
+	l.listCountryPages = func(f model.SwiftCodeFilter, iso2s []string, after string, limit int) (map[string][]model.SwiftCode, error) {
+		return map[string][]model.SwiftCode{}, nil
+	}
+	var iso2s []string
+	for i := 0; i < 2*maxNodes/maxPageSize; i++ {
+		iso2s = append(iso2s, fmt.Sprintf("C%03d", i))
+	}
+	l.setListed(iso2s)
+	ctx := withLoaders(context.Background(), l)
+
+	var err error
+	resolved := 0
+	for _, iso2 := range iso2s {
+		if _, err = (&countryResolver{iso2: iso2}).SwiftCodes(ctx, swiftCodesArgs{First: maxPageSize}); err != nil {
+			break
+		}
+		resolved++
+	}
+	if err != errTooMany || resolved != maxNodes/maxPageSize {
+		t.Errorf("Oczekiwano errTooMany po %d krajach, otrzymano %v po %d", maxNodes/maxPageSize, err, resolved)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	key, err := decodeCursor(encodeCursor("BPKOPLPWXXX"))
+	if err != nil || key != "BPKOPLPWXXX" {
+		t.Errorf("Oczekiwano BPKOPLPWXXX, otrzymano %q (%v)", key, err)
+	}
+	bad := "!!"
+	if _, err := decodeCursor(&bad); err != errCursor {
+		t.Errorf("Oczekiwano błędu kursora, otrzymano %v", err)
+	}
+}
+
+// exec wykonuje zapytanie bez bazy danych; nadaje się tylko do zapytań
+// odrzucanych przed dostępem do bazy.
+func exec(t *testing.T, query string) []string {
+	ctx := withLoaders(context.Background(), newLoaders(nil))
+	response := NewSchema().Exec(ctx, query, "", nil)
+	var messages []string
+	for _, err := range response.Errors {
+		messages = append(messages, err.Message)
+	}
+	return messages
+}
+
+func TestInvalidArguments(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"nieprawidłowy kod", `{ swiftCode(code: "BPKO") { swiftCode } }`, errSwiftCode.Error()},
+		{"nieprawidłowy BIC8", `{ bank(bic8: "12345678") { bic8 } }`, errBIC8.Error()},
+		{"pusty kraj", `{ banks(country: " ") { totalCount } }`, errCountryArg.Error()},
+		{"za duża strona", `{ swiftCodes(first: 501) { totalCount } }`, errPageSize.Error()},
+		{"nieprawidłowy kursor", `{ swiftCodes(after: "!!") { totalCount } }`, errCursor.Error()},
+		{"nieznane pole", `{ swiftCode(code: "BPKOPLPW") { iban } }`, `Cannot query field "iban"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := exec(t, tt.query)
+			if len(messages) != 1 || !strings.Contains(messages[0], tt.want) {
+				t.Errorf("Oczekiwano błędu %q, otrzymano %v", tt.want, messages)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	query := "{ bank(bic8: \"BPKOPLPW\") " + strings.Repeat("{ branches { bank ", maxDepth/2) + "{ bic8 }" + strings.Repeat(" } }", maxDepth/2) + " }"
+	if messages := exec(t, query); len(messages) == 0 {
+		t.Error("Oczekiwano odrzucenia zbyt głęboko zagnieżdżonego zapytania")
+	}
+}
+
+func TestHandler_RejectsEmptyQuery(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Handler(nil).ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": ""}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Oczekiwano status 400, otrzymano %d", rr.Code)
+	}
+}
+
+func TestHandler_RejectsLargeBody(t *testing.T) {
+	body := `{"query": "` + strings.Repeat(" ", maxRequestBytes) + `{ countries { iso2 } }"}`
+	rr := httptest.NewRecorder()
+	Handler(nil).ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Oczekiwano status 413, otrzymano %d", rr.Code)
+	}
+}
+
+func TestHandler_Queries(t *testing.T) {
+	connStr := os.Getenv("TEST_DB_CONN")
+	if connStr == "" {
+		t.Fatal("Brak ustawionej zmiennej środowiskowej TEST_DB_CONN")
+	}
+	testDB, err := db.InitDB(connStr)
+	if err != nil {
+		t.Fatalf("InitDB nie powiodło się: %v", err)
+	}
+	defer testDB.Close()
+	if _, err := testDB.Exec("TRUNCATE TABLE swift_codes"); err != nil {
+		t.Fatalf("Nie udało się wyczyścić tabeli: %v", err)
+	}
+
+	for _, rec := range []model.SwiftCode{
+		{BankName: "ALPHA BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "ALPHPLPWXXX"},
+		{BankName: "ALPHA BANK", Address: "KRAKÓW", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "ALPHPLPWKRK"},
+		{BankName: "ALPHA BANK", Address: "WROCŁAW", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "ALPHPLPWWRO"},
+		{BankName: "BETA BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "BETAPLPWXXX"},
+		{BankName: "GAMMA BANK", Address: "HQ", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true, SwiftCode: "GAMMDEFFXXX"},
+	} {
+		if err := db.InsertSwiftCode(testDB, rec); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	handler := Handler(testDB)
+	post := func(query string, variables map[string]any, out any) {
+		body, _ := json.Marshal(request{Query: query, Variables: variables})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", bytes.NewReader(body)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Oczekiwano status 200, otrzymano %d", rr.Code)
+		}
+		var response struct {
+			Data   json.RawMessage
+			Errors []struct{ Message string }
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+		}
+		if len(response.Errors) > 0 {
+			t.Fatalf("Nieoczekiwane błędy: %+v", response.Errors)
+		}
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			t.Fatalf("Błąd dekodowania danych: %v", err)
+		}
+	}
+
+	var nested struct {
+		Country struct {
+			Name  string
+			Banks struct {
+				TotalCount int
+				Nodes      []struct {
+					BIC8        string
+					Headquarter *struct {
+						SwiftCode string
+						Branches  []struct{ SwiftCode string }
+					}
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+		}
+	}
+	post(`query($after: String) {
+		country(iso2: "pl") {
+			name
+			banks(first: 1, after: $after) {
+				totalCount
+				nodes { bic8 headquarter { swiftCode branches { swiftCode } } }
+				pageInfo { hasNextPage endCursor }
+			}
+		}
+	}`, nil, &nested)
+	banks := nested.Country.Banks
+	if nested.Country.Name != "POLAND" || banks.TotalCount != 2 || len(banks.Nodes) != 1 || !banks.PageInfo.HasNextPage {
+		t.Fatalf("Nieoczekiwana pierwsza strona banków: %+v", nested)
+	}
+	if hq := banks.Nodes[0].Headquarter; hq == nil || hq.SwiftCode != "ALPHPLPWXXX" || len(hq.Branches) != 2 {
+		t.Errorf("Oczekiwano centrali ALPHPLPWXXX z 2 oddziałami, otrzymano %+v", hq)
+	}
+
+	post(`query($after: String) {
+		country(iso2: "PL") { banks(first: 1, after: $after) { nodes { bic8 } pageInfo { hasNextPage } } }
+	}`, map[string]any{"after": banks.PageInfo.EndCursor}, &nested)
+	if nodes := nested.Country.Banks.Nodes; len(nodes) != 1 || nodes[0].BIC8 != "BETAPLPW" || nested.Country.Banks.PageInfo.HasNextPage {
+		t.Errorf("Nieoczekiwana druga strona banków: %+v", nested.Country.Banks)
+	}
+
+	var codes struct {
+		SwiftCodes struct {
+			TotalCount int
+			Nodes      []struct {
+				SwiftCode   string
+				Headquarter *struct{ SwiftCode string }
+				Bank        struct{ BankName string }
+			}
+		}
+		SwiftCode *struct {
+			SwiftCode string
+			Country   struct{ ISO2 string }
+		}
+	}
+	post(`{
+		swiftCodes(filter: {bic8: "alphplpw", isHeadquarter: false}) {
+			totalCount
+			nodes { swiftCode headquarter { swiftCode } bank { bankName } }
+		}
+		swiftCode(code: "gammdeff") { swiftCode country { iso2 } }
+	}`, nil, &codes)
+	if codes.SwiftCodes.TotalCount != 2 || len(codes.SwiftCodes.Nodes) != 2 {
+		t.Fatalf("Oczekiwano 2 oddziałów ALPHPLPW, otrzymano %+v", codes.SwiftCodes)
+	}
+	for _, n := range codes.SwiftCodes.Nodes {
+		if n.Headquarter == nil || n.Headquarter.SwiftCode != "ALPHPLPWXXX" || n.Bank.BankName != "ALPHA BANK" {
+			t.Errorf("Nieoczekiwany oddział %+v", n)
+		}
+	}
+	if codes.SwiftCode == nil || codes.SwiftCode.SwiftCode != "GAMMDEFFXXX" || codes.SwiftCode.Country.ISO2 != "DE" {
+		t.Errorf("Oczekiwano GAMMDEFFXXX z kraju DE, otrzymano %+v", codes.SwiftCode)
+	}
+
+	var listed struct {
+		Countries []struct {
+			ISO2       string
+			SwiftCodes struct {
+				TotalCount int
+				Nodes      []struct{ SwiftCode string }
+			}
+		}
+	}
+	post(`{ countries { iso2 swiftCodes(first: 2) { totalCount nodes { swiftCode } } } }`, nil, &listed)
+	if len(listed.Countries) != 2 {
+		t.Fatalf("Oczekiwano 2 krajów, otrzymano %+v", listed.Countries)
+	}
+	de, pl := listed.Countries[0].SwiftCodes, listed.Countries[1].SwiftCodes
+	if de.TotalCount != 1 || len(de.Nodes) != 1 || de.Nodes[0].SwiftCode != "GAMMDEFFXXX" {
+		t.Errorf("Nieoczekiwane kody DE: %+v", de)
+	}
+	if pl.TotalCount != 4 || len(pl.Nodes) != 2 || pl.Nodes[0].SwiftCode != "ALPHPLPWKRK" || pl.Nodes[1].SwiftCode != "ALPHPLPWWRO" {
+		t.Errorf("Nieoczekiwane kody PL: %+v", pl)
+	}
+}
@@ -0,0 +1,236 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+// batch wczytuje wartości dla wielu kluczy jednym zapytaniem. Klucze
+// zgłoszone przez expect (np. wszystkie BIC8 z bieżącej strony wyników) są
+// pobierane razem przy pierwszym load, więc elementy listy nie odpytują bazy
+// każdy osobno. Wynik jest zapamiętywany do końca żądania.
+type batch[V any] struct {
+	fetch   func(keys []string) (map[string]V, error)
+	mu      sync.Mutex
+	pending map[string]bool
+	loaded  map[string]V
+}
+
+func newBatch[V any](fetch func(keys []string) (map[string]V, error)) *batch[V] {
+	return &batch[V]{fetch: fetch, pending: map[string]bool{}, loaded: map[string]V{}}
+}
+
+func (b *batch[V]) expect(keys ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, k := range keys {
+		if _, ok := b.loaded[k]; !ok {
+			b.pending[k] = true
+		}
+	}
+}
+
+// prime zapamiętuje wartości pobrane w inny sposób.
+func (b *batch[V]) prime(values map[string]V) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for k, v := range values {
+		b.loaded[k] = v
+		delete(b.pending, k)
+	}
+}
+
+// load zwraca wartość dla klucza; brak wartości w bazie daje wartość zerową.
+func (b *batch[V]) load(key string) (V, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if v, ok := b.loaded[key]; ok {
+		return v, nil
+	}
+
+	b.pending[key] = true
+	keys := make([]string, 0, len(b.pending))
+	for k := range b.pending {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values, err := b.fetch(keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	for _, k := range keys {
+		b.loaded[k] = values[k]
+	}
+	for k, v := range values {
+		b.loaded[k] = v
+	}
+	b.pending = map[string]bool{}
+	return b.loaded[key], nil
+}
+
+// loaders to połączenie z bazą i pamięć podręczna jednego zapytania GraphQL.
+type loaders struct {
+	db *sql.DB
+	// codes: BIC8 -> wszystkie kody instytucji, posortowane po kodzie.
+	codes *batch[[]model.SwiftCode]
+	// records: kod -> rekord; służy do wczytania central oddziałów.
+	records *batch[model.SwiftCode]
+	// branches: kod centrali -> jej oddziały, także przeniesione z innych
+	// instytucji.
+	branches *batch[[]model.SwiftCode]
+	// banks: ISO2 -> banki kraju.
+	banks *batch[[]model.BankSummary]
+	// countries: ISO2 -> liczba central i oddziałów; pobierane wszystkie naraz.
+	countries *batch[model.CountrySummary]
+
+	// Strony i liczniki kodów krajów (Country.swiftCodes) są pobierane
+	// jednym zapytaniem dla wszystkich krajów z listy countries, osobno dla
+	// każdego zestawu argumentów pola.
+	listCountryPages  func(f model.SwiftCodeFilter, iso2s []string, after string, limit int) (map[string][]model.SwiftCode, error)
+	countCountryCodes func(f model.SwiftCodeFilter, iso2s []string) (map[string]int, error)
+	mu                sync.Mutex
+	listed            []string
+	pages             map[string]*batch[[]model.SwiftCode]
+	counts            map[string]*batch[int]
+
+	// nodes to liczba elementów list przydzielonych zapytaniu (zob. maxNodes).
+	nodes atomic.Int64
+}
+
+func newLoaders(dbConn *sql.DB) *loaders {
+	return &loaders{
+		db: dbConn,
+		codes: newBatch(func(bic8s []string) (map[string][]model.SwiftCode, error) {
+			records, err := db.GetSwiftCodesByBIC8s(dbConn, bic8s)
+			if err != nil {
+				return nil, err
+			}
+			byBIC8 := make(map[string][]model.SwiftCode, len(bic8s))
+			for _, sc := range records {
+				key := db.BIC8(bic.Normalize(sc.SwiftCode))
+				byBIC8[key] = append(byBIC8[key], sc)
+			}
+			return byBIC8, nil
+		}),
+		records: newBatch(func(codes []string) (map[string]model.SwiftCode, error) {
+			records, err := db.GetSwiftCodes(dbConn, codes)
+			if err != nil {
+				return nil, err
+			}
+			byCode := make(map[string]model.SwiftCode, len(records))
+			for _, sc := range records {
+				byCode[bic.Normalize(sc.SwiftCode)] = sc
+			}
+			return byCode, nil
+		}),
+		branches: newBatch(func(codes []string) (map[string][]model.SwiftCode, error) {
+			return db.GetBranchesByHeadquarters(dbConn, codes)
+		}),
+		banks: newBatch(func(iso2s []string) (map[string][]model.BankSummary, error) {
+			return db.ListBanksByCountry(dbConn, iso2s)
+		}),
+		countries: newBatch(func([]string) (map[string]model.CountrySummary, error) {
+			return countrySummaries(dbConn)
+		}),
+		listCountryPages: func(f model.SwiftCodeFilter, iso2s []string, after string, limit int) (map[string][]model.SwiftCode, error) {
+			return db.ListSwiftCodesByCountries(dbConn, f, iso2s, after, limit)
+		},
+		countCountryCodes: func(f model.SwiftCodeFilter, iso2s []string) (map[string]int, error) {
+			return db.CountSwiftCodesByCountries(dbConn, f, iso2s)
+		},
+		pages:  map[string]*batch[[]model.SwiftCode]{},
+		counts: map[string]*batch[int]{},
+	}
+}
+
+// charge dolicza n elementów do odpowiedzi i zwraca errTooMany po
+// przekroczeniu maxNodes. Strony są liczone w wielkości first jeszcze przed
+// zapytaniem do bazy, listy bez stronicowania - w faktycznej długości.
+func (l *loaders) charge(n int) error {
+	if l.nodes.Add(int64(n)) > maxNodes {
+		return errTooMany
+	}
+	return nil
+}
+
+// setListed zapamiętuje kraje zwrócone przez listę countries.
+func (l *loaders) setListed(iso2s []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listed = append(l.listed, iso2s...)
+}
+
+// filterKey opisuje filtr bez kraju; filtry o tym samym kluczu dają to samo
+// zapytanie dla każdego kraju.
+func filterKey(f model.SwiftCodeFilter) string {
+	hq := ""
+	if f.IsHeadquarter != nil {
+		hq = fmt.Sprint(*f.IsHeadquarter)
+	}
+	return fmt.Sprintf("%q %q %q", f.BIC8, hq, f.Phrase)
+}
+
+// countryPages zwraca loader stron kodów krajów (ISO2 -> co najwyżej limit
+// kodów po after) dla filtra.
+func (l *loaders) countryPages(f model.SwiftCodeFilter, after string, limit int) *batch[[]model.SwiftCode] {
+	key := fmt.Sprintf("%s %q %d", filterKey(f), after, limit)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.pages[key]
+	if b == nil {
+		b = newBatch(func(iso2s []string) (map[string][]model.SwiftCode, error) {
+			return l.listCountryPages(f, iso2s, after, limit)
+		})
+		b.expect(l.listed...)
+		l.pages[key] = b
+	}
+	return b
+}
+
+// countryCounts zwraca loader liczby kodów krajów spełniających filtr.
+func (l *loaders) countryCounts(f model.SwiftCodeFilter) *batch[int] {
+	key := filterKey(f)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.counts[key]
+	if b == nil {
+		b = newBatch(func(iso2s []string) (map[string]int, error) {
+			return l.countCountryCodes(f, iso2s)
+		})
+		b.expect(l.listed...)
+		l.counts[key] = b
+	}
+	return b
+}
+
+func countrySummaries(dbConn *sql.DB) (map[string]model.CountrySummary, error) {
+	summaries, err := db.CountSwiftCodesByCountry(dbConn)
+	if err != nil {
+		return nil, err
+	}
+	byISO2 := make(map[string]model.CountrySummary, len(summaries))
+	for _, c := range summaries {
+		byISO2[c.CountryISO2] = c
+	}
+	return byISO2, nil
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,526 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/country"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+const maxPageSize = 500
+
+// maxNodes ogranicza łączną liczbę elementów list w odpowiedzi na jedno
+// zapytanie. Bez tego zagnieżdżone strony (np. countries -> swiftCodes ->
+// branches) mnożą się i kilkanaście pól z first: 500 pobiera miliony
+// rekordów, mimo limitu głębokości.
+const maxNodes = 20000
+
+var (
+	errInternal   = errors.New("Błąd pobierania danych")
+	errPageSize   = errors.New("Parametr first musi mieć wartość od 1 do 500")
+	errTooMany    = fmt.Errorf("Zapytanie zwraca więcej niż %d elementów list; zmniejsz first albo zagnieżdżenie", maxNodes)
+	errCursor     = errors.New("Nieprawidłowy kursor")
+	errSwiftCode  = errors.New("Nieprawidłowy kod SWIFT")
+	errBIC8       = errors.New("Nieprawidłowy kod BIC8")
+	errCountryArg = errors.New("Parametr country jest wymagany")
+)
+
+// internalError zapisuje błąd bazy w logu i zwraca klientowi ogólny komunikat,
+// tak jak handlery REST.
+func internalError(ctx context.Context, err error) error {
+	slog.ErrorContext(ctx, errInternal.Error(), "error", err)
+	return errInternal
+}
+
+// pageSize sprawdza argument first; wartość domyślną (50) ustawia schemat.
+func pageSize(first int32) (int, error) {
+	if first < 1 || first > maxPageSize {
+		return 0, errPageSize
+	}
+	return int(first), nil
+}
+
+// Kursor to zakodowany klucz ostatniego elementu strony (kod SWIFT lub BIC8).
+func encodeCursor(key string) *string {
+	c := base64.RawURLEncoding.EncodeToString([]byte(key))
+	return &c
+}
+
+func decodeCursor(after *string) (string, error) {
+	if after == nil {
+		return "", nil
+	}
+	key, err := base64.RawURLEncoding.DecodeString(*after)
+	if err != nil {
+		return "", errCursor
+	}
+	return string(key), nil
+}
+
+// Resolver to korzeń schematu (typ Query). Połączenie z bazą i pamięć
+// podręczna zapytania są przekazywane w kontekście (zob. loaders).
+type Resolver struct{}
+
+type pageArgs struct {
+	First int32
+	After *string
+}
+
+type swiftCodesArgs struct {
+	Filter *filterInput
+	First  int32
+	After  *string
+}
+
+type filterInput struct {
+	Country       *string
+	BIC8          *string
+	IsHeadquarter *bool
+	Search        *string
+}
+
+func (f *filterInput) model() model.SwiftCodeFilter {
+	var filter model.SwiftCodeFilter
+	if f == nil {
+		return filter
+	}
+	if f.Country != nil {
+		filter.CountryISO2 = strings.ToUpper(strings.TrimSpace(*f.Country))
+	}
+	if f.BIC8 != nil {
+		filter.BIC8 = db.BIC8(bic.Normalize(*f.BIC8))
+	}
+	filter.IsHeadquarter = f.IsHeadquarter
+	if f.Search != nil {
+		filter.Phrase = strings.TrimSpace(*f.Search)
+	}
+	return filter
+}
+
+func (r *Resolver) SwiftCode(ctx context.Context, args struct{ Code string }) (*swiftCodeResolver, error) {
+	code := bic.Canonical(args.Code)
+	if bic.Validate(code) != nil {
+		return nil, errSwiftCode
+	}
+	codes, err := loadersFrom(ctx).codes.load(db.BIC8(code))
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	for _, sc := range codes {
+		if bic.Normalize(sc.SwiftCode) == code {
+			return &swiftCodeResolver{sc: sc}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) SwiftCodes(ctx context.Context, args swiftCodesArgs) (*swiftCodeConnection, error) {
+	return newSwiftCodeConnection(ctx, args.Filter.model(), pageArgs{First: args.First, After: args.After})
+}
+
+func newSwiftCodeConnection(ctx context.Context, filter model.SwiftCodeFilter, page pageArgs) (*swiftCodeConnection, error) {
+	limit, err := pageSize(page.First)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadersFrom(ctx).charge(limit); err != nil {
+		return nil, err
+	}
+	after, err := decodeCursor(page.After)
+	if err != nil {
+		return nil, err
+	}
+	// Jeden rekord ponad limit mówi, czy istnieje następna strona.
+	records, err := db.ListSwiftCodes(loadersFrom(ctx).db, filter, after, limit+1)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return pageOf(filter, records, limit), nil
+}
+
+func pageOf(filter model.SwiftCodeFilter, records []model.SwiftCode, limit int) *swiftCodeConnection {
+	conn := &swiftCodeConnection{filter: filter, records: records}
+	if len(records) > limit {
+		conn.records, conn.hasNext = records[:limit], true
+	}
+	return conn
+}
+
+func (r *Resolver) Country(ctx context.Context, args struct{ ISO2 string }) (*countryResolver, error) {
+	iso2 := strings.ToUpper(strings.TrimSpace(args.ISO2))
+	summary, err := loadersFrom(ctx).countries.load(iso2)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	if country.Name(iso2) == "" && summary.CountryISO2 == "" {
+		return nil, nil
+	}
+	return newCountryResolver(iso2, summary), nil
+}
+
+func (r *Resolver) Countries(ctx context.Context) ([]*countryResolver, error) {
+	l := loadersFrom(ctx)
+	summaries, err := countrySummaries(l.db)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	if err := l.charge(len(summaries)); err != nil {
+		return nil, err
+	}
+	l.countries.prime(summaries)
+
+	result := make([]*countryResolver, 0, len(summaries))
+	iso2s := make([]string, 0, len(summaries))
+	for iso2, summary := range summaries {
+		result = append(result, newCountryResolver(iso2, summary))
+		iso2s = append(iso2s, iso2)
+		l.banks.expect(iso2)
+	}
+	l.setListed(iso2s)
+	sort.Slice(result, func(i, j int) bool { return result[i].iso2 < result[j].iso2 })
+	return result, nil
+}
+
+func (r *Resolver) Bank(ctx context.Context, args struct{ BIC8 string }) (*bankResolver, error) {
+	code := bic.Normalize(args.BIC8)
+	if bic.Validate(code) != nil {
+		return nil, errBIC8
+	}
+	bic8 := db.BIC8(code)
+	codes, err := loadersFrom(ctx).codes.load(bic8)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	bank := model.NewBank(bic8, codes)
+	return &bankResolver{bic8: bic8, bankName: bank.BankName, countryISO2: bank.CountryISO2}, nil
+}
+
+func (r *Resolver) Banks(ctx context.Context, args struct {
+	Country string
+	First   int32
+	After   *string
+}) (*bankConnection, error) {
+	iso2 := strings.ToUpper(strings.TrimSpace(args.Country))
+	if iso2 == "" {
+		return nil, errCountryArg
+	}
+	return newBankConnection(ctx, iso2, pageArgs{First: args.First, After: args.After})
+}
+
+// newBankConnection stronicuje banki kraju w pamięci: lista banków jednego
+// kraju jest niewielka i i tak jest pobierana w całości (zob. loaders.banks).
+func newBankConnection(ctx context.Context, iso2 string, page pageArgs) (*bankConnection, error) {
+	limit, err := pageSize(page.First)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadersFrom(ctx).charge(limit); err != nil {
+		return nil, err
+	}
+	after, err := decodeCursor(page.After)
+	if err != nil {
+		return nil, err
+	}
+	banks, err := loadersFrom(ctx).banks.load(iso2)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+
+	start := sort.Search(len(banks), func(i int) bool { return banks[i].BIC8 > after })
+	end := min(start+limit, len(banks))
+	conn := &bankConnection{total: len(banks), hasNext: end < len(banks)}
+	for _, b := range banks[start:end] {
+		conn.nodes = append(conn.nodes, &bankResolver{bic8: b.BIC8, bankName: b.BankName, countryISO2: iso2})
+	}
+	return conn, nil
+}
+
+type pageInfo struct {
+	hasNext   bool
+	endCursor *string
+}
+
+func (p pageInfo) HasNextPage() bool  { return p.hasNext }
+func (p pageInfo) EndCursor() *string { return p.endCursor }
+
+type swiftCodeConnection struct {
+	filter  model.SwiftCodeFilter
+	records []model.SwiftCode
+	hasNext bool
+	// counts liczy kody razem z innymi krajami (zob. loaders.countryCounts).
+	counts *batch[int]
+}
+
+// TotalCount wykonuje osobne zapytanie, więc tylko wtedy, gdy klient o nie
+// prosi.
+func (c *swiftCodeConnection) TotalCount(ctx context.Context) (int32, error) {
+	if c.counts != nil {
+		n, err := c.counts.load(c.filter.CountryISO2)
+		if err != nil {
+			return 0, internalError(ctx, err)
+		}
+		return int32(n), nil
+	}
+	n, err := db.CountSwiftCodes(loadersFrom(ctx).db, c.filter)
+	if err != nil {
+		return 0, internalError(ctx, err)
+	}
+	return int32(n), nil
+}
+
+func (c *swiftCodeConnection) Nodes(ctx context.Context) []*swiftCodeResolver {
+	// Centrale, oddziały i banki wszystkich kodów strony wczytujemy razem.
+	l := loadersFrom(ctx)
+	nodes := make([]*swiftCodeResolver, len(c.records))
+	for i, sc := range c.records {
+		nodes[i] = &swiftCodeResolver{sc: sc}
+		l.codes.expect(nodes[i].BIC8())
+		if sc.IsHeadquarter {
+			l.branches.expect(bic.Normalize(sc.SwiftCode))
+		} else {
+			l.records.expect(nodes[i].headquarterCode())
+		}
+	}
+	return nodes
+}
+
+func (c *swiftCodeConnection) PageInfo() pageInfo {
+	info := pageInfo{hasNext: c.hasNext}
+	if len(c.records) > 0 {
+		info.endCursor = encodeCursor(c.records[len(c.records)-1].SwiftCode)
+	}
+	return info
+}
+
+type bankConnection struct {
+	total   int
+	nodes   []*bankResolver
+	hasNext bool
+}
+
+func (c *bankConnection) TotalCount() int32 { return int32(c.total) }
+
+func (c *bankConnection) Nodes(ctx context.Context) []*bankResolver {
+	l := loadersFrom(ctx)
+	for _, b := range c.nodes {
+		l.codes.expect(b.bic8)
+	}
+	return c.nodes
+}
+
+func (c *bankConnection) PageInfo() pageInfo {
+	info := pageInfo{hasNext: c.hasNext}
+	if len(c.nodes) > 0 {
+		info.endCursor = encodeCursor(c.nodes[len(c.nodes)-1].bic8)
+	}
+	return info
+}
+
+type swiftCodeResolver struct {
+	sc model.SwiftCode
+}
+
+func (s *swiftCodeResolver) SwiftCode() string   { return s.sc.SwiftCode }
+func (s *swiftCodeResolver) BIC8() string        { return db.BIC8(bic.Normalize(s.sc.SwiftCode)) }
+func (s *swiftCodeResolver) BankName() string    { return s.sc.BankName }
+func (s *swiftCodeResolver) Address() string     { return s.sc.Address }
+func (s *swiftCodeResolver) CountryISO2() string { return s.sc.CountryISO2 }
+func (s *swiftCodeResolver) CountryName() string { return s.sc.CountryName }
+func (s *swiftCodeResolver) IsHeadquarter() bool { return s.sc.IsHeadquarter }
+
+// institution zwraca wszystkie kody instytucji, do której należy kod.
+func (s *swiftCodeResolver) institution(ctx context.Context) ([]model.SwiftCode, error) {
+	codes, err := loadersFrom(ctx).codes.load(s.BIC8())
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return codes, nil
+}
+
+func (s *swiftCodeResolver) Country(ctx context.Context) (*countryResolver, error) {
+	return countryOf(ctx, s.sc.CountryISO2, s.sc.CountryName)
+}
+
+func (s *swiftCodeResolver) Bank(ctx context.Context) (*bankResolver, error) {
+	codes, err := s.institution(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		// Rekord usunięty w trakcie zapytania - bank opisuje sam kod.
+		codes = []model.SwiftCode{s.sc}
+	}
+	bank := model.NewBank(s.BIC8(), codes)
+	return &bankResolver{bic8: bank.BIC8, bankName: bank.BankName, countryISO2: bank.CountryISO2}, nil
+}
+
+// headquarterCode zwraca centralę oddziału: wskazaną w rekordzie po
+// przeniesieniu oddziałów, a bez tego centralę jego instytucji.
+func (s *swiftCodeResolver) headquarterCode() string {
+	if s.sc.HeadquarterCode != "" {
+		return bic.Normalize(s.sc.HeadquarterCode)
+	}
+	return s.BIC8() + "XXX"
+}
+
+func (s *swiftCodeResolver) Headquarter(ctx context.Context) (*swiftCodeResolver, error) {
+	if s.sc.IsHeadquarter {
+		return nil, nil
+	}
+	hq, err := loadersFrom(ctx).records.load(s.headquarterCode())
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	if hq.SwiftCode == "" {
+		return nil, nil
+	}
+	return &swiftCodeResolver{sc: hq}, nil
+}
+
+func (s *swiftCodeResolver) Branches(ctx context.Context) ([]*swiftCodeResolver, error) {
+	if !s.sc.IsHeadquarter {
+		return []*swiftCodeResolver{}, nil
+	}
+	l := loadersFrom(ctx)
+	codes, err := l.branches.load(bic.Normalize(s.sc.SwiftCode))
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	if err := l.charge(len(codes)); err != nil {
+		return nil, err
+	}
+	branches := make([]*swiftCodeResolver, len(codes))
+	for i, sc := range codes {
+		branches[i] = &swiftCodeResolver{sc: sc}
+	}
+	return branches, nil
+}
+
+func headquarterOf(codes []model.SwiftCode) *swiftCodeResolver {
+	for _, sc := range codes {
+		if bic.IsHeadquarter(bic.Normalize(sc.SwiftCode)) {
+			return &swiftCodeResolver{sc: sc}
+		}
+	}
+	return nil
+}
+
+func branchesOf(codes []model.SwiftCode) []*swiftCodeResolver {
+	branches := []*swiftCodeResolver{}
+	for _, sc := range codes {
+		if !bic.IsHeadquarter(bic.Normalize(sc.SwiftCode)) {
+			branches = append(branches, &swiftCodeResolver{sc: sc})
+		}
+	}
+	return branches
+}
+
+type bankResolver struct {
+	bic8        string
+	bankName    string
+	countryISO2 string
+}
+
+func (b *bankResolver) BIC8() string        { return b.bic8 }
+func (b *bankResolver) BankName() string    { return b.bankName }
+func (b *bankResolver) CountryISO2() string { return b.countryISO2 }
+
+func (b *bankResolver) Country(ctx context.Context) (*countryResolver, error) {
+	return countryOf(ctx, b.countryISO2, "")
+}
+
+func (b *bankResolver) Headquarter(ctx context.Context) (*swiftCodeResolver, error) {
+	codes, err := loadersFrom(ctx).codes.load(b.bic8)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return headquarterOf(codes), nil
+}
+
+func (b *bankResolver) Branches(ctx context.Context) ([]*swiftCodeResolver, error) {
+	l := loadersFrom(ctx)
+	codes, err := l.codes.load(b.bic8)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	branches := branchesOf(codes)
+	if err := l.charge(len(branches)); err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+type countryResolver struct {
+	iso2    string
+	name    string
+	summary model.CountrySummary
+}
+
+// newCountryResolver bierze nazwę kraju z rejestru ISO 3166, a dla kodów
+// spoza rejestru - z zapisanych rekordów.
+func newCountryResolver(iso2 string, summary model.CountrySummary) *countryResolver {
+	name := country.Name(iso2)
+	if name == "" {
+		name = summary.CountryName
+	}
+	return &countryResolver{iso2: iso2, name: name, summary: summary}
+}
+
+func countryOf(ctx context.Context, iso2, storedName string) (*countryResolver, error) {
+	summary, err := loadersFrom(ctx).countries.load(iso2)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	if summary.CountryName == "" {
+		summary.CountryName = storedName
+	}
+	return newCountryResolver(iso2, summary), nil
+}
+
+func (c *countryResolver) ISO2() string        { return c.iso2 }
+func (c *countryResolver) Name() string        { return c.name }
+func (c *countryResolver) Headquarters() int32 { return int32(c.summary.Headquarters) }
+func (c *countryResolver) Branches() int32     { return int32(c.summary.Branches) }
+
+func (c *countryResolver) Banks(ctx context.Context, args pageArgs) (*bankConnection, error) {
+	return newBankConnection(ctx, c.iso2, args)
+}
+
+// SwiftCodes pobiera stronę kodów kraju razem ze stronami pozostałych krajów
+// z listy countries, więc lista krajów nie odpytuje bazy osobno dla każdego
+// kraju.
+func (c *countryResolver) SwiftCodes(ctx context.Context, args swiftCodesArgs) (*swiftCodeConnection, error) {
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+	filter := args.Filter.model()
+	filter.CountryISO2 = c.iso2
+
+	l := loadersFrom(ctx)
+	if err := l.charge(limit); err != nil {
+		return nil, err
+	}
+	records, err := l.countryPages(filter, after, limit+1).load(c.iso2)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	conn := pageOf(filter, records, limit)
+	conn.counts = l.countryCounts(filter)
+	return conn, nil
+}
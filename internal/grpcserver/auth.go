@@ -0,0 +1,87 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"strconv"
+	"strings"
+
+	"swift-codes/internal/middleware"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyMetadata to klucz metadanych z kluczem API, odpowiednik nagłówka
+// X-API-Key.
+var apiKeyMetadata = strings.ToLower(middleware.APIKeyHeader)
+
+// authorize stosuje do wywołania metody limity żądań i role z konfiguracji,
+// tak jak middleware API REST, i zwraca kontekst z tożsamością klienta.
+func (cfg Config) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	role, class := cfg.Route(fullMethod)
+
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(apiKeyMetadata); len(values) > 0 {
+			key = values[0]
+		}
+	}
+	var cert *x509.Certificate
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			addr = p.Addr.String()
+		}
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			cert = middleware.VerifiedCertificate(&info.State)
+		}
+	}
+	client := middleware.IdentifyClient(key, cfg.Auth.APIKeys, cert, addr)
+
+	if cfg.Limiter != nil && class != "" {
+		if retryAfter, err := cfg.Limiter.Allow(client, class); err != nil {
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(retryAfter.Seconds()))))
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+	}
+
+	id, err := cfg.Auth.Authorize(client, key, cert, role)
+	var denied *middleware.AccessError
+	if errors.As(err, &denied) {
+		if denied.Unauthenticated {
+			return nil, status.Error(codes.Unauthenticated, denied.Message)
+		}
+		return nil, status.Error(codes.PermissionDenied, denied.Message)
+	}
+	return middleware.WithIdentity(ctx, id), nil
+}
+
+func (cfg Config) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := cfg.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (cfg Config) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := cfg.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+}
+
+// identityStream przekazuje strumieniowi kontekst z tożsamością klienta.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context { return s.ctx }
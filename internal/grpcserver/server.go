@@ -0,0 +1,297 @@
+// Package grpcserver udostępnia katalog kodów SWIFT jako usługę gRPC
+// swiftcodes.v1.SwiftCodeService. Korzysta z tej samej bazy, cache,
+// normalizacji i walidacji co API REST.
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/cache"
+	"swift-codes/internal/country"
+	"swift-codes/internal/db"
+	"swift-codes/internal/handlers"
+	"swift-codes/internal/middleware"
+	"swift-codes/internal/model"
+	pb "swift-codes/pkg/swiftcodesv1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// exportBatchSize to liczba rekordów pobieranych z kursora naraz w Export.
+const exportBatchSize = 500
+
+type Config struct {
+	// LookupMaxBatch ogranicza liczbę kodów w BatchLookup.
+	LookupMaxBatch int
+	// DeletePolicy to domyślny tryb usuwania centrali z oddziałami
+	// (jeden z handlers.Delete*).
+	DeletePolicy string
+	// TLS włącza szyfrowanie połączeń; nil oznacza połączenia bez TLS.
+	TLS *tls.Config
+	// Auth przypisuje klucze API (metadane x-api-key) i certyfikaty
+	// klientów do ról jak w API REST; pole Required nie jest używane.
+	Auth middleware.AuthConfig
+	// Limiter ogranicza liczbę wywołań klientów; nil wyłącza limity.
+	Limiter *middleware.RateLimiter
+	// Route zwraca rolę potrzebną do wywołania metody (pełna nazwa gRPC)
+	// i klasę jej limitu; pusta klasa oznacza brak limitu. Nil wyłącza
+	// kontrolę dostępu i limity.
+	Route func(fullMethod string) (role, class string)
+}
+
+type service struct {
+	pb.UnimplementedSwiftCodeServiceServer
+	db    *sql.DB
+	codes *cache.Cache[model.SwiftCode]
+	cfg   Config
+}
+
+// New tworzy serwer gRPC z usługą katalogu, usługą health
+// (grpc.health.v1.Health) i refleksją serwera. Z cfg.Route wywołania podlegają
+// tym samym rolom i limitom co API REST.
+func New(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode], cfg Config) *grpc.Server {
+	var opts []grpc.ServerOption
+	if cfg.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(cfg.TLS)))
+	}
+	if cfg.Route != nil {
+		opts = append(opts, grpc.UnaryInterceptor(cfg.unaryInterceptor), grpc.StreamInterceptor(cfg.streamInterceptor))
+	}
+	s := grpc.NewServer(opts...)
+	pb.RegisterSwiftCodeServiceServer(s, &service{db: dbConn, codes: codes, cfg: cfg})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.SwiftCodeService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	reflection.Register(s)
+	return s
+}
+
+// internalError zapisuje błąd w logu i zwraca klientowi ogólny komunikat.
+func internalError(ctx context.Context, message string, err error) error {
+	slog.ErrorContext(ctx, message, "error", err)
+	return status.Error(codes.Internal, message)
+}
+
+// requestError zamienia błąd z handlers (RequestError ze statusem HTTP) na
+// status gRPC; pozostałe błędy są wewnętrzne.
+func requestError(ctx context.Context, message string, err error) error {
+	var reqErr *handlers.RequestError
+	if !errors.As(err, &reqErr) {
+		return internalError(ctx, message, err)
+	}
+	code := codes.InvalidArgument
+	switch reqErr.Status {
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		code = codes.FailedPrecondition
+	case http.StatusPreconditionFailed:
+		code = codes.Aborted
+	}
+	return status.Error(code, reqErr.Message)
+}
+
+func (s *service) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	code := bic.Canonical(req.GetSwiftCode())
+	if err := bic.Validate(code); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Nieprawidłowy kod SWIFT: "+err.Error())
+	}
+
+	sc, err := s.codes.GetOrLoad(code, func() (model.SwiftCode, error) {
+		return handlers.LoadSwiftCode(s.db, code)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "Nie znaleziono wpisu")
+	}
+	if err != nil {
+		return nil, internalError(ctx, "Błąd pobierania danych", err)
+	}
+	return &pb.GetResponse{SwiftCode: toProto(sc)}, nil
+}
+
+func (s *service) ListByCountry(ctx context.Context, req *pb.ListByCountryRequest) (*pb.ListByCountryResponse, error) {
+	iso2 := strings.ToUpper(strings.TrimSpace(req.GetCountryIso2()))
+	records, err := db.GetSwiftCodesByCountry(s.db, iso2)
+	if err != nil {
+		return nil, internalError(ctx, "Błąd pobierania danych", err)
+	}
+
+	countryName := country.Name(iso2)
+	if countryName == "" {
+		if len(records) == 0 {
+			return nil, status.Error(codes.NotFound, "Nieznany kod kraju")
+		}
+		countryName = records[0].CountryName
+	}
+
+	response := &pb.ListByCountryResponse{CountryIso2: iso2, CountryName: countryName}
+	for _, sc := range records {
+		response.SwiftCodes = append(response.SwiftCodes, toProto(sc))
+	}
+	return response, nil
+}
+
+func (s *service) BatchLookup(ctx context.Context, req *pb.BatchLookupRequest) (*pb.BatchLookupResponse, error) {
+	requested := req.GetSwiftCodes()
+	if len(requested) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Lista swift_codes nie może być pusta")
+	}
+	if len(requested) > s.cfg.LookupMaxBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "Maksymalna liczba kodów w jednym żądaniu to %d", s.cfg.LookupMaxBatch)
+	}
+
+	canonical := make([]string, len(requested))
+	for i, code := range requested {
+		canonical[i] = bic.Canonical(code)
+	}
+	records, err := db.GetSwiftCodes(s.db, canonical)
+	if err != nil {
+		return nil, internalError(ctx, "Błąd pobierania danych", err)
+	}
+	byCode := make(map[string]model.SwiftCode, len(records))
+	for _, sc := range records {
+		byCode[bic.Normalize(sc.SwiftCode)] = sc
+	}
+
+	response := &pb.BatchLookupResponse{}
+	reported := make(map[string]bool, len(canonical))
+	for i, code := range canonical {
+		if reported[code] {
+			continue
+		}
+		reported[code] = true
+		if sc, ok := byCode[code]; ok {
+			response.Found = append(response.Found, toProto(sc))
+		} else {
+			response.NotFound = append(response.NotFound, requested[i])
+		}
+	}
+	return response, nil
+}
+
+func (s *service) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateResponse, error) {
+	if req.GetSwiftCode() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Pole swift_code jest wymagane")
+	}
+	sc := fromProto(req.GetSwiftCode())
+	handlers.NormalizeSwiftCode(&sc)
+	if errs := handlers.ValidateSwiftCode(sc); len(errs) > 0 {
+		return nil, status.Error(codes.InvalidArgument, handlers.ValidationMessage(errs))
+	}
+
+	err := db.InsertSwiftCode(s.db, sc)
+	if errors.Is(err, db.ErrDuplicate) {
+		return nil, status.Error(codes.AlreadyExists, "Wpis o tym kodzie SWIFT już istnieje")
+	}
+	if err != nil {
+		return nil, internalError(ctx, "Nie udało się dodać wpisu", err)
+	}
+	handlers.InvalidateSwiftCode(s.codes, sc.SwiftCode)
+
+	stored, err := handlers.LoadSwiftCode(s.db, sc.SwiftCode)
+	if err != nil {
+		return nil, internalError(ctx, "Błąd pobierania danych", err)
+	}
+	return &pb.CreateResponse{SwiftCode: toProto(stored)}, nil
+}
+
+var branchModes = map[pb.BranchMode]string{
+	pb.BranchMode_BRANCH_MODE_UNSPECIFIED: "",
+	pb.BranchMode_BRANCH_MODE_ORPHAN:      handlers.DeleteOrphan,
+	pb.BranchMode_BRANCH_MODE_REFUSE:      handlers.DeleteRefuse,
+	pb.BranchMode_BRANCH_MODE_CASCADE:     handlers.DeleteCascade,
+	pb.BranchMode_BRANCH_MODE_REPARENT:    handlers.DeleteReparent,
+}
+
+func (s *service) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	requestedMode, ok := branchModes[req.GetBranches()]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Nieznany tryb branches")
+	}
+	mode, err := handlers.ResolveDeleteMode(requestedMode, s.cfg.DeletePolicy)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// Odpowiednik nagłówka If-Match w API REST.
+	if req.GetExpectedVersion() == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "Pole expected_version jest wymagane")
+	}
+
+	code := bic.Canonical(req.GetSwiftCode())
+	current, err := handlers.LoadSwiftCode(s.db, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "Nie znaleziono wpisu")
+	}
+	if err != nil {
+		return nil, internalError(ctx, "Błąd pobierania danych", err)
+	}
+	if current.Version != req.GetExpectedVersion() {
+		return nil, status.Error(codes.Aborted, "Wpis został zmieniony, pobierz go ponownie")
+	}
+
+	message, err := handlers.DeleteSwiftCode(s.db, s.codes, current, mode, req.GetNewHeadquarter())
+	if err != nil {
+		return nil, requestError(ctx, "Nie udało się usunąć wpisu", err)
+	}
+	handlers.InvalidateSwiftCode(s.codes, code)
+	return &pb.DeleteResponse{Message: message}, nil
+}
+
+func (s *service) Export(_ *pb.ExportRequest, stream grpc.ServerStreamingServer[pb.ExportResponse]) error {
+	ctx := stream.Context()
+	err := db.StreamSwiftCodes(ctx, s.db, exportBatchSize, func(sc model.SwiftCode) error {
+		return stream.Send(&pb.ExportResponse{SwiftCode: toProto(sc)})
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return internalError(ctx, "Błąd eksportu danych", err)
+	}
+	return nil
+}
+
+func toProto(sc model.SwiftCode) *pb.SwiftCode {
+	out := &pb.SwiftCode{
+		SwiftCode:     sc.SwiftCode,
+		BankName:      sc.BankName,
+		Address:       sc.Address,
+		CountryIso2:   sc.CountryISO2,
+		CountryName:   sc.CountryName,
+		IsHeadquarter: sc.IsHeadquarter,
+		Version:       sc.Version,
+	}
+	for _, branch := range sc.Branches {
+		out.Branches = append(out.Branches, toProto(branch))
+	}
+	if sc.Headquarter != nil {
+		out.Headquarter = &pb.HeadquarterRef{SwiftCode: sc.Headquarter.SwiftCode, BankName: sc.Headquarter.BankName}
+	}
+	return out
+}
+
+func fromProto(sc *pb.SwiftCode) model.SwiftCode {
+	return model.SwiftCode{
+		SwiftCode:     sc.GetSwiftCode(),
+		BankName:      sc.GetBankName(),
+		Address:       sc.GetAddress(),
+		CountryISO2:   sc.GetCountryIso2(),
+		CountryName:   sc.GetCountryName(),
+		IsHeadquarter: sc.GetIsHeadquarter(),
+	}
+}
@@ -0,0 +1,269 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"swift-codes/internal/cache"
+	"swift-codes/internal/db"
+	"swift-codes/internal/handlers"
+	"swift-codes/internal/middleware"
+	"swift-codes/internal/model"
+	pb "swift-codes/pkg/swiftcodesv1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startServer uruchamia serwer w pamięci bez kontroli dostępu i zwraca
+// połączenie z nim.
+func startServer(t *testing.T, dbConn *sql.DB) *grpc.ClientConn {
+	return startServerWithConfig(t, dbConn, Config{Auth: middleware.AuthConfig{Disabled: true}})
+}
+
+func startServerWithConfig(t *testing.T, dbConn *sql.DB, cfg Config) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	cfg.LookupMaxBatch = 3
+	cfg.DeletePolicy = handlers.DeleteRefuse
+	server := New(dbConn, cache.New[model.SwiftCode](100, time.Minute), cfg)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Nie udało się połączyć z serwerem gRPC: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func expectCode(t *testing.T, name string, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("%s - oczekiwano kodu %v, otrzymano %v (%v)", name, want, got, err)
+	}
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	conn := startServer(t, nil)
+	ctx := context.Background()
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "swiftcodes.v1.SwiftCodeService"})
+	if err != nil || health.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Oczekiwano statusu SERVING, otrzymano %v (%v)", health.GetStatus(), err)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatalf("Błąd otwierania strumienia refleksji: %v", err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatalf("Błąd wysyłania żądania refleksji: %v", err)
+	}
+	response, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Błąd odbierania odpowiedzi refleksji: %v", err)
+	}
+	found := false
+	for _, svc := range response.GetListServicesResponse().GetService() {
+		found = found || svc.GetName() == "swiftcodes.v1.SwiftCodeService"
+	}
+	if !found {
+		t.Errorf("Refleksja nie zwróciła usługi katalogu: %v", response.GetListServicesResponse().GetService())
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	client := pb.NewSwiftCodeServiceClient(startServer(t, nil))
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, &pb.GetRequest{SwiftCode: "BPKO"})
+	expectCode(t, "Get z nieprawidłowym kodem", err, codes.InvalidArgument)
+
+	_, err = client.BatchLookup(ctx, &pb.BatchLookupRequest{})
+	expectCode(t, "BatchLookup bez kodów", err, codes.InvalidArgument)
+	_, err = client.BatchLookup(ctx, &pb.BatchLookupRequest{SwiftCodes: []string{"A", "B", "C", "D"}})
+	expectCode(t, "BatchLookup ponad limit", err, codes.InvalidArgument)
+
+	_, err = client.Create(ctx, &pb.CreateRequest{})
+	expectCode(t, "Create bez rekordu", err, codes.InvalidArgument)
+	_, err = client.Create(ctx, &pb.CreateRequest{SwiftCode: &pb.SwiftCode{SwiftCode: "BPKOPLPWXXX", CountryIso2: "DE"}})
+	expectCode(t, "Create z nieprawidłowym rekordem", err, codes.InvalidArgument)
+
+	_, err = client.Delete(ctx, &pb.DeleteRequest{SwiftCode: "BPKOPLPWXXX"})
+	expectCode(t, "Delete bez wersji", err, codes.FailedPrecondition)
+	_, err = client.Delete(ctx, &pb.DeleteRequest{SwiftCode: "BPKOPLPWXXX", ExpectedVersion: 1, Branches: pb.BranchMode_BRANCH_MODE_ORPHAN})
+	expectCode(t, "Delete z osieroceniem wbrew zasadom serwera", err, codes.InvalidArgument)
+}
+
+func TestAccessControl(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.RateLimitConfig{
+		Limits: map[string]middleware.RateLimit{middleware.RouteLookup: {Rate: 1.0 / 60, Burst: 2}},
+	})
+	client := pb.NewSwiftCodeServiceClient(startServerWithConfig(t, nil, Config{
+		Auth:    middleware.AuthConfig{APIKeys: map[string]string{"klucz-r": middleware.RoleReader, "klucz-w": middleware.RoleWriter}},
+		Limiter: limiter,
+		Route: func(fullMethod string) (string, string) {
+			switch fullMethod {
+			case pb.SwiftCodeService_Create_FullMethodName, pb.SwiftCodeService_Delete_FullMethodName:
+				return middleware.RoleWriter, middleware.RouteWrite
+			case pb.SwiftCodeService_Get_FullMethodName:
+				return middleware.RoleReader, middleware.RouteLookup
+			}
+			return middleware.RoleReader, ""
+		},
+	}))
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+	}
+
+	_, err := client.Create(context.Background(), &pb.CreateRequest{})
+	expectCode(t, "Create bez klucza", err, codes.Unauthenticated)
+	_, err = client.Create(withKey("nieznany"), &pb.CreateRequest{})
+	expectCode(t, "Create z nieznanym kluczem", err, codes.Unauthenticated)
+	_, err = client.Delete(withKey("klucz-r"), &pb.DeleteRequest{SwiftCode: "BPKOPLPWXXX"})
+	expectCode(t, "Delete z kluczem reader", err, codes.PermissionDenied)
+	_, err = client.Create(withKey("klucz-w"), &pb.CreateRequest{})
+	expectCode(t, "Create z kluczem writer", err, codes.InvalidArgument)
+
+	stream, err := client.Export(withKey("nieznany"), &pb.ExportRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	expectCode(t, "Export z nieznanym kluczem", err, codes.Unauthenticated)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Get(context.Background(), &pb.GetRequest{SwiftCode: "BPKO"})
+		expectCode(t, "Get w limicie", err, codes.InvalidArgument)
+	}
+	var header metadata.MD
+	_, err = client.Get(context.Background(), &pb.GetRequest{SwiftCode: "BPKO"}, grpc.Header(&header))
+	expectCode(t, "Get ponad limit", err, codes.ResourceExhausted)
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] != "60" {
+		t.Errorf("Oczekiwano retry-after 60, otrzymano %v", got)
+	}
+}
+
+func TestRequestErrorMapping(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{&handlers.RequestError{Status: http.StatusBadRequest}, codes.InvalidArgument},
+		{&handlers.RequestError{Status: http.StatusConflict}, codes.FailedPrecondition},
+		{&handlers.RequestError{Status: http.StatusUnprocessableEntity}, codes.FailedPrecondition},
+		{&handlers.RequestError{Status: http.StatusPreconditionFailed}, codes.Aborted},
+		{errors.New("awaria bazy"), codes.Internal},
+	}
+	for _, tt := range tests {
+		expectCode(t, tt.err.Error(), requestError(context.Background(), "Błąd", tt.err), tt.want)
+	}
+}
+
+func TestService(t *testing.T) {
+	connStr := os.Getenv("TEST_DB_CONN")
+	if connStr == "" {
+		t.Fatal("Brak ustawionej zmiennej środowiskowej TEST_DB_CONN")
+	}
+	testDB, err := db.InitDB(connStr)
+	if err != nil {
+		t.Fatalf("InitDB nie powiodło się: %v", err)
+	}
+	defer testDB.Close()
+	if _, err := testDB.Exec("TRUNCATE TABLE swift_codes"); err != nil {
+		t.Fatalf("Nie udało się wyczyścić tabeli: %v", err)
+	}
+
+	client := pb.NewSwiftCodeServiceClient(startServer(t, testDB))
+	ctx := context.Background()
+
+	for _, sc := range []*pb.SwiftCode{
+		{SwiftCode: "grpcplpw", BankName: "grpc bank", Address: "HQ", CountryIso2: "pl", IsHeadquarter: true},
+		{SwiftCode: "GRPCPLPWKRK", BankName: "GRPC BANK", Address: "KRAKÓW", CountryIso2: "PL"},
+	} {
+		if _, err := client.Create(ctx, &pb.CreateRequest{SwiftCode: sc}); err != nil {
+			t.Fatalf("Create %s nie powiodło się: %v", sc.GetSwiftCode(), err)
+		}
+	}
+
+	hq, err := client.Get(ctx, &pb.GetRequest{SwiftCode: "grpc plpw"})
+	if err != nil {
+		t.Fatalf("Get nie powiodło się: %v", err)
+	}
+	if sc := hq.GetSwiftCode(); sc.GetSwiftCode() != "GRPCPLPWXXX" || sc.GetCountryName() != "POLAND" || len(sc.GetBranches()) != 1 {
+		t.Errorf("Oczekiwano centrali GRPCPLPWXXX z 1 oddziałem, otrzymano %v", sc)
+	}
+	branch, err := client.Get(ctx, &pb.GetRequest{SwiftCode: "GRPCPLPWKRK"})
+	if err != nil || branch.GetSwiftCode().GetHeadquarter().GetSwiftCode() != "GRPCPLPWXXX" {
+		t.Errorf("Oczekiwano oddziału z odnośnikiem do centrali, otrzymano %v (%v)", branch, err)
+	}
+	_, err = client.Get(ctx, &pb.GetRequest{SwiftCode: "NONEPLPWXXX"})
+	expectCode(t, "Get nieznanego kodu", err, codes.NotFound)
+
+	country, err := client.ListByCountry(ctx, &pb.ListByCountryRequest{CountryIso2: "pl"})
+	if err != nil || country.GetCountryName() != "POLAND" || len(country.GetSwiftCodes()) != 2 {
+		t.Errorf("Oczekiwano 2 kodów z Polski, otrzymano %v (%v)", country, err)
+	}
+	_, err = client.ListByCountry(ctx, &pb.ListByCountryRequest{CountryIso2: "QQ"})
+	expectCode(t, "ListByCountry nieznanego kraju", err, codes.NotFound)
+
+	lookup, err := client.BatchLookup(ctx, &pb.BatchLookupRequest{SwiftCodes: []string{"grpcplpw", "GRPCPLPWKRK", "MISSPLPWXXX"}})
+	if err != nil || len(lookup.GetFound()) != 2 || len(lookup.GetNotFound()) != 1 || lookup.GetNotFound()[0] != "MISSPLPWXXX" {
+		t.Errorf("Nieoczekiwany wynik BatchLookup: %v (%v)", lookup, err)
+	}
+
+	stream, err := client.Export(ctx, &pb.ExportRequest{})
+	if err != nil {
+		t.Fatalf("Export nie powiodło się: %v", err)
+	}
+	var exported []string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Błąd odbierania eksportu: %v", err)
+		}
+		exported = append(exported, msg.GetSwiftCode().GetSwiftCode())
+	}
+	if len(exported) != 2 || exported[0] != "GRPCPLPWKRK" || exported[1] != "GRPCPLPWXXX" {
+		t.Errorf("Oczekiwano eksportu posortowanego po kodzie, otrzymano %v", exported)
+	}
+
+	version := hq.GetSwiftCode().GetVersion()
+	_, err = client.Delete(ctx, &pb.DeleteRequest{SwiftCode: "GRPCPLPWXXX", ExpectedVersion: version + 1})
+	expectCode(t, "Delete z nieaktualną wersją", err, codes.Aborted)
+	_, err = client.Delete(ctx, &pb.DeleteRequest{SwiftCode: "GRPCPLPWXXX", ExpectedVersion: version})
+	expectCode(t, "Delete centrali z oddziałami", err, codes.FailedPrecondition)
+
+	deleted, err := client.Delete(ctx, &pb.DeleteRequest{SwiftCode: "GRPCPLPWXXX", ExpectedVersion: version, Branches: pb.BranchMode_BRANCH_MODE_CASCADE})
+	if err != nil {
+		t.Fatalf("Delete kaskadowe nie powiodło się: %v", err)
+	}
+	if deleted.GetMessage() == "" {
+		t.Error("Oczekiwano komunikatu o usunięciu")
+	}
+	_, err = client.Get(ctx, &pb.GetRequest{SwiftCode: "GRPCPLPWKRK"})
+	expectCode(t, "Get usuniętego oddziału", err, codes.NotFound)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"swift-codes/internal/cache"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+func CacheStatsHandler(codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(codes.Stats()); err != nil {
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+			return
+		}
+	}
+}
+
+func PurgeCacheHandler(codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		codes.Purge()
+
+		response := map[string]string{
+			"message": "Cache wyczyszczony",
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+			return
+		}
+	}
+}
+
+// OutboxHandler zwraca pozycje odbiorców zmian w dzienniku i liczbę zmian,
+// które jeszcze do nich nie dotarły.
+func OutboxHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cursors, err := db.ListOutboxCursors(dbConn)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		writeJSON(w, r, map[string]any{"sinks": cursors})
+	}
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"swift-codes/internal/middleware"
+)
+
+// WhoAmIHandler zwraca tożsamość i rolę klienta, np. aby interfejs mógł
+// pokazać tylko dozwolone operacje.
+func WhoAmIHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(middleware.IdentityFromContext(r.Context())); err != nil {
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/country"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+
+	"github.com/gorilla/mux"
+)
+
+func GetBankHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
+		// Pełny kod BIC11 wskazuje ten sam bank co jego pierwsze 8 znaków.
+		bic8 := bic.Normalize(mux.Vars(r)["bic8"])
+		if bic.Validate(bic8) != nil {
+			http.Error(w, "Nieprawidłowy kod BIC8", http.StatusBadRequest)
+			return
+		}
+		bic8 = db.BIC8(bic8)
+
+		codes, err := db.GetSwiftCodesByBIC8(dbConn, bic8)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		if len(codes) == 0 {
+			http.Error(w, "Nie znaleziono banku", http.StatusNotFound)
+			return
+		}
+
+		if notModified(w, r, computeETag(media, codes...), lastModified(codes...)) {
+			return
+		}
+		render(w, r, media, model.NewBank(bic8, codes))
+	}
+}
+
+func ListBanksHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
+		countryISO2 := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("country")))
+		if countryISO2 == "" {
+			http.Error(w, "Parametr country jest wymagany", http.StatusBadRequest)
+			return
+		}
+
+		banks, err := db.ListBanks(dbConn, countryISO2)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+
+		countryName := country.Name(countryISO2)
+		if countryName == "" && len(banks) == 0 {
+			http.Error(w, "Nieznany kod kraju", http.StatusNotFound)
+			return
+		}
+		if banks == nil {
+			banks = []model.BankSummary{}
+		}
+
+		render(w, r, media, model.BankList{
+			CountryISO2: countryISO2,
+			CountryName: countryName,
+			Banks:       banks,
+		})
+	}
+}
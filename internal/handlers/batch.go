@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/cache"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best-effort"
+)
+
+const (
+	batchStatusCreated    = "created"
+	batchStatusUpdated    = "updated"
+	batchStatusInvalid    = "invalid"
+	batchStatusConflict   = "conflict"
+	batchStatusFailed     = "failed"
+	batchStatusRolledBack = "rolledBack"
+)
+
+// batchRecordBytes to rozmiar treści żądania wsadowego przypadający na jeden
+// rekord; rekordy z katalogu zajmują w JSON kilkaset bajtów.
+const batchRecordBytes = 8 << 10
+
+// batchRecord to rekord żądania wsadowego. Rekord bez IfMatch jest dodawany
+// jako nowy; nadpisanie istniejącego wymaga jego ETagu, jak nagłówek If-Match
+// w PUT.
+type batchRecord struct {
+	model.SwiftCode
+	IfMatch string `json:"ifMatch,omitempty"`
+}
+
+// decodeBatch odczytuje rekordy po jednym i przerywa, gdy jest ich więcej
+// niż maxItems, więc zbyt duża tablica nie jest wczytywana w całości.
+func decodeBatch(r *http.Request, maxItems int) ([]batchRecord, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	dec := json.NewDecoder(r.Body)
+
+	var records []batchRecord
+	if mediaType == "application/x-ndjson" || mediaType == "application/jsonl" {
+		for {
+			var sc batchRecord
+			err := dec.Decode(&sc)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("wiersz %d: %w", len(records)+1, err)
+			}
+			records = append(records, sc)
+			if len(records) > maxItems {
+				return nil, errBatchTooLarge
+			}
+		}
+		return records, nil
+	}
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('[') {
+		return nil, errors.New("oczekiwano tablicy JSON")
+	}
+	for dec.More() {
+		var sc batchRecord
+		if err := dec.Decode(&sc); err != nil {
+			return nil, fmt.Errorf("rekord %d: %w", len(records)+1, err)
+		}
+		records = append(records, sc)
+		if len(records) > maxItems {
+			return nil, errBatchTooLarge
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+var errBatchTooLarge = errors.New("zbyt wiele rekordów")
+
+// BatchSwiftCodesHandler przyjmuje tablicę JSON lub strumień NDJSON rekordów.
+// W trybie atomic wszystkie rekordy są zapisywane w jednej transakcji albo
+// żaden, w trybie best-effort każdy rekord jest zapisywany niezależnie.
+func BatchSwiftCodesHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode], maxItems int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mode := r.URL.Query().Get("mode")
+		if mode == "" {
+			mode = batchModeBestEffort
+		}
+		if mode != batchModeAtomic && mode != batchModeBestEffort {
+			http.Error(w, "Parametr mode musi mieć wartość atomic lub best-effort", http.StatusBadRequest)
+			return
+		}
+
+		limit := int64(maxItems+1) * batchRecordBytes
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		batch, err := decodeBatch(r, maxItems)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Maksymalny rozmiar żądania to %d bajtów", limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, errBatchTooLarge) {
+			http.Error(w, fmt.Sprintf("Maksymalna liczba rekordów w jednym żądaniu to %d", maxItems), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Błędny format danych: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(batch) == 0 {
+			http.Error(w, "Lista rekordów nie może być pusta", http.StatusBadRequest)
+			return
+		}
+
+		records := make([]model.SwiftCode, len(batch))
+		result := model.BatchResult{
+			Mode:  mode,
+			Total: len(batch),
+			Items: make([]model.BatchItemResult, len(batch)),
+		}
+		valid := true
+		for i := range batch {
+			records[i] = batch[i].SwiftCode
+			NormalizeSwiftCode(&records[i])
+			result.Items[i] = model.BatchItemResult{Index: i, SwiftCode: records[i].SwiftCode}
+			if errs := ValidateSwiftCode(records[i]); len(errs) > 0 {
+				result.Items[i].Status = batchStatusInvalid
+				result.Items[i].Errors = errs
+				valid = false
+			}
+		}
+		if err := resolveVersions(dbConn, batch, records, result.Items); err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		for _, item := range result.Items {
+			if item.Status != "" {
+				valid = false
+			}
+		}
+
+		status := http.StatusOK
+		if mode == batchModeAtomic {
+			if !valid {
+				markPending(result.Items, batchStatusRolledBack)
+				status = http.StatusUnprocessableEntity
+			} else if saved, err := applyAtomic(dbConn, records, result.Items); err != nil {
+				internalError(w, r, "Nie udało się zapisać rekordów", err)
+				return
+			} else if !saved {
+				status = http.StatusUnprocessableEntity
+			}
+		} else {
+			applyBestEffort(r, dbConn, records, result.Items)
+		}
+
+		for i, item := range result.Items {
+			switch item.Status {
+			case batchStatusCreated, batchStatusUpdated:
+				result.Succeeded++
+				InvalidateSwiftCode(codes, records[i].SwiftCode)
+			default:
+				result.Failed++
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+			return
+		}
+	}
+}
+
+func markPending(items []model.BatchItemResult, status string) {
+	for i := range items {
+		if items[i].Status == "" {
+			items[i].Status = status
+		}
+	}
+}
+
+// resolveVersions porównuje rekordy z bazą: rekord bez ifMatch nie może
+// nadpisać istniejącego kodu, a rekord z ifMatch dostaje wersję, którą
+// zapis ma zastąpić. Rekordy, których nie da się zapisać, są oznaczane jako
+// konflikt.
+func resolveVersions(dbConn *sql.DB, batch []batchRecord, records []model.SwiftCode, items []model.BatchItemResult) error {
+	codes := make([]string, 0, len(records))
+	for i, sc := range records {
+		if items[i].Status == "" {
+			codes = append(codes, sc.SwiftCode)
+		}
+	}
+	existing, err := db.GetSwiftCodes(dbConn, codes)
+	if err != nil {
+		return err
+	}
+	byCode := make(map[string]model.SwiftCode, len(existing))
+	for _, sc := range existing {
+		byCode[bic.Normalize(sc.SwiftCode)] = sc
+	}
+
+	for i, sc := range records {
+		if items[i].Status != "" {
+			continue
+		}
+		current, found := byCode[bic.Normalize(sc.SwiftCode)]
+		switch {
+		case batch[i].IfMatch == "" && found:
+			items[i].Status = batchStatusConflict
+			items[i].Errors = []model.FieldError{{Field: "ifMatch", Message: "Wpis już istnieje; podaj jego ETag, aby go nadpisać"}}
+		case batch[i].IfMatch != "" && (!found || !matchesRow(batch[i].IfMatch, current)):
+			items[i].Status = batchStatusConflict
+			items[i].Errors = []model.FieldError{{Field: "ifMatch", Message: "Wpis został zmieniony, pobierz go ponownie"}}
+		case found:
+			records[i].Version = current.Version
+		}
+	}
+	return nil
+}
+
+func savedStatus(sc model.SwiftCode) string {
+	if sc.Version == 0 {
+		return batchStatusCreated
+	}
+	return batchStatusUpdated
+}
+
+// rejectedStatus zamienia błąd zapisu rekordu odrzuconego przez bazę na
+// status pozycji i jego powód; dla pozostałych błędów zwraca pusty status.
+func rejectedStatus(err error) (string, []model.FieldError) {
+	switch {
+	case errors.Is(err, db.ErrDuplicate):
+		return batchStatusConflict, []model.FieldError{{Field: "swiftCode", Message: "Wpis o tym kodzie już istnieje albo powtarza się w tej partii"}}
+	case errors.Is(err, db.ErrConflict):
+		return batchStatusConflict, []model.FieldError{{Field: "ifMatch", Message: "Wpis został zmieniony, pobierz go ponownie"}}
+	case errors.Is(err, db.ErrInvalid):
+		return batchStatusInvalid, []model.FieldError{{Field: "record", Message: err.Error()}}
+	}
+	return "", nil
+}
+
+// applyAtomic zapisuje rekordy w jednej transakcji. Jeśli baza odrzuci
+// któryś z nich, np. kod dodany w międzyczasie, oznacza go tak jak wynik
+// walidacji, pozostałe jako wycofane, i zwraca false.
+func applyAtomic(dbConn *sql.DB, records []model.SwiftCode, items []model.BatchItemResult) (bool, error) {
+	err := db.SaveSwiftCodes(dbConn, records)
+	var recordErr *db.RecordError
+	if errors.As(err, &recordErr) {
+		if status, errs := rejectedStatus(recordErr.Err); status != "" {
+			items[recordErr.Index].Status = status
+			items[recordErr.Index].Errors = errs
+			markPending(items, batchStatusRolledBack)
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	for i := range items {
+		items[i].Status = savedStatus(records[i])
+	}
+	return true, nil
+}
+
+func applyBestEffort(r *http.Request, dbConn *sql.DB, records []model.SwiftCode, items []model.BatchItemResult) {
+	for i, sc := range records {
+		if items[i].Status != "" {
+			continue
+		}
+		err := db.SaveSwiftCodes(dbConn, []model.SwiftCode{sc})
+		if status, errs := rejectedStatus(err); status != "" {
+			items[i].Status = status
+			items[i].Errors = errs
+			continue
+		}
+		if err != nil {
+			logError(r, "Nie udało się zapisać rekordu "+sc.SwiftCode, err)
+			items[i].Status = batchStatusFailed
+			continue
+		}
+		items[i].Status = savedStatus(sc)
+	}
+}
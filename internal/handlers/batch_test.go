@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBatch(t *testing.T) {
+	array := `[{"swiftCode": "BPKOPLPWXXX"}, {"swiftCode": "BPKOPLPWBIA", "ifMatch": "\"abc\""}]`
+	ndjson := "{\"swiftCode\": \"BPKOPLPWXXX\"}\n{\"swiftCode\": \"BPKOPLPWBIA\", \"ifMatch\": \"\\\"abc\\\"\"}\n"
+
+	for contentType, body := range map[string]string{
+		"application/json":                    array,
+		"application/x-ndjson":                ndjson,
+		"application/x-ndjson; charset=utf-8": ndjson,
+	} {
+		req := httptest.NewRequest("POST", "/v1/swift-codes/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+
+		records, err := decodeBatch(req, 10)
+		if err != nil {
+			t.Fatalf("%s - decodeBatch nie powiodło się: %v", contentType, err)
+		}
+		if len(records) != 2 || records[1].SwiftCode.SwiftCode != "BPKOPLPWBIA" || records[1].IfMatch != `"abc"` {
+			t.Errorf("%s - oczekiwano 2 rekordów, otrzymano %+v", contentType, records)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/v1/swift-codes/batch", strings.NewReader(ndjson))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if _, err := decodeBatch(req, 1); !errors.Is(err, errBatchTooLarge) {
+		t.Errorf("Oczekiwano errBatchTooLarge, otrzymano %v", err)
+	}
+}
+
+// endlessArray to nieskończona tablica JSON rekordów.
+type endlessArray struct {
+	started bool
+	read    int
+}
+
+func (a *endlessArray) Read(p []byte) (int, error) {
+	chunk := `{"swiftCode": "BPKOPLPWXXX"},`
+	if !a.started {
+		a.started = true
+		chunk = "[" + chunk
+	}
+	n := copy(p, chunk)
+	a.read += n
+	return n, nil
+}
+
+func TestDecodeBatch_StopsAtLimit(t *testing.T) {
+	body := &endlessArray{}
+	req := httptest.NewRequest("POST", "/v1/swift-codes/batch", body)
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := decodeBatch(req, 3); !errors.Is(err, errBatchTooLarge) {
+		t.Fatalf("Oczekiwano errBatchTooLarge, otrzymano %v", err)
+	}
+	if body.read > 4096 {
+		t.Errorf("Tablica powinna być czytana tylko do przekroczenia limitu, przeczytano %d bajtów", body.read)
+	}
+
+	req = httptest.NewRequest("POST", "/v1/swift-codes/batch", strings.NewReader(`{"swiftCode": "BPKOPLPWXXX"}`))
+	if _, err := decodeBatch(req, 3); err == nil {
+		t.Error("Oczekiwano błędu dla obiektu zamiast tablicy")
+	}
+}
+
+func TestBatchHandler_BodyLimit(t *testing.T) {
+	body := `[{"swiftCode": "BPKOPLPWXXX", "bankName": "` + strings.Repeat("A", 3*batchRecordBytes) + `"}]`
+	req := httptest.NewRequest("POST", "/v1/swift-codes/batch", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	BatchSwiftCodesHandler(nil, nil, 1)(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Oczekiwano status 413, otrzymano %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"swift-codes/internal/cache"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+const (
+	changesDefaultLimit = 1000
+	changesMaxLimit     = 10000
+	// changesKeepalive to odstęp komentarzy podtrzymujących bezczynny
+	// strumień SSE, żeby pośrednicy nie zamykali połączenia.
+	changesKeepalive = 15 * time.Second
+)
+
+// parseSince odczytuje numer ostatniej znanej klientowi zmiany.
+func parseSince(w http.ResponseWriter, value string) (int64, bool) {
+	if value == "" {
+		return 0, true
+	}
+	since, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || since < 0 {
+		http.Error(w, "Parametr since musi być nieujemną liczbą całkowitą", http.StatusBadRequest)
+		return 0, false
+	}
+	return since, true
+}
+
+// ListChangesHandler zwraca zmiany o numerach większych niż since
+// w kolejności numerów. Klient ponawia zapytanie z nextSince, dopóki hasMore
+// jest ustawione.
+func ListChangesHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		since, ok := parseSince(w, r.URL.Query().Get("since"))
+		if !ok {
+			return
+		}
+		limit := changesDefaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > changesMaxLimit {
+				http.Error(w, fmt.Sprintf("Parametr limit musi być liczbą od 1 do %d", changesMaxLimit), http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		latest, err := db.LatestChangeSequence(dbConn)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		if since > latest {
+			http.Error(w, "Parametr since jest większy niż numer ostatniej zmiany; zsynchronizuj replikę od nowa", http.StatusConflict)
+			return
+		}
+		changes, err := db.ListChanges(dbConn, since, limit)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+
+		feed := model.ChangeFeed{Changes: changes, NextSince: since, HasMore: len(changes) == limit}
+		if len(changes) > 0 {
+			feed.NextSince = changes[len(changes)-1].Sequence
+		}
+		// Zmiana zatwierdzona między zapytaniami może już być na liście.
+		feed.LatestSequence = max(latest, feed.NextSince)
+		writeJSON(w, r, feed)
+	}
+}
+
+// StreamChangesHandler wysyła zmiany jako Server-Sent Events: najpierw
+// zaległe zmiany od since (albo od nagłówka Last-Event-ID przy ponownym
+// połączeniu), potem nowe zmiany sprawdzane co pollInterval. Identyfikatorem
+// zdarzenia jest numer zmiany.
+func StreamChangesHandler(dbConn *sql.DB, pollInterval time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value := r.URL.Query().Get("since")
+		if id := r.Header.Get("Last-Event-ID"); id != "" {
+			value = id
+		}
+		since, ok := parseSince(w, value)
+		if !ok {
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", pollInterval.Milliseconds()); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			logError(r, "Strumień zmian nie obsługuje opróżniania bufora", err)
+			return
+		}
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		lastWrite := time.Now()
+		for {
+			changes, err := db.ListChanges(dbConn, since, changesDefaultLimit)
+			if err != nil {
+				// Klient połączy się ponownie z Last-Event-ID.
+				logError(r, "Błąd pobierania zmian", err)
+				return
+			}
+			for _, c := range changes {
+				data, err := json.Marshal(c)
+				if err != nil {
+					logError(r, "Błąd podczas kodowania zmiany", err)
+					return
+				}
+				if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", c.Sequence, c.Type, data); err != nil {
+					return
+				}
+				since = c.Sequence
+			}
+			if len(changes) > 0 {
+				lastWrite = time.Now()
+			} else if time.Since(lastWrite) >= changesKeepalive {
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+				lastWrite = time.Now()
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			// Pełna porcja oznacza, że zaległych zmian może być więcej.
+			if len(changes) == changesDefaultLimit && r.Context().Err() == nil {
+				continue
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// FollowChanges czyta dziennik zmian co interval do zakończenia ctx i usuwa
+// z cache kody zmienione poza tym procesem: przez narzędzie importu,
+// aktywację wersji danych w innej instancji albo zapisy w innej instancji.
+// Przy większej liczbie zaległych zmian cache jest czyszczony w całości.
+func FollowChanges(ctx context.Context, dbConn *sql.DB, codes *cache.Cache[model.SwiftCode], interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	since := int64(-1)
+	for {
+		if since < 0 {
+			// Numer jest czytany przed czyszczeniem, więc zmiana zapisana
+			// pomiędzy nie zostanie pominięta.
+			latest, err := db.LatestChangeSequence(dbConn)
+			if err == nil {
+				codes.Purge()
+				since = latest
+			} else {
+				slog.ErrorContext(ctx, "Błąd pobierania numeru ostatniej zmiany", "error", err)
+			}
+		} else {
+			changes, err := db.ListChanges(dbConn, since, changesDefaultLimit)
+			switch {
+			case err != nil:
+				slog.ErrorContext(ctx, "Błąd pobierania zmian", "error", err)
+			case len(changes) == changesDefaultLimit:
+				since = -1
+				continue
+			default:
+				since = invalidateChanges(codes, changes, since)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// invalidateChanges usuwa z cache kody ze zmian i nowe centrale
+// przeniesionych oddziałów. Zwraca numer ostatniej zmiany.
+func invalidateChanges(codes *cache.Cache[model.SwiftCode], changes []model.Change, since int64) int64 {
+	for _, c := range changes {
+		InvalidateSwiftCode(codes, c.SwiftCode)
+		if c.Record.HeadquarterCode != "" {
+			InvalidateSwiftCode(codes, c.Record.HeadquarterCode)
+		}
+		since = c.Sequence
+	}
+	return since
+}
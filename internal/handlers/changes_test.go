@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"swift-codes/internal/cache"
+	"swift-codes/internal/model"
+)
+
+func TestChangesHandlers_InvalidParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+		header  string
+	}{
+		{"ujemne since", ListChangesHandler(nil), "/v1/changes?since=-1", ""},
+		{"since nie jest liczbą", ListChangesHandler(nil), "/v1/changes?since=abc", ""},
+		{"zerowy limit", ListChangesHandler(nil), "/v1/changes?limit=0", ""},
+		{"za duży limit", ListChangesHandler(nil), "/v1/changes?limit=10001", ""},
+		{"błędny Last-Event-ID", StreamChangesHandler(nil, time.Second), "/v1/changes/stream", "abc"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("Last-Event-ID", tt.header)
+		}
+		rr := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s - oczekiwano status 400, otrzymano %d", tt.name, rr.Code)
+		}
+	}
+}
+
+func TestInvalidateChanges(t *testing.T) {
+	codes := cache.New[model.SwiftCode](10, time.Minute)
+	for _, code := range []string{"AAAAPLPWXXX", "AAAAPLPWKRK", "BBBBDEFFXXX", "CCCCFRPPXXX"} {
+		codes.Set(code, model.SwiftCode{SwiftCode: code})
+	}
+	changes := []model.Change{
+		{Sequence: 7, Type: model.ChangeUpdated, SwiftCode: "AAAAPLPWKRK"},
+		{Sequence: 9, Type: model.ChangeUpdated, SwiftCode: "DDDDPLPWGDA", Record: model.SwiftCode{SwiftCode: "DDDDPLPWGDA", HeadquarterCode: "BBBBDEFFXXX"}},
+	}
+	if since := invalidateChanges(codes, changes, 5); since != 9 {
+		t.Errorf("Oczekiwano numeru 9, otrzymano %d", since)
+	}
+	for code, cached := range map[string]bool{"AAAAPLPWXXX": false, "AAAAPLPWKRK": false, "BBBBDEFFXXX": false, "CCCCFRPPXXX": true} {
+		if _, ok := codes.Get(code); ok != cached {
+			t.Errorf("%s: oczekiwano obecności w cache %v, otrzymano %v", code, cached, ok)
+		}
+	}
+	if since := invalidateChanges(codes, nil, 9); since != 9 {
+		t.Errorf("Bez zmian numer nie powinien się zmienić, otrzymano %d", since)
+	}
+}
+
+func TestChangeFeed(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+	if _, err := testDB.Exec("TRUNCATE TABLE swift_code_changes"); err != nil {
+		t.Fatalf("Nie udało się wyczyścić dziennika zmian: %v", err)
+	}
+
+	get := func(path string) model.ChangeFeed {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GET %s - oczekiwano status 200, otrzymano %d: %s", path, rr.Code, rr.Body.String())
+		}
+		var feed model.ChangeFeed
+		if err := json.NewDecoder(rr.Body).Decode(&feed); err != nil {
+			t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+		}
+		return feed
+	}
+
+	start := get("/v1/changes").LatestSequence
+	record := model.SwiftCode{BankName: "FEED BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "FEEDPLPWXXX"}
+	for i := 0; i < 2; i++ {
+		body, _ := json.Marshal(record)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/swift-codes", bytes.NewReader(body)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("POST /v1/swift-codes - oczekiwano status 200, otrzymano %d", rr.Code)
+		}
+	}
+	req := httptest.NewRequest("DELETE", "/v1/swift-codes/FEEDPLPWXXX", nil)
+	req.Header.Set("If-Match", getETag(t, router, "/v1/swift-codes/FEEDPLPWXXX"))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("DELETE - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+
+	first := get(fmt.Sprintf("/v1/changes?since=%d&limit=2", start))
+	if len(first.Changes) != 2 || !first.HasMore || first.LatestSequence != start+3 {
+		t.Fatalf("Nieoczekiwana pierwsza strona zmian: %+v", first)
+	}
+	rest := get(fmt.Sprintf("/v1/changes?since=%d&limit=2", first.NextSince))
+	if len(rest.Changes) != 1 || rest.HasMore || rest.NextSince != start+3 {
+		t.Fatalf("Nieoczekiwana druga strona zmian: %+v", rest)
+	}
+
+	all := append(first.Changes, rest.Changes...)
+	wantTypes := []string{model.ChangeCreated, model.ChangeUpdated, model.ChangeDeleted}
+	for i, c := range all {
+		if c.Sequence != start+int64(i)+1 || c.Type != wantTypes[i] || c.Record.SwiftCode != "FEEDPLPWXXX" {
+			t.Errorf("Zmiana %d - oczekiwano %s z numerem %d, otrzymano %+v", i, wantTypes[i], start+int64(i)+1, c)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", fmt.Sprintf("/v1/changes?since=%d", start+100), nil))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("since za ostatnią zmianą - oczekiwano status 409, otrzymano %d", rr.Code)
+	}
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	streamReq, _ := http.NewRequest("GET", server.URL+"/v1/changes/stream", nil)
+	streamReq.Header.Set("Last-Event-ID", fmt.Sprint(start+1))
+	resp, err := http.DefaultClient.Do(streamReq)
+	if err != nil {
+		t.Fatalf("Błąd otwierania strumienia zmian: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Oczekiwano text/event-stream, otrzymano %q", ct)
+	}
+
+	var ids, events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < 2 && scanner.Scan() {
+		line := scanner.Text()
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+		if event, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, event)
+		}
+	}
+	wantIDs := []string{fmt.Sprint(start + 2), fmt.Sprint(start + 3)}
+	if fmt.Sprint(ids) != fmt.Sprint(wantIDs) || fmt.Sprint(events) != fmt.Sprint(wantTypes[1:]) {
+		t.Errorf("Oczekiwano zdarzeń %v %v, otrzymano %v %v", wantIDs, wantTypes[1:], ids, events)
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"swift-codes/internal/country"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+// ListCountriesHandler zwraca kraje obecne w katalogu. Nazwy krajów pochodzą
+// z rejestru ISO 3166, a nie z zapisanych rekordów.
+func ListCountriesHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
+
+		countries, err := db.CountSwiftCodesByCountry(dbConn)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		for i := range countries {
+			if name := country.Name(countries[i].CountryISO2); name != "" {
+				countries[i].CountryName = name
+			}
+		}
+		if countries == nil {
+			countries = []model.CountrySummary{}
+		}
+
+		render(w, r, media, model.CountryList{Countries: countries})
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+// ETag jest liczony z typu odpowiedzi i par (kod, wersja) wszystkich rekordów
+// składających się na odpowiedź, więc zmienia się przy każdej zmianie, dodaniu
+// lub usunięciu rekordu, w tym oddziału widocznego w odpowiedzi centrali
+// i centrali wskazanej w odpowiedzi oddziału. Odpowiedzi JSON, XML i CSV mają
+// różne treści, więc dostają różne silne ETagi.
+func computeETag(media string, records ...model.SwiftCode) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s;", media)
+	var write func(sc model.SwiftCode)
+	write = func(sc model.SwiftCode) {
+		fmt.Fprintf(h, "%s:%d;", sc.SwiftCode, sc.Version)
+		if sc.Headquarter != nil {
+			fmt.Fprintf(h, "^%s:%d;", sc.Headquarter.SwiftCode, sc.Headquarter.Version)
+		}
+		for _, branch := range sc.Branches {
+			write(branch)
+		}
+	}
+	for _, sc := range records {
+		write(sc)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// recordETag to ETag odpowiedzi z jednym rekordem. Jego pierwsza część
+// zależy tylko od wersji samego wiersza, a druga, jak computeETag, także od
+// dołączonych oddziałów i centrali. If-Match porównuje tylko pierwszą część,
+// więc zmiana oddziału nie blokuje zapisu centrali, a GET warunkowy nadal
+// zauważa zmianę listy oddziałów.
+func recordETag(media string, sc model.SwiftCode) string {
+	return `"` + rowTag(sc) + "-" + strings.Trim(computeETag(media, sc), `"`) + `"`
+}
+
+func rowTag(sc model.SwiftCode) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", sc.SwiftCode, sc.Version)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// matchesRow sprawdza warunek If-Match względem wersji samego rekordu. Pasuje
+// ETag z dowolnego formatu odpowiedzi, a także sama jego pierwsza część.
+func matchesRow(header string, current model.SwiftCode) bool {
+	want := rowTag(current)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if !strings.HasPrefix(candidate, `"`) {
+			continue
+		}
+		tag, _, _ := strings.Cut(strings.Trim(candidate, `"`), "-")
+		if tag == want {
+			return true
+		}
+	}
+	return false
+}
+
+func lastModified(records ...model.SwiftCode) time.Time {
+	var latest time.Time
+	for _, sc := range records {
+		if sc.UpdatedAt.After(latest) {
+			latest = sc.UpdatedAt
+		}
+		if branches := lastModified(sc.Branches...); branches.After(latest) {
+			latest = branches
+		}
+	}
+	return latest
+}
+
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified ustawia nagłówki walidatorów i odpowiada 304, jeśli klient ma
+// aktualną wersję. If-None-Match ma pierwszeństwo przed If-Modified-Since.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagMatches(inm, etag, true) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err == nil && !modified.Truncate(time.Second).After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed wymusza If-Match przy operacjach modyfikujących, żeby dwie
+// osoby edytujące ten sam rekord nie nadpisały nawzajem swoich zmian.
+func preconditionFailed(w http.ResponseWriter, r *http.Request, current model.SwiftCode) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		http.Error(w, "Wymagany nagłówek If-Match", http.StatusPreconditionRequired)
+		return true
+	}
+	if matchesRow(ifMatch, current) {
+		return false
+	}
+	http.Error(w, "Wpis został zmieniony, pobierz go ponownie", http.StatusPreconditionFailed)
+	return true
+}
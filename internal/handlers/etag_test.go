@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+func TestComputeETag_ChangesWithBranchVersion(t *testing.T) {
+	hq := model.SwiftCode{
+		SwiftCode: "AAISALTRXXX",
+		Version:   1,
+		Branches:  []model.SwiftCode{{SwiftCode: "AAISALTR001", Version: 1}},
+	}
+	before := computeETag(mediaJSON, hq)
+
+	hq.Branches[0].Version = 2
+	if after := computeETag(mediaJSON, hq); after == before {
+		t.Error("Oczekiwano zmiany ETag po zmianie wersji oddziału")
+	}
+}
+
+func TestComputeETag_ChangesWithHeadquarterVersion(t *testing.T) {
+	branch := model.SwiftCode{
+		SwiftCode:   "AAISALTR001",
+		Version:     1,
+		Headquarter: &model.HeadquarterRef{SwiftCode: "AAISALTRXXX", Version: 1},
+	}
+	before := computeETag(mediaJSON, branch)
+
+	branch.Headquarter.Version = 2
+	if after := computeETag(mediaJSON, branch); after == before {
+		t.Error("Oczekiwano zmiany ETag po zmianie wersji centrali")
+	}
+}
+
+func TestComputeETag_DiffersByMedia(t *testing.T) {
+	sc := model.SwiftCode{SwiftCode: "AAISALTRXXX", Version: 1}
+	seen := map[string]string{}
+	for _, media := range offeredMedia {
+		etag := computeETag(media, sc)
+		if other, ok := seen[etag]; ok {
+			t.Errorf("Typy %s i %s mają ten sam ETag %s", other, media, etag)
+		}
+		seen[etag] = media
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	etag := `"abc"`
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"bez nagłówków", nil, false},
+		{"zgodny If-None-Match", map[string]string{"If-None-Match": `"xyz", "abc"`}, true},
+		{"słaby If-None-Match", map[string]string{"If-None-Match": `W/"abc"`}, true},
+		{"niezgodny If-None-Match", map[string]string{"If-None-Match": `"xyz"`}, false},
+		{"If-Modified-Since po zmianie", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, true},
+		{"If-Modified-Since przed zmianą", map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, false},
+		{"If-None-Match ma pierwszeństwo", map[string]string{
+			"If-None-Match":     `"xyz"`,
+			"If-Modified-Since": modified.Format(http.TimeFormat),
+		}, false},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+
+		if got := notModified(rr, req, etag, modified); got != tc.want {
+			t.Errorf("%s - oczekiwano %v, otrzymano %v", tc.name, tc.want, got)
+		}
+		if rr.Header().Get("ETag") != etag {
+			t.Errorf("%s - brak nagłówka ETag", tc.name)
+		}
+	}
+}
+
+func TestRecordETag_RowPartIgnoresBranches(t *testing.T) {
+	hq := model.SwiftCode{
+		SwiftCode: "AAISALTRXXX",
+		Version:   1,
+		Branches:  []model.SwiftCode{{SwiftCode: "AAISALTR001", Version: 1}},
+	}
+	before := recordETag(mediaJSON, hq)
+
+	hq.Branches[0].Version = 2
+	if after := recordETag(mediaJSON, hq); after == before {
+		t.Error("Oczekiwano zmiany ETag po zmianie wersji oddziału")
+	}
+	if !matchesRow(before, hq) {
+		t.Error("ETag sprzed zmiany oddziału powinien nadal spełniać If-Match centrali")
+	}
+}
+
+func TestPreconditionFailed(t *testing.T) {
+	current := model.SwiftCode{
+		SwiftCode: "AAISALTRXXX",
+		Version:   2,
+		Branches:  []model.SwiftCode{{SwiftCode: "AAISALTR001", Version: 1}},
+	}
+	jsonETag := recordETag(mediaJSON, current)
+	tests := []struct {
+		ifMatch string
+		status  int
+	}{
+		{"", http.StatusPreconditionRequired},
+		{`"xyz"`, http.StatusPreconditionFailed},
+		{"W/" + jsonETag, http.StatusPreconditionFailed},
+		{recordETag(mediaJSON, model.SwiftCode{SwiftCode: "AAISALTRXXX", Version: 1}), http.StatusPreconditionFailed},
+		{jsonETag, http.StatusOK},
+		{recordETag(mediaXML, current), http.StatusOK},
+		{recordETag(mediaCSV, model.SwiftCode{SwiftCode: "AAISALTRXXX", Version: 2}), http.StatusOK},
+		{`"` + rowTag(current) + `"`, http.StatusOK},
+		{"*", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest("DELETE", "/", nil)
+		if tc.ifMatch != "" {
+			req.Header.Set("If-Match", tc.ifMatch)
+		}
+		rr := httptest.NewRecorder()
+
+		preconditionFailed(rr, req, current)
+		if rr.Code != tc.status {
+			t.Errorf("If-Match %q - oczekiwano status %d, otrzymano %d", tc.ifMatch, tc.status, rr.Code)
+		}
+	}
+}
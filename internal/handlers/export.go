@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+	"swift-codes/internal/parser"
+)
+
+const (
+	exportBatchSize  = 500
+	exportFlushEvery = 1000
+)
+
+type exportEncoder interface {
+	begin() error
+	encode(sc model.SwiftCode) error
+	end() error
+}
+
+type csvExport struct{ w *parser.Writer }
+
+func (e csvExport) begin() error                    { return nil }
+func (e csvExport) encode(sc model.SwiftCode) error { return e.w.Write(sc) }
+func (e csvExport) end() error                      { return e.w.Flush() }
+
+type ndjsonExport struct{ enc *json.Encoder }
+
+func (e ndjsonExport) begin() error                    { return nil }
+func (e ndjsonExport) encode(sc model.SwiftCode) error { return e.enc.Encode(sc) }
+func (e ndjsonExport) end() error                      { return nil }
+
+type xmlExport struct {
+	w   io.Writer
+	enc *xml.Encoder
+}
+
+func (e xmlExport) begin() error {
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	return e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "swiftCodes"}})
+}
+
+func (e xmlExport) encode(sc model.SwiftCode) error { return e.enc.Encode(sc) }
+
+func (e xmlExport) end() error {
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "swiftCodes"}}); err != nil {
+		return err
+	}
+	return e.enc.Flush()
+}
+
+// ExportSwiftCodesHandler strumieniuje cały katalog. Format CSV ma układ
+// kolumn pliku źródłowego, więc eksport można wczytać ponownie przez cmd/import.
+func ExportSwiftCodesHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+
+		var enc exportEncoder
+		var contentType string
+		switch format {
+		case "csv":
+			enc, contentType = csvExport{w: parser.NewWriter(w)}, "text/csv; charset=utf-8"
+		case "ndjson":
+			enc, contentType = ndjsonExport{enc: json.NewEncoder(w)}, "application/x-ndjson"
+		case "xml":
+			enc, contentType = xmlExport{w: w, enc: xml.NewEncoder(w)}, "application/xml; charset=utf-8"
+		default:
+			http.Error(w, "Parametr format musi mieć wartość csv, ndjson lub xml", http.StatusBadRequest)
+			return
+		}
+
+		filename := fmt.Sprintf("swiftcodes-%s.%s", time.Now().UTC().Format("20060102"), format)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		flusher, _ := w.(http.Flusher)
+		written := 0
+		err := enc.begin()
+		if err == nil {
+			err = db.StreamSwiftCodes(r.Context(), dbConn, exportBatchSize, func(sc model.SwiftCode) error {
+				if err := enc.encode(sc); err != nil {
+					return err
+				}
+				written++
+				if flusher != nil && written%exportFlushEvery == 0 {
+					if csv, ok := enc.(csvExport); ok {
+						csv.w.Flush()
+					}
+					flusher.Flush()
+				}
+				return nil
+			})
+		}
+		if err == nil {
+			err = enc.end()
+		}
+		if err != nil {
+			// Nagłówki zostały już wysłane, więc zrywamy połączenie, żeby klient
+			// nie potraktował uciętego eksportu jako kompletnego.
+			logError(r, "Błąd podczas eksportu danych", err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
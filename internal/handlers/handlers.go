@@ -3,79 +3,295 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"swift-codes/internal/bic"
+	"swift-codes/internal/cache"
+	"swift-codes/internal/country"
 	"swift-codes/internal/db"
 	"swift-codes/internal/model"
 
 	"github.com/gorilla/mux"
 )
 
+func logError(r *http.Request, message string, err error) {
+	slog.ErrorContext(r.Context(), message, "error", err)
+}
+
+func internalError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	logError(r, message, err)
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// LoadSwiftCode wczytuje wpis razem z oddziałami (dla centrali) albo
+// odnośnikiem do centrali (dla oddziału).
+func LoadSwiftCode(dbConn *sql.DB, code string) (model.SwiftCode, error) {
+	swiftData, err := db.GetSwiftCode(dbConn, code)
+	if err != nil {
+		return swiftData, err
+	}
+
+	if swiftData.IsHeadquarter {
+		branches, err := db.GetBranchesByHeadquarter(dbConn, swiftData.SwiftCode)
+		if err != nil {
+			return swiftData, fmt.Errorf("błąd podczas pobierania oddziałów: %w", err)
+		}
+		swiftData.Branches = branches
+		return swiftData, nil
+	}
+
+	hqCode := swiftData.HeadquarterCode
+	if hqCode == "" {
+		hqCode = headquarterCode(swiftData.SwiftCode)
+	}
+	hq, err := db.GetSwiftCode(dbConn, hqCode)
+	if err == nil {
+		swiftData.Headquarter = &model.HeadquarterRef{SwiftCode: hq.SwiftCode, BankName: hq.BankName, Version: hq.Version}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return swiftData, fmt.Errorf("błąd podczas pobierania centrali: %w", err)
+	}
+	return swiftData, nil
+}
+
+// headquarterCode zwraca kod centrali instytucji, do której należy kod.
+func headquarterCode(code string) string {
+	return db.BIC8(code) + "XXX"
+}
+
+// wantsHeadquarterFallback odczytuje parametr fallback=headquarter, który
+// pozwala zwrócić centralę zamiast nieznanego kodu oddziału. Dla innej
+// wartości parametru odpowiada 400 i zwraca ok == false.
+func wantsHeadquarterFallback(w http.ResponseWriter, r *http.Request) (fallback, ok bool) {
+	switch r.URL.Query().Get("fallback") {
+	case "":
+		return false, true
+	case "headquarter":
+		return true, true
+	default:
+		http.Error(w, "Parametr fallback może mieć tylko wartość headquarter", http.StatusBadRequest)
+		return false, false
+	}
+}
+
+// asFallback oznacza centralę zwróconą w miejsce nieznanego kodu.
+func asFallback(hq model.SwiftCode, requested string) model.SwiftCode {
+	hq.RequestedSwiftCode = requested
+	hq.HeadquarterFallback = true
+	return hq
+}
+
+// InvalidateSwiftCode usuwa z cache kod, wszystkie kody tego samego banku
+// oraz wpisy, które odwołują się do kodu jako do centrali lub oddziału, bo
+// zmiana oddziału zmienia też odpowiedź dla jego centrali, a przeniesiony
+// oddział może należeć do innego banku niż centrala.
+func InvalidateSwiftCode(codes *cache.Cache[model.SwiftCode], code string) {
+	code = bic.Normalize(code)
+	bic8 := db.BIC8(code)
+	codes.InvalidateFunc(func(key string, sc model.SwiftCode) bool {
+		if strings.HasPrefix(key, bic8) || bic.Normalize(sc.HeadquarterCode) == code {
+			return true
+		}
+		for _, branch := range sc.Branches {
+			if bic.Normalize(branch.SwiftCode) == code {
+				return true
+			}
+		}
+		return false
+	})
+}
 
-func GetSwiftCodeHandler(dbConn *sql.DB) http.HandlerFunc {
+func GetSwiftCodeHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
+		fallback, ok := wantsHeadquarterFallback(w, r)
+		if !ok {
+			return
+		}
 		vars := mux.Vars(r)
-		swiftCodeParam := vars["swiftCode"]
+		swiftCodeParam := bic.Canonical(vars["swiftCode"])
 
-		swiftData, err := db.GetSwiftCode(dbConn, swiftCodeParam)
-		if err != nil {
+		swiftData, err := codes.GetOrLoad(swiftCodeParam, func() (model.SwiftCode, error) {
+			return LoadSwiftCode(dbConn, swiftCodeParam)
+		})
+		if errors.Is(err, sql.ErrNoRows) && fallback && len(swiftCodeParam) == 11 && !bic.IsHeadquarter(swiftCodeParam) {
+			hqCode := headquarterCode(swiftCodeParam)
+			swiftData, err = codes.GetOrLoad(hqCode, func() (model.SwiftCode, error) {
+				return LoadSwiftCode(dbConn, hqCode)
+			})
+			if err == nil {
+				swiftData = asFallback(swiftData, swiftCodeParam)
+			}
+		}
+		if errors.Is(err, sql.ErrNoRows) {
 			http.Error(w, "Nie znaleziono wpisu", http.StatusNotFound)
 			return
 		}
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+
+		if notModified(w, r, recordETag(media, swiftData), lastModified(swiftData)) {
+			return
+		}
 
-		if swiftData.IsHeadquarter {
-			branches, err := db.GetBranchesByHeadquarter(dbConn, swiftData.SwiftCode)
-			if err != nil {
-				http.Error(w, "Błąd podczas pobierania oddziałów", http.StatusInternalServerError)
+		render(w, r, media, swiftData)
+	}
+}
+
+// GetHeadquarterHandler zwraca centralę instytucji, do której należy kod.
+// Sam kod oddziału nie musi być w katalogu.
+func GetHeadquarterHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
+		code := bic.Normalize(mux.Vars(r)["swiftCode"])
+		if err := bic.Validate(code); err != nil {
+			http.Error(w, "Nieprawidłowy kod SWIFT: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		hqCode := headquarterCode(code)
+		if !bic.IsHeadquarter(code) {
+			// Przeniesiony oddział wskazuje centralę innej instytucji.
+			branch, err := codes.GetOrLoad(code, func() (model.SwiftCode, error) {
+				return LoadSwiftCode(dbConn, code)
+			})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				internalError(w, r, "Błąd pobierania danych", err)
 				return
 			}
-			swiftData.Branches = branches
+			if branch.HeadquarterCode != "" {
+				hqCode = bic.Normalize(branch.HeadquarterCode)
+			}
+		}
+		hq, err := codes.GetOrLoad(hqCode, func() (model.SwiftCode, error) {
+			return LoadSwiftCode(dbConn, hqCode)
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Nie znaleziono centrali", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(swiftData); err != nil {
-			http.Error(w, "Błąd podczas kodowania odpowiedzi", http.StatusInternalServerError)
+		if notModified(w, r, recordETag(media, hq), lastModified(hq)) {
 			return
 		}
+		render(w, r, media, hq)
 	}
 }
 
 func GetSwiftCodesByCountryHandler(dbConn *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
 		vars := mux.Vars(r)
 		countryISO2 := strings.ToUpper(vars["countryISO2code"])
 
 		swiftCodes, err := db.GetSwiftCodesByCountry(dbConn, countryISO2)
 		if err != nil {
-			http.Error(w, "Błąd pobierania danych", http.StatusInternalServerError)
+			internalError(w, r, "Błąd pobierania danych", err)
 			return
 		}
 
-		countryName := ""
-		if len(swiftCodes) > 0 {
+		// Kraj spoza rejestru ISO 3166 istnieje tylko wtedy, gdy ma rekordy
+		// w bazie (np. dane wczytane przed wprowadzeniem walidacji).
+		countryName := country.Name(countryISO2)
+		if countryName == "" {
+			if len(swiftCodes) == 0 {
+				http.Error(w, "Nieznany kod kraju", http.StatusNotFound)
+				return
+			}
 			countryName = swiftCodes[0].CountryName
 		}
+		if swiftCodes == nil {
+			swiftCodes = []model.SwiftCode{}
+		}
+
+		if notModified(w, r, computeETag(media, swiftCodes...), lastModified(swiftCodes...)) {
+			return
+		}
 
-		response := struct {
-			CountryISO2 string             `json:"countryISO2"`
-			CountryName string             `json:"countryName"`
-			SwiftCodes  []model.SwiftCode  `json:"swiftCodes"`
-		}{
+		response := model.CountrySwiftCodes{
 			CountryISO2: countryISO2,
 			CountryName: countryName,
 			SwiftCodes:  swiftCodes,
 		}
+		render(w, r, media, response)
+	}
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Błąd podczas kodowania odpowiedzi", http.StatusInternalServerError)
+// NormalizeSwiftCode ujednolica zapis pól; nazwa kraju pochodzi z rejestru
+// ISO 3166, jeśli kod kraju jest znany.
+func NormalizeSwiftCode(sc *model.SwiftCode) {
+	sc.CountryISO2 = strings.ToUpper(strings.TrimSpace(sc.CountryISO2))
+	sc.CountryName = strings.ToUpper(strings.TrimSpace(sc.CountryName))
+	if name := country.Name(sc.CountryISO2); name != "" {
+		sc.CountryName = name
+	}
+	sc.BankName = strings.ToUpper(strings.TrimSpace(sc.BankName))
+	sc.SwiftCode = bic.Canonical(sc.SwiftCode)
+	sc.Address = strings.TrimSpace(sc.Address)
+}
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 500
+)
+
+func SearchSwiftCodesHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
 			return
 		}
+		phrase := strings.TrimSpace(r.URL.Query().Get("q"))
+		if len([]rune(phrase)) < 2 {
+			http.Error(w, "Parametr q musi mieć co najmniej 2 znaki", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultSearchLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxSearchLimit {
+				http.Error(w, fmt.Sprintf("Parametr limit musi być liczbą od 1 do %d", maxSearchLimit), http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		swiftCodes, err := db.SearchSwiftCodes(dbConn, phrase, limit)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+
+		response := model.SearchResult{
+			Query:      phrase,
+			SwiftCodes: swiftCodes,
+		}
+		render(w, r, media, response)
 	}
 }
 
-func CreateSwiftCodeHandler(dbConn *sql.DB) http.HandlerFunc {
+func CreateSwiftCodeHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var newSwift model.SwiftCode
 
@@ -84,45 +300,188 @@ func CreateSwiftCodeHandler(dbConn *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		newSwift.CountryISO2 = strings.ToUpper(strings.TrimSpace(newSwift.CountryISO2))
-		newSwift.CountryName = strings.ToUpper(strings.TrimSpace(newSwift.CountryName))
-		newSwift.BankName = strings.ToUpper(strings.TrimSpace(newSwift.BankName))
-		newSwift.SwiftCode = strings.TrimSpace(newSwift.SwiftCode)
-		newSwift.Address = strings.TrimSpace(newSwift.Address)
+		NormalizeSwiftCode(&newSwift)
+		if errs := ValidateSwiftCode(newSwift); len(errs) > 0 {
+			http.Error(w, ValidationMessage(errs), http.StatusBadRequest)
+			return
+		}
 
-		if err := db.InsertSwiftCode(dbConn, newSwift); err != nil {
-			http.Error(w, "Nie udało się dodać wpisu", http.StatusInternalServerError)
+		err := db.InsertSwiftCode(dbConn, newSwift)
+		if errors.Is(err, db.ErrDuplicate) {
+			http.Error(w, "Wpis o tym kodzie SWIFT już istnieje; zmień go przez PUT lub PATCH z nagłówkiem If-Match", http.StatusConflict)
 			return
 		}
+		if err != nil {
+			internalError(w, r, "Nie udało się dodać wpisu", err)
+			return
+		}
+		InvalidateSwiftCode(codes, newSwift.SwiftCode)
 
 		response := map[string]string{
 			"message": "Wpis dodany pomyślnie",
 		}
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Błąd podczas kodowania odpowiedzi", http.StatusInternalServerError)
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
 			return
 		}
 	}
 }
 
-func DeleteSwiftCodeHandler(dbConn *sql.DB) http.HandlerFunc {
+// DeleteSwiftCodeHandler usuwa wpis. Centrala z oddziałami jest usuwana
+// zgodnie z parametrem branches, a domyślnie zgodnie z zasadami serwera
+// (policy, jeden z trybów Delete*).
+func DeleteSwiftCodeHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode], policy string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
-		swiftCodeParam := vars["swift-code"]
+		swiftCodeParam := bic.Canonical(vars["swiftCode"])
+
+		mode, ok := deleteMode(w, r, policy)
+		if !ok {
+			return
+		}
+
+		current, err := LoadSwiftCode(dbConn, swiftCodeParam)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Nie znaleziono wpisu", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		if preconditionFailed(w, r, current) {
+			return
+		}
 
-		if err := db.DeleteSwiftCode(dbConn, swiftCodeParam); err != nil {
-			http.Error(w, "Nie udało się usunąć wpisu", http.StatusInternalServerError)
+		message, err := DeleteSwiftCode(dbConn, codes, current, mode, r.URL.Query().Get("newHeadquarter"))
+		if err != nil {
+			writeError(w, r, "Nie udało się usunąć wpisu", err)
 			return
 		}
+		InvalidateSwiftCode(codes, swiftCodeParam)
 
 		response := map[string]string{
-			"message": "Wpis usunięty pomyślnie",
+			"message": message,
 		}
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Błąd podczas kodowania odpowiedzi", http.StatusInternalServerError)
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
 			return
 		}
 	}
 }
+
+// UpdateSwiftCodeHandler zastępuje cały rekord treścią żądania.
+func UpdateSwiftCodeHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		swiftCodeParam := bic.Canonical(vars["swiftCode"])
+
+		var updated model.SwiftCode
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			http.Error(w, "Błędny format danych", http.StatusBadRequest)
+			return
+		}
+		if !validUpdate(w, &updated, swiftCodeParam) {
+			return
+		}
+
+		current, ok := loadForWrite(w, r, dbConn, swiftCodeParam)
+		if !ok {
+			return
+		}
+		writeUpdate(w, r, dbConn, codes, updated, current.Version)
+	}
+}
+
+// PatchSwiftCodeHandler zmienia tylko pola podane w treści żądania (JSON
+// Merge Patch, RFC 7396); pozostałe pola zachowują bieżące wartości.
+func PatchSwiftCodeHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		swiftCodeParam := bic.Canonical(vars["swiftCode"])
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+			http.Error(w, "Obsługiwany typ treści to application/merge-patch+json", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		current, ok := loadForWrite(w, r, dbConn, swiftCodeParam)
+		if !ok {
+			return
+		}
+
+		// Rekord nie ma zagnieżdżonych obiektów do scalania, więc dekodowanie
+		// łatki na kopię bieżącego rekordu nadpisuje dokładnie podane pola.
+		patched := current
+		patched.Branches = nil
+		patched.Headquarter = nil
+		if err := json.NewDecoder(r.Body).Decode(&patched); err != nil {
+			http.Error(w, "Błędny format danych", http.StatusBadRequest)
+			return
+		}
+		if !validUpdate(w, &patched, swiftCodeParam) {
+			return
+		}
+		writeUpdate(w, r, dbConn, codes, patched, current.Version)
+	}
+}
+
+// validUpdate normalizuje i sprawdza rekord zapisywany pod adresem code.
+func validUpdate(w http.ResponseWriter, updated *model.SwiftCode, code string) bool {
+	NormalizeSwiftCode(updated)
+	if updated.SwiftCode == "" {
+		updated.SwiftCode = code
+	}
+	if updated.SwiftCode != code {
+		http.Error(w, "Kod SWIFT w treści nie zgadza się z adresem", http.StatusBadRequest)
+		return false
+	}
+	if errs := ValidateSwiftCode(*updated); len(errs) > 0 {
+		http.Error(w, ValidationMessage(errs), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// loadForWrite wczytuje zmieniany rekord i sprawdza nagłówek If-Match.
+func loadForWrite(w http.ResponseWriter, r *http.Request, dbConn *sql.DB, code string) (model.SwiftCode, bool) {
+	current, err := LoadSwiftCode(dbConn, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Nie znaleziono wpisu", http.StatusNotFound)
+		return current, false
+	}
+	if err != nil {
+		internalError(w, r, "Błąd pobierania danych", err)
+		return current, false
+	}
+	return current, !preconditionFailed(w, r, current)
+}
+
+func writeUpdate(w http.ResponseWriter, r *http.Request, dbConn *sql.DB, codes *cache.Cache[model.SwiftCode], updated model.SwiftCode, expectedVersion int64) {
+	err := db.UpdateSwiftCode(dbConn, updated, expectedVersion)
+	if errors.Is(err, db.ErrConflict) {
+		http.Error(w, "Wpis został zmieniony, pobierz go ponownie", http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		internalError(w, r, "Nie udało się zaktualizować wpisu", err)
+		return
+	}
+	InvalidateSwiftCode(codes, updated.SwiftCode)
+
+	if fresh, err := LoadSwiftCode(dbConn, updated.SwiftCode); err == nil {
+		w.Header().Set("ETag", recordETag(mediaJSON, fresh))
+	}
+
+	response := map[string]string{
+		"message": "Wpis zaktualizowany pomyślnie",
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+		return
+	}
+}
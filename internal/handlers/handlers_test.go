@@ -3,14 +3,19 @@ package handlers
 import (
 	"bytes"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"swift-codes/internal/cache"
 	"swift-codes/internal/db"
 	"swift-codes/internal/model"
 )
@@ -25,11 +30,32 @@ func setupTestServer(t *testing.T) (*mux.Router, *sql.DB) {
 		t.Fatalf("InitDB nie powiodło się: %v", err)
 	}
 
+	codes := cache.New[model.SwiftCode](100, time.Minute)
+
 	router := mux.NewRouter()
-	router.HandleFunc("/v1/swift-codes/{swiftCode}", GetSwiftCodeHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/export", ExportSwiftCodesHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", GetSwiftCodeHandler(testDB, codes)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}/headquarter", GetHeadquarterHandler(testDB, codes)).Methods("GET")
 	router.HandleFunc("/v1/swift-codes/country/{countryISO2code}", GetSwiftCodesByCountryHandler(testDB)).Methods("GET")
-	router.HandleFunc("/v1/swift-codes", CreateSwiftCodeHandler(testDB)).Methods("POST")
-	router.HandleFunc("/v1/swift-codes/{swift-code}", DeleteSwiftCodeHandler(testDB)).Methods("DELETE")
+	router.HandleFunc("/v1/swift-codes", CreateSwiftCodeHandler(testDB, codes)).Methods("POST")
+	router.HandleFunc("/v1/countries", ListCountriesHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/admin/integrity", IntegrityHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/banks", ListBanksHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/banks/{bic8}", GetBankHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/iban/{iban}", GetIBANHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/lookup", LookupSwiftCodesHandler(testDB, 3)).Methods("POST")
+	router.HandleFunc("/v1/swift-codes/batch", BatchSwiftCodesHandler(testDB, codes, 10)).Methods("POST")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", UpdateSwiftCodeHandler(testDB, codes)).Methods("PUT")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", PatchSwiftCodeHandler(testDB, codes)).Methods("PATCH")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", DeleteSwiftCodeHandler(testDB, codes, DeleteRefuse)).Methods("DELETE")
+	router.HandleFunc("/v1/changes", ListChangesHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/changes/stream", StreamChangesHandler(testDB, 10*time.Millisecond)).Methods("GET")
+	router.HandleFunc("/v1/webhooks", CreateWebhookHandler(testDB)).Methods("POST")
+	router.HandleFunc("/v1/webhooks", ListWebhooksHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/webhooks/dead-letters", ListDeadLettersHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/webhooks/dead-letters/{id}/retry", RetryDeadLetterHandler(testDB)).Methods("POST")
+	router.HandleFunc("/v1/webhooks/{id}", GetWebhookHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/webhooks/{id}", DeleteWebhookHandler(testDB)).Methods("DELETE")
 
 	return router, testDB
 }
@@ -41,6 +67,21 @@ func clearTable(db *sql.DB, t *testing.T) {
 	}
 }
 
+func getETag(t *testing.T, router *mux.Router, path string) string {
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("GET %s - brak nagłówka ETag", path)
+	}
+	return etag
+}
+
 func TestCreateAndGetSwiftCodeHandler(t *testing.T) {
 	router, testDB := setupTestServer(t)
 	defer testDB.Close()
@@ -52,7 +93,7 @@ func TestCreateAndGetSwiftCodeHandler(t *testing.T) {
 		CountryISO2:   "AT",
 		CountryName:   "TESTLAND",
 		IsHeadquarter: true,
-		SwiftCode:     "APITESTXXX",
+		SwiftCode:     "APITATWWXXX",
 	}
 	payload, err := json.Marshal(testRecord)
 	if err != nil {
@@ -71,7 +112,15 @@ func TestCreateAndGetSwiftCodeHandler(t *testing.T) {
 		t.Errorf("POST - oczekiwano status 200, otrzymano %d", status)
 	}
 
-	req, err = http.NewRequest("GET", "/v1/swift-codes/APITESTXXX", nil)
+	req, _ = http.NewRequest("POST", "/v1/swift-codes", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("Ponowny POST - oczekiwano status 409, otrzymano %d", status)
+	}
+
+	req, err = http.NewRequest("GET", "/v1/swift-codes/APITATWWXXX", nil)
 	if err != nil {
 		t.Fatalf("Błąd tworzenia żądania GET: %v", err)
 	}
@@ -180,6 +229,20 @@ func TestDeleteSwiftCodeHandler(t *testing.T) {
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
+	if status := rr.Code; status != http.StatusPreconditionRequired {
+		t.Errorf("DELETE bez If-Match - oczekiwano status 428, otrzymano %d", status)
+	}
+
+	etag := getETag(t, router, "/v1/swift-codes/DELETESWIFTXXX")
+
+	req, err = http.NewRequest("DELETE", "/v1/swift-codes/DELETESWIFTXXX", nil)
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania DELETE: %v", err)
+	}
+	req.Header.Set("If-Match", etag)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
 	if status := rr.Code; status != http.StatusOK {
 		t.Errorf("DELETE - oczekiwano status 200, otrzymano %d", status)
 	}
@@ -194,3 +257,663 @@ func TestDeleteSwiftCodeHandler(t *testing.T) {
 		t.Errorf("GET po DELETE - oczekiwano status 404, otrzymano %d", status)
 	}
 }
+
+func TestConditionalGetSwiftCodeHandler(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	rec := model.SwiftCode{
+		BankName:      "Etag Bank",
+		Address:       "Etag Address",
+		CountryISO2:   "ET",
+		CountryName:   "Etagland",
+		IsHeadquarter: true,
+		SwiftCode:     "ETAGSWIFTXXX",
+	}
+	if err := db.InsertSwiftCode(testDB, rec); err != nil {
+		t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+	}
+
+	for _, path := range []string{"/v1/swift-codes/ETAGSWIFTXXX", "/v1/swift-codes/country/ET"} {
+		etag := getETag(t, router, path)
+
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+		}
+		req.Header.Set("If-None-Match", etag)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNotModified {
+			t.Errorf("GET %s z If-None-Match - oczekiwano status 304, otrzymano %d", path, status)
+		}
+	}
+}
+
+func TestUpdateSwiftCodeHandler(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	rec := model.SwiftCode{
+		BankName:      "Update Bank",
+		Address:       "Update Address",
+		CountryISO2:   "PL",
+		CountryName:   "POLAND",
+		IsHeadquarter: true,
+		SwiftCode:     "UPDTPLPWXXX",
+	}
+	if err := db.InsertSwiftCode(testDB, rec); err != nil {
+		t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+	}
+	etag := getETag(t, router, "/v1/swift-codes/UPDTPLPWXXX")
+
+	rec.BankName = "UPDATED BANK"
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Błąd kodowania JSON: %v", err)
+	}
+
+	for _, tc := range []struct {
+		ifMatch string
+		status  int
+	}{
+		{etag, http.StatusOK},
+		{etag, http.StatusPreconditionFailed},
+	} {
+		req, err := http.NewRequest("PUT", "/v1/swift-codes/UPDTPLPWXXX", bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania PUT: %v", err)
+		}
+		req.Header.Set("If-Match", tc.ifMatch)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != tc.status {
+			t.Errorf("PUT - oczekiwano status %d, otrzymano %d", tc.status, status)
+		}
+	}
+
+	updated, err := db.GetSwiftCode(testDB, "UPDTPLPWXXX")
+	if err != nil {
+		t.Fatalf("GetSwiftCode nie powiodło się: %v", err)
+	}
+	if updated.BankName != "UPDATED BANK" {
+		t.Errorf("Oczekiwano BankName 'UPDATED BANK', otrzymano %s", updated.BankName)
+	}
+
+	// Zmiana oddziału nie unieważnia ETagu centrali dla zapisu.
+	etag = getETag(t, router, "/v1/swift-codes/UPDTPLPWXXX")
+	if err := db.InsertSwiftCode(testDB, model.SwiftCode{
+		BankName: "UPDATED BANK", Address: "Branch", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "UPDTPLPWKRK",
+	}); err != nil {
+		t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+	}
+
+	req, err := http.NewRequest("PATCH", "/v1/swift-codes/UPDTPLPWXXX", strings.NewReader(`{"address": "Patched Address"}`))
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania PATCH: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", etag)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("PATCH - oczekiwano status 200, otrzymano %d: %s", status, rr.Body.String())
+	}
+
+	patched, err := db.GetSwiftCode(testDB, "UPDTPLPWXXX")
+	if err != nil {
+		t.Fatalf("GetSwiftCode nie powiodło się: %v", err)
+	}
+	if patched.Address != "Patched Address" || patched.BankName != "UPDATED BANK" {
+		t.Errorf("PATCH powinien zmienić tylko adres, otrzymano %+v", patched)
+	}
+}
+
+func TestLookupSwiftCodesHandler(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	for _, rec := range []model.SwiftCode{
+		{BankName: "HQ BANK", Address: "HQ ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "LOOKPLPWXXX"},
+		{BankName: "HQ BANK", Address: "BRANCH ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "LOOKPLPWKRK"},
+	} {
+		if err := db.InsertSwiftCode(testDB, rec); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	payload := []byte(`{"swiftCodes": ["lookplpw", "LOOKPLPWKRK", "MISSPLPWXXX"]}`)
+	req, err := http.NewRequest("POST", "/v1/swift-codes/lookup", bytes.NewBuffer(payload))
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania POST: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("POST lookup - oczekiwano status 200, otrzymano %d", status)
+	}
+
+	var result model.LookupResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	if len(result.Found) != 2 || result.Found[0].SwiftCode != "LOOKPLPWXXX" {
+		t.Errorf("Oczekiwano 2 znalezionych rekordów zaczynając od LOOKPLPWXXX, otrzymano %+v", result.Found)
+	}
+	if len(result.NotFound) != 1 || result.NotFound[0] != "MISSPLPWXXX" {
+		t.Errorf("Oczekiwano nieznalezionego kodu MISSPLPWXXX, otrzymano %v", result.NotFound)
+	}
+
+	payload = []byte(`{"swiftCodes": ["A", "B", "C", "D"]}`)
+	req, err = http.NewRequest("POST", "/v1/swift-codes/lookup", bytes.NewBuffer(payload))
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania POST: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST lookup ponad limit - oczekiwano status 413, otrzymano %d", status)
+	}
+}
+
+func TestBatchSwiftCodesHandler(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	if err := db.InsertSwiftCode(testDB, model.SwiftCode{
+		BankName: "OLD NAME", Address: "ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "BTCHPLPWXXX",
+	}); err != nil {
+		t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+	}
+
+	getReq, _ := http.NewRequest("GET", "/v1/swift-codes/BTCHPLPWXXX", nil)
+	getRR := httptest.NewRecorder()
+	router.ServeHTTP(getRR, getReq)
+	etag := getRR.Header().Get("ETag")
+
+	body := func(ifMatch string) string {
+		return `[
+		{"swiftCode": "btchplpwxxx", "bankName": "New Name", "address": "Address", "countryISO2": "pl", "countryName": "Poland", "isHeadquarter": true, "ifMatch": ` + strconv.Quote(ifMatch) + `},
+		{"swiftCode": "BTCHPLPWKRK", "bankName": "New Name", "address": "Branch", "countryISO2": "PL", "countryName": "POLAND", "isHeadquarter": false},
+		{"swiftCode": "BAD", "bankName": "", "address": "", "countryISO2": "PL", "countryName": "POLAND", "isHeadquarter": false}
+	]`
+	}
+
+	post := func(mode, ifMatch string) (int, model.BatchResult) {
+		req, err := http.NewRequest("POST", "/v1/swift-codes/batch?mode="+mode, strings.NewReader(body(ifMatch)))
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania POST: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var result model.BatchResult
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+		}
+		return rr.Code, result
+	}
+
+	status, result := post("atomic", "")
+	if status != http.StatusUnprocessableEntity {
+		t.Errorf("atomic - oczekiwano status 422, otrzymano %d", status)
+	}
+	if result.Items[0].Status != "conflict" || result.Items[1].Status != "rolledBack" || result.Items[2].Status != "invalid" {
+		t.Errorf("atomic - nieoczekiwane statusy %+v", result.Items)
+	}
+	if _, err := db.GetSwiftCode(testDB, "BTCHPLPWKRK"); err == nil {
+		t.Error("atomic - oczekiwano, że żaden rekord nie zostanie zapisany")
+	}
+
+	status, result = post("best-effort", etag)
+	if status != http.StatusOK {
+		t.Errorf("best-effort - oczekiwano status 200, otrzymano %d", status)
+	}
+	want := []string{"updated", "created", "invalid"}
+	for i, item := range result.Items {
+		if item.Status != want[i] {
+			t.Errorf("best-effort - rekord %d: oczekiwano statusu %s, otrzymano %s", i, want[i], item.Status)
+		}
+	}
+	if result.Succeeded != 2 || result.Failed != 1 {
+		t.Errorf("best-effort - oczekiwano 2 sukcesów i 1 błędu, otrzymano %d i %d", result.Succeeded, result.Failed)
+	}
+
+	updated, err := db.GetSwiftCode(testDB, "BTCHPLPWXXX")
+	if err != nil {
+		t.Fatalf("GetSwiftCode nie powiodło się: %v", err)
+	}
+	if updated.BankName != "NEW NAME" {
+		t.Errorf("Oczekiwano BankName 'NEW NAME', otrzymano %s", updated.BankName)
+	}
+
+	// Ponowne wysłanie tej samej porcji niczego nie nadpisuje.
+	_, result = post("best-effort", etag)
+	if result.Items[0].Status != "conflict" || result.Items[1].Status != "conflict" {
+		t.Errorf("Ponowny zapis - oczekiwano konfliktów, otrzymano %+v", result.Items)
+	}
+
+	// Kod powtórzony w partii odrzuca dopiero baza; wynik wskazuje rekord.
+	duplicated := `[
+		{"swiftCode": "BTCHPLPWGDA", "bankName": "New Name", "address": "Branch", "countryISO2": "PL", "countryName": "POLAND", "isHeadquarter": false},
+		{"swiftCode": "btchplpwgda", "bankName": "New Name", "address": "Branch", "countryISO2": "PL", "countryName": "POLAND", "isHeadquarter": false}
+	]`
+	req, _ := http.NewRequest("POST", "/v1/swift-codes/batch?mode=atomic", strings.NewReader(duplicated))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Powtórzony kod - oczekiwano status 422, otrzymano %d", rr.Code)
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	if result.Items[0].Status != "rolledBack" || result.Items[1].Status != "conflict" {
+		t.Errorf("Powtórzony kod - nieoczekiwane statusy %+v", result.Items)
+	}
+	if _, err := db.GetSwiftCode(testDB, "BTCHPLPWGDA"); err == nil {
+		t.Error("Powtórzony kod - oczekiwano, że żaden rekord nie zostanie zapisany")
+	}
+}
+
+func TestExportSwiftCodesHandler(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	for _, sc := range []model.SwiftCode{
+		{BankName: "EXPORT BANK", Address: "ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "EXPTPLPWXXX"},
+		{BankName: "EXPORT BANK", Address: "BRANCH", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "EXPTPLPWKRK"},
+	} {
+		if err := db.InsertSwiftCode(testDB, sc); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	export := func(format string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/v1/swift-codes/export?format="+format, nil)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := export("csv")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("csv - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+	rows, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("csv - błąd odczytu: %v", err)
+	}
+	if len(rows) != 3 || rows[1][1] != "EXPTPLPWKRK" || rows[2][2] != "BIC11" {
+		t.Errorf("csv - oczekiwano nagłówka i 2 rekordów, otrzymano %v", rows)
+	}
+
+	rr = export("ndjson")
+	if lines := strings.Count(rr.Body.String(), "\n"); lines != 2 {
+		t.Errorf("ndjson - oczekiwano 2 linii, otrzymano %d", lines)
+	}
+	if !strings.HasPrefix(rr.Header().Get("Content-Disposition"), "attachment;") {
+		t.Errorf("ndjson - nieoczekiwany nagłówek Content-Disposition %q", rr.Header().Get("Content-Disposition"))
+	}
+
+	rr = export("xml")
+	var doc struct {
+		Codes []model.SwiftCode `xml:"swiftCodeEntry"`
+	}
+	if err := xml.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("xml - błąd dekodowania: %v", err)
+	}
+	if len(doc.Codes) != 2 || doc.Codes[0].SwiftCode != "EXPTPLPWKRK" {
+		t.Errorf("xml - nieoczekiwane rekordy %+v", doc.Codes)
+	}
+
+	if rr = export("yaml"); rr.Code != http.StatusBadRequest {
+		t.Errorf("yaml - oczekiwano status 400, otrzymano %d", rr.Code)
+	}
+}
+
+func TestCountriesHandlers(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	for _, sc := range []model.SwiftCode{
+		{BankName: "COUNTRY BANK", Address: "ADDRESS", CountryISO2: "PL", CountryName: "Polska", IsHeadquarter: true, SwiftCode: "CTRYPLPWXXX"},
+		{BankName: "COUNTRY BANK", Address: "BRANCH", CountryISO2: "PL", CountryName: "Polska", IsHeadquarter: false, SwiftCode: "CTRYPLPWKRK"},
+		{BankName: "LEGACY BANK", Address: "ADDRESS", CountryISO2: "AA", CountryName: "CountryA", IsHeadquarter: true, SwiftCode: "LGCYAAAAXXX"},
+	} {
+		if err := db.InsertSwiftCode(testDB, sc); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/v1/countries")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /v1/countries - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+	var list model.CountryList
+	if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	want := []model.CountrySummary{
+		{CountryISO2: "AA", CountryName: "CountryA", Headquarters: 1, Branches: 0},
+		{CountryISO2: "PL", CountryName: "POLAND", Headquarters: 1, Branches: 1},
+	}
+	if len(list.Countries) != len(want) {
+		t.Fatalf("Oczekiwano %d krajów, otrzymano %+v", len(want), list.Countries)
+	}
+	for i, c := range list.Countries {
+		if c.CountryISO2 != want[i].CountryISO2 || c.CountryName != want[i].CountryName ||
+			c.Headquarters != want[i].Headquarters || c.Branches != want[i].Branches {
+			t.Errorf("Kraj %d: oczekiwano %+v, otrzymano %+v", i, want[i], c)
+		}
+	}
+
+	if rr := get("/v1/swift-codes/country/ZZ"); rr.Code != http.StatusNotFound {
+		t.Errorf("Nieznany kraj - oczekiwano status 404, otrzymano %d", rr.Code)
+	}
+
+	rr = get("/v1/swift-codes/country/DE")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Kraj bez rekordów - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+	var germany model.CountrySwiftCodes
+	if err := json.NewDecoder(rr.Body).Decode(&germany); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	if germany.CountryName != "GERMANY" || germany.SwiftCodes == nil || len(germany.SwiftCodes) != 0 {
+		t.Errorf("Oczekiwano pustej listy dla GERMANY, otrzymano %+v", germany)
+	}
+}
+
+func TestBankHandlers(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	for _, sc := range []model.SwiftCode{
+		{BankName: "BANK ONE", Address: "ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "BANKPLPWXXX"},
+		{BankName: "BANK ONE", Address: "BRANCH", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "BANKPLPWKRK"},
+		{BankName: "BANK ONE", Address: "BRANCH", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "BANKPLPWWAW"},
+		{BankName: "BANK TWO", Address: "BRANCH", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "BNKTPLPWGDA"},
+	} {
+		if err := db.InsertSwiftCode(testDB, sc); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/v1/banks/bankplpw")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /v1/banks/{bic8} - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+	var bank model.Bank
+	if err := json.NewDecoder(rr.Body).Decode(&bank); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	if bank.Headquarter == nil || bank.Headquarter.SwiftCode != "BANKPLPWXXX" || len(bank.Branches) != 2 {
+		t.Errorf("Oczekiwano centrali z 2 oddziałami, otrzymano %+v", bank)
+	}
+
+	if rr := get("/v1/banks/NONEPLPW"); rr.Code != http.StatusNotFound {
+		t.Errorf("Nieznany bank - oczekiwano status 404, otrzymano %d", rr.Code)
+	}
+	if rr := get("/v1/banks/BANK"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Zły kod BIC8 - oczekiwano status 400, otrzymano %d", rr.Code)
+	}
+
+	rr = get("/v1/banks?country=pl")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /v1/banks - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+	var list model.BankList
+	if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	if len(list.Banks) != 2 || list.Banks[0].Branches != 2 || !list.Banks[0].HasHeadquarter || list.Banks[1].HasHeadquarter {
+		t.Errorf("Nieoczekiwana lista banków %+v", list.Banks)
+	}
+}
+
+func TestHeadquarterResolution(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	for _, rec := range []model.SwiftCode{
+		{BankName: "PARENT BANK", Address: "HQ ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "PRNTPLPWXXX"},
+		{BankName: "PARENT BANK", Address: "BRANCH ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "PRNTPLPWKRK"},
+	} {
+		if err := db.InsertSwiftCode(testDB, rec); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	get := func(path string) (int, model.SwiftCode) {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var sc model.SwiftCode
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&sc); err != nil {
+				t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+			}
+		}
+		return rr.Code, sc
+	}
+
+	status, branch := get("/v1/swift-codes/PRNTPLPWKRK")
+	if status != http.StatusOK || branch.Headquarter == nil || branch.Headquarter.SwiftCode != "PRNTPLPWXXX" || branch.Headquarter.BankName != "PARENT BANK" {
+		t.Errorf("Oddział - oczekiwano odnośnika do centrali PRNTPLPWXXX, otrzymano %d %+v", status, branch.Headquarter)
+	}
+
+	for _, path := range []string{"/v1/swift-codes/PRNTPLPWKRK/headquarter", "/v1/swift-codes/prntplpwwaw/headquarter"} {
+		if status, hq := get(path); status != http.StatusOK || hq.SwiftCode != "PRNTPLPWXXX" || len(hq.Branches) != 1 {
+			t.Errorf("%s - oczekiwano centrali z 1 oddziałem, otrzymano %d %+v", path, status, hq)
+		}
+	}
+	if status, _ := get("/v1/swift-codes/NONEPLPWKRK/headquarter"); status != http.StatusNotFound {
+		t.Errorf("Brak centrali - oczekiwano status 404, otrzymano %d", status)
+	}
+
+	if status, _ := get("/v1/swift-codes/PRNTPLPWWAW"); status != http.StatusNotFound {
+		t.Errorf("Bez fallback - oczekiwano status 404, otrzymano %d", status)
+	}
+	status, hq := get("/v1/swift-codes/PRNTPLPWWAW?fallback=headquarter")
+	if status != http.StatusOK || hq.SwiftCode != "PRNTPLPWXXX" || !hq.HeadquarterFallback || hq.RequestedSwiftCode != "PRNTPLPWWAW" {
+		t.Errorf("Fallback - oczekiwano centrali oznaczonej flagą, otrzymano %d %+v", status, hq)
+	}
+
+	req, err := http.NewRequest("POST", "/v1/swift-codes/lookup?fallback=headquarter", strings.NewReader(`{"swiftCodes": ["PRNTPLPWWAW", "NONEPLPWKRK"]}`))
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania POST: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var result model.LookupResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	if len(result.Found) != 1 || !result.Found[0].HeadquarterFallback || result.Found[0].RequestedSwiftCode != "PRNTPLPWWAW" {
+		t.Errorf("Lookup z fallback - nieoczekiwane znalezione rekordy %+v", result.Found)
+	}
+	if len(result.NotFound) != 1 || result.NotFound[0] != "NONEPLPWKRK" {
+		t.Errorf("Lookup z fallback - oczekiwano nieznalezionego NONEPLPWKRK, otrzymano %v", result.NotFound)
+	}
+}
+
+func TestNormalizedSwiftCodeInputs(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	// Oddział zapisany w starszej postaci (małe litery, spacje) omija
+	// normalizację handlerów, ale nadal musi być znajdowany.
+	for _, rec := range []model.SwiftCode{
+		{BankName: "NORM BANK", Address: "HQ ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "NORMPLPWXXX"},
+		{BankName: "NORM BANK", Address: "BRANCH ADDRESS", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "normplpw krk"},
+	} {
+		if err := db.InsertSwiftCode(testDB, rec); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	get := func(path string) (int, model.SwiftCode) {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var sc model.SwiftCode
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&sc); err != nil {
+				t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+			}
+		}
+		return rr.Code, sc
+	}
+
+	for _, path := range []string{"/v1/swift-codes/normplpw", "/v1/swift-codes/NORM%20PLPW", "/v1/swift-codes/normplpwxxx"} {
+		if status, hq := get(path); status != http.StatusOK || hq.SwiftCode != "NORMPLPWXXX" || len(hq.Branches) != 1 {
+			t.Errorf("%s - oczekiwano centrali NORMPLPWXXX z 1 oddziałem, otrzymano %d %+v", path, status, hq)
+		}
+	}
+	if status, _ := get("/v1/swift-codes/NORMPLPWKRK"); status != http.StatusOK {
+		t.Errorf("Oddział w starszej postaci - oczekiwano status 200, otrzymano %d", status)
+	}
+	if status, bank := get("/v1/banks/normplpwkrk"); status != http.StatusOK {
+		t.Errorf("Bank po kodzie BIC11 - oczekiwano status 200, otrzymano %d %+v", status, bank)
+	}
+
+	payload := []byte(`{"swiftCodes": [" normplpw ", "NORM PLPW KRK"]}`)
+	req, err := http.NewRequest("POST", "/v1/swift-codes/lookup", bytes.NewBuffer(payload))
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania POST: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var result model.LookupResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+	}
+	if len(result.Found) != 2 || len(result.NotFound) != 0 {
+		t.Errorf("Oczekiwano 2 znalezionych rekordów, otrzymano %+v", result)
+	}
+}
+
+func TestDeleteHeadquarterWithBranches(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	for _, rec := range []model.SwiftCode{
+		{BankName: "OLD BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "OLDBPLPWXXX"},
+		{BankName: "OLD BANK", Address: "BRANCH", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "OLDBPLPWKRK"},
+		{BankName: "NEW BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "NEWBPLPWXXX"},
+		{BankName: "GONE BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "GONEPLPWXXX"},
+		{BankName: "GONE BANK", Address: "BRANCH", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "GONEPLPWKRK"},
+	} {
+		if err := db.InsertSwiftCode(testDB, rec); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+
+	del := func(code, query string) int {
+		etag := getETag(t, router, "/v1/swift-codes/"+code)
+		req, err := http.NewRequest("DELETE", "/v1/swift-codes/"+code+query, nil)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania DELETE: %v", err)
+		}
+		req.Header.Set("If-Match", etag)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if status := del("OLDBPLPWXXX", ""); status != http.StatusConflict {
+		t.Errorf("refuse - oczekiwano status 409, otrzymano %d", status)
+	}
+	if status := del("OLDBPLPWXXX", "?branches=orphan"); status != http.StatusBadRequest {
+		t.Errorf("orphan przy zasadzie refuse - oczekiwano status 400, otrzymano %d", status)
+	}
+
+	if status := del("GONEPLPWXXX", "?branches=cascade"); status != http.StatusOK {
+		t.Errorf("cascade - oczekiwano status 200, otrzymano %d", status)
+	}
+	if _, err := db.GetSwiftCode(testDB, "GONEPLPWKRK"); err == nil {
+		t.Error("cascade - oczekiwano usunięcia oddziału")
+	}
+
+	if status := del("OLDBPLPWXXX", "?branches=reparent&newHeadquarter=NEWBPLPWXXX"); status != http.StatusOK {
+		t.Errorf("reparent - oczekiwano status 200, otrzymano %d", status)
+	}
+	if _, err := db.GetSwiftCode(testDB, "NEWBPLPWKRK"); err == nil {
+		t.Error("reparent - przeniesienie nie może tworzyć nowych kodów")
+	}
+	moved, err := LoadSwiftCode(testDB, "OLDBPLPWKRK")
+	if err != nil {
+		t.Fatalf("reparent - oczekiwano oddziału OLDBPLPWKRK: %v", err)
+	}
+	if moved.BankName != "OLD BANK" || moved.Headquarter == nil || moved.Headquarter.SwiftCode != "NEWBPLPWXXX" {
+		t.Errorf("reparent - oczekiwano niezmienionego oddziału z centralą NEWBPLPWXXX, otrzymano %+v", moved)
+	}
+	newHQ, err := LoadSwiftCode(testDB, "NEWBPLPWXXX")
+	if err != nil || len(newHQ.Branches) != 1 || newHQ.Branches[0].SwiftCode != "OLDBPLPWKRK" {
+		t.Errorf("reparent - oczekiwano oddziału OLDBPLPWKRK w nowej centrali, otrzymano %+v (%v)", newHQ, err)
+	}
+
+	req, err := http.NewRequest("GET", "/v1/admin/integrity", nil)
+	if err != nil {
+		t.Fatalf("Błąd tworzenia żądania GET: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var report model.IntegrityReport
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("Błąd dekodowania raportu: %v", err)
+	}
+	if report.Checked != 2 || report.Issues() != 0 {
+		t.Errorf("Oczekiwano spójnego katalogu z 2 rekordami, otrzymano %+v", report)
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/cache"
+	"swift-codes/internal/db"
+	"swift-codes/internal/integrity"
+	"swift-codes/internal/model"
+)
+
+// Tryby usuwania centrali, która ma oddziały.
+const (
+	DeleteOrphan   = "orphan"   // usuwa tylko centralę, oddziały zostają bez niej
+	DeleteRefuse   = "refuse"   // odmawia usunięcia (409)
+	DeleteCascade  = "cascade"  // usuwa centralę razem z oddziałami
+	DeleteReparent = "reparent" // wiąże oddziały z inną centralą
+)
+
+func ValidDeleteMode(mode string) bool {
+	switch mode {
+	case DeleteOrphan, DeleteRefuse, DeleteCascade, DeleteReparent:
+		return true
+	}
+	return false
+}
+
+// RequestError to błąd po stronie klienta wraz ze statusem HTTP, którym
+// należy odpowiedzieć. Pozostałe błędy oznaczają awarię serwera.
+type RequestError struct {
+	Status  int
+	Message string
+}
+
+func (e *RequestError) Error() string { return e.Message }
+
+func requestError(status int, format string, args ...any) error {
+	return &RequestError{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// ResolveDeleteMode wybiera tryb usuwania: requested (pusty oznacza zasady
+// serwera) albo policy. Osierocenie oddziałów jest możliwe tylko wtedy, gdy
+// pozwalają na to zasady serwera.
+func ResolveDeleteMode(requested, policy string) (string, error) {
+	if requested == "" {
+		return policy, nil
+	}
+	if !ValidDeleteMode(requested) {
+		return "", requestError(http.StatusBadRequest, "Parametr branches musi mieć wartość refuse, cascade, reparent lub orphan")
+	}
+	if requested == DeleteOrphan && policy != DeleteOrphan {
+		return "", requestError(http.StatusBadRequest, "Zasady serwera nie pozwalają pozostawić oddziałów bez centrali")
+	}
+	return requested, nil
+}
+
+// deleteMode odczytuje parametr branches.
+func deleteMode(w http.ResponseWriter, r *http.Request, policy string) (string, bool) {
+	mode, err := ResolveDeleteMode(r.URL.Query().Get("branches"), policy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	return mode, true
+}
+
+// writeError odpowiada statusem z RequestError, a w przypadku innych błędów
+// zapisuje je w logu i odpowiada 500 z komunikatem message.
+func writeError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		http.Error(w, reqErr.Message, reqErr.Status)
+		return
+	}
+	internalError(w, r, message, err)
+}
+
+// DeleteSwiftCode usuwa wczytany wcześniej wpis (current, z oddziałami)
+// zgodnie z trybem mode i zwraca komunikat dla klienta. Wpis musi mieć nadal
+// wersję current.Version. newHeadquarter jest wymagany w trybie reparent.
+// Cache jest czyszczony dla oddziałów i centrali, do której trafiły; sam
+// usuwany wpis czyści wywołujący.
+func DeleteSwiftCode(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode], current model.SwiftCode, mode, newHeadquarter string) (string, error) {
+	if len(current.Branches) == 0 {
+		mode = DeleteOrphan
+	}
+	switch mode {
+	case DeleteRefuse:
+		return "", requestError(http.StatusConflict, "Centrala ma %d oddziałów; użyj branches=cascade lub branches=reparent", len(current.Branches))
+
+	case DeleteCascade:
+		deleted, err := db.DeleteHeadquarterCascade(dbConn, current.SwiftCode, current.Version)
+		if err != nil {
+			return "", deleteFailed(err)
+		}
+		invalidateBranches(codes, current)
+		return fmt.Sprintf("Wpis usunięty pomyślnie razem z %d oddziałami", deleted), nil
+
+	case DeleteReparent:
+		target := bic.Canonical(newHeadquarter)
+		if bic.Validate(target) != nil || !bic.IsHeadquarter(target) || len(target) != 11 {
+			return "", requestError(http.StatusBadRequest, "Parametr newHeadquarter musi być kodem centrali (BIC11 zakończonym na XXX)")
+		}
+		if db.BIC8(target) == db.BIC8(current.SwiftCode) {
+			return "", requestError(http.StatusBadRequest, "Nowa centrala musi należeć do innej instytucji")
+		}
+		moved, err := db.ReparentBranches(dbConn, current.SwiftCode, current.Version, target)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", requestError(http.StatusUnprocessableEntity, "Nowa centrala nie istnieje")
+		}
+		if err != nil {
+			return "", deleteFailed(err)
+		}
+		invalidateBranches(codes, current)
+		InvalidateSwiftCode(codes, target)
+		return fmt.Sprintf("Wpis usunięty pomyślnie, %d oddziałów przeniesiono do %s", len(moved), target), nil
+	}
+
+	if err := db.DeleteSwiftCodeVersion(dbConn, current.SwiftCode, current.Version); err != nil {
+		return "", deleteFailed(err)
+	}
+	return "Wpis usunięty pomyślnie", nil
+}
+
+// invalidateBranches usuwa z cache oddziały centrali hq, które mogą należeć do
+// innych banków niż ona.
+func invalidateBranches(codes *cache.Cache[model.SwiftCode], hq model.SwiftCode) {
+	for _, branch := range hq.Branches {
+		InvalidateSwiftCode(codes, branch.SwiftCode)
+	}
+}
+
+func deleteFailed(err error) error {
+	if errors.Is(err, db.ErrConflict) {
+		return requestError(http.StatusPreconditionFailed, "Wpis został zmieniony, pobierz go ponownie")
+	}
+	return err
+}
+
+// IntegrityHandler sprawdza cały katalog: osierocone oddziały, oddziały
+// w innym kraju niż centrala oraz kody różniące się tylko wielkością liter
+// lub białymi znakami.
+func IntegrityHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checker := integrity.NewChecker()
+		err := db.StreamSwiftCodes(r.Context(), dbConn, exportBatchSize, func(sc model.SwiftCode) error {
+			checker.Add(sc)
+			return nil
+		})
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(checker.Report()); err != nil {
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+			return
+		}
+	}
+}
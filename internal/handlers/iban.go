@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/db"
+	"swift-codes/internal/iban"
+	"swift-codes/internal/model"
+
+	"github.com/gorilla/mux"
+)
+
+// GetIBANHandler sprawdza numer IBAN i zwraca kody SWIFT banku wskazane przez
+// tabelę krajowych identyfikatorów banków. Poprawny numer bez pasującego
+// wpisu w tabeli daje pustą listę swiftCodes.
+func GetIBANHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
+		number, err := iban.Parse(iban.Normalize(mux.Vars(r)["iban"]))
+		if err != nil {
+			http.Error(w, "Nieprawidłowy numer IBAN: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result := model.IBANResult{
+			IBAN:        number.Number,
+			Formatted:   number.Formatted(),
+			CountryISO2: number.Country,
+			CheckDigits: number.CheckDigits,
+			BBAN:        number.BBAN,
+			BankCode:    number.BankCode,
+			BranchCode:  number.BranchCode,
+			SwiftCodes:  []model.SwiftCode{},
+		}
+		bankCodes, err := db.FindBankCodes(dbConn, number.Country, number.NationalID())
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		if len(bankCodes) > 0 {
+			result.MatchedBankCode = bankCodes[0].BankCode
+			bic8s := make([]string, len(bankCodes))
+			for i, bc := range bankCodes {
+				bic8s[i] = db.BIC8(bc.SwiftCode)
+			}
+			records, err := db.GetSwiftCodesByBIC8s(dbConn, bic8s)
+			if err != nil {
+				internalError(w, r, "Błąd pobierania danych", err)
+				return
+			}
+			result.SwiftCodes = append(result.SwiftCodes, matchBankCodes(bankCodes, records)...)
+		}
+		render(w, r, media, result)
+	}
+}
+
+// matchBankCodes wybiera z records (kodów instytucji z tabeli) rekordy
+// wskazane przez wpisy tabeli: dla kodu BIC11 ten kod, a dla BIC8 centralę
+// albo, gdy katalog jej nie zawiera, wszystkie kody instytucji.
+func matchBankCodes(bankCodes []model.BankCode, records []model.SwiftCode) []model.SwiftCode {
+	byCode := make(map[string]model.SwiftCode, len(records))
+	for _, sc := range records {
+		byCode[bic.Normalize(sc.SwiftCode)] = sc
+	}
+	var matched []model.SwiftCode
+	seen := map[string]bool{}
+	add := func(sc model.SwiftCode) {
+		if code := bic.Normalize(sc.SwiftCode); !seen[code] {
+			seen[code] = true
+			matched = append(matched, sc)
+		}
+	}
+	for _, bc := range bankCodes {
+		if sc, ok := byCode[bic.Canonical(bc.SwiftCode)]; ok {
+			add(sc)
+			continue
+		}
+		if len(bc.SwiftCode) != 8 {
+			continue
+		}
+		for _, sc := range records {
+			if db.BIC8(bic.Normalize(sc.SwiftCode)) == bc.SwiftCode {
+				add(sc)
+			}
+		}
+	}
+	return matched
+}
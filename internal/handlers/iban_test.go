@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+func TestIBANHandler(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+
+	for _, sc := range []model.SwiftCode{
+		{SwiftCode: "WBKPPLPPXXX", BankName: "SANTANDER BANK POLSKA S.A.", Address: "POZNAN", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{SwiftCode: "WBKPPLPPCBM", BankName: "SANTANDER BANK POLSKA S.A.", Address: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND"},
+	} {
+		if err := db.InsertSwiftCode(testDB, sc); err != nil {
+			t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+		}
+	}
+	err := db.ReplaceBankCodes(testDB, []model.BankCode{
+		{CountryISO2: "PL", BankCode: "109", SwiftCode: "WBKPPLPP"},
+		{CountryISO2: "PL", BankCode: "10901014", SwiftCode: "WBKPPLPPCBM"},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceBankCodes nie powiodło się: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		status  int
+		matched string
+		codes   []string
+	}{
+		{"/v1/iban/PL61109010140000071219812874", http.StatusOK, "10901014", []string{"WBKPPLPPCBM"}},
+		{"/v1/iban/pl36%201090%201015%200000%200712%201981%202874", http.StatusOK, "109", []string{"WBKPPLPPXXX"}},
+		{"/v1/iban/PL61109010140000071219812875", http.StatusBadRequest, "", nil},
+		{"/v1/iban/DE89370400440532013000", http.StatusOK, "", nil},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != tc.status {
+			t.Errorf("GET %s - oczekiwano statusu %d, otrzymano %d (%s)", tc.path, tc.status, rr.Code, rr.Body.String())
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+		var result model.IBANResult
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatalf("Błąd dekodowania odpowiedzi: %v", err)
+		}
+		if result.MatchedBankCode != tc.matched || len(result.SwiftCodes) != len(tc.codes) {
+			t.Errorf("GET %s - oczekiwano wpisu %q i kodów %v, otrzymano %+v", tc.path, tc.matched, tc.codes, result)
+			continue
+		}
+		for i, sc := range result.SwiftCodes {
+			if sc.SwiftCode != tc.codes[i] {
+				t.Errorf("GET %s - oczekiwano kodów %v, otrzymano %+v", tc.path, tc.codes, result.SwiftCodes)
+			}
+		}
+	}
+}
+
+func TestMatchBankCodes(t *testing.T) {
+	records := []model.SwiftCode{
+		{SwiftCode: "BPKOPLPWWAR"},
+		{SwiftCode: "BPKOPLPWXXX", IsHeadquarter: true},
+		{SwiftCode: "NESBPLPWFMB"},
+		{SwiftCode: "NESBPLPWKRK"},
+		{SwiftCode: "BREXPLPWMBK"},
+		{SwiftCode: "BREXPLPWXXX", IsHeadquarter: true},
+	}
+	codes := func(matched []model.SwiftCode) []string {
+		var result []string
+		for _, sc := range matched {
+			result = append(result, sc.SwiftCode)
+		}
+		return result
+	}
+	tests := []struct {
+		name      string
+		bankCodes []string
+		want      []string
+	}{
+		{"BIC8 z centralą", []string{"BPKOPLPW"}, []string{"BPKOPLPWXXX"}},
+		{"BIC8 bez centrali", []string{"NESBPLPW"}, []string{"NESBPLPWFMB", "NESBPLPWKRK"}},
+		{"BIC11 oddziału", []string{"BREXPLPWMBK"}, []string{"BREXPLPWMBK"}},
+		{"kod spoza katalogu", []string{"INGBPLPW"}, nil},
+		{"powtórzony kod", []string{"BPKOPLPW", "BPKOPLPWXXX"}, []string{"BPKOPLPWXXX"}},
+	}
+	for _, tc := range tests {
+		var bankCodes []model.BankCode
+		for _, code := range tc.bankCodes {
+			bankCodes = append(bankCodes, model.BankCode{CountryISO2: "PL", BankCode: "102", SwiftCode: code})
+		}
+		got := codes(matchBankCodes(bankCodes, records))
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: oczekiwano %v, otrzymano %v", tc.name, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: oczekiwano %v, otrzymano %v", tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+}
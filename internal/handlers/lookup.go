@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+// lookupCodeBytes to rozmiar treści żądania przypadający na jeden kod; kod
+// w tablicy JSON zajmuje kilkanaście bajtów.
+const lookupCodeBytes = 64
+
+func LookupSwiftCodesHandler(dbConn *sql.DB, maxBatch int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		media, ok := negotiateMedia(w, r)
+		if !ok {
+			return
+		}
+
+		fallback, ok := wantsHeadquarterFallback(w, r)
+		if !ok {
+			return
+		}
+
+		limit := int64(maxBatch+1) * lookupCodeBytes
+		var request model.LookupRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&request); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, fmt.Sprintf("Maksymalny rozmiar żądania to %d bajtów", limit), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "Błędny format danych", http.StatusBadRequest)
+			return
+		}
+		if len(request.SwiftCodes) == 0 {
+			http.Error(w, "Lista swiftCodes nie może być pusta", http.StatusBadRequest)
+			return
+		}
+		if len(request.SwiftCodes) > maxBatch {
+			http.Error(w, fmt.Sprintf("Maksymalna liczba kodów w jednym żądaniu to %d", maxBatch), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		codes := make([]string, len(request.SwiftCodes))
+		for i, requested := range request.SwiftCodes {
+			codes[i] = bic.Canonical(requested)
+		}
+
+		records, err := db.GetSwiftCodes(dbConn, codes)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		byCode := make(map[string]model.SwiftCode, len(records))
+		for _, sc := range records {
+			byCode[bic.Normalize(sc.SwiftCode)] = sc
+		}
+
+		// Dla nieznanych oddziałów szukamy ich central jednym dodatkowym zapytaniem.
+		fallbacks := map[string]model.SwiftCode{}
+		if fallback {
+			var hqCodes []string
+			for _, code := range codes {
+				if _, ok := byCode[code]; !ok && len(code) == 11 && !bic.IsHeadquarter(code) {
+					hqCodes = append(hqCodes, headquarterCode(code))
+				}
+			}
+			if len(hqCodes) > 0 {
+				headquarters, err := db.GetSwiftCodes(dbConn, hqCodes)
+				if err != nil {
+					internalError(w, r, "Błąd pobierania danych", err)
+					return
+				}
+				for _, hq := range headquarters {
+					fallbacks[bic.Normalize(hq.SwiftCode)] = hq
+				}
+			}
+		}
+
+		response := model.LookupResult{
+			Found:    []model.SwiftCode{},
+			NotFound: []string{},
+		}
+		reported := make(map[string]bool, len(codes))
+		for i, code := range codes {
+			if reported[code] {
+				continue
+			}
+			reported[code] = true
+			if sc, ok := byCode[code]; ok {
+				response.Found = append(response.Found, sc)
+			} else if hq, ok := fallbacks[headquarterCode(code)]; ok {
+				response.Found = append(response.Found, asFallback(hq, code))
+			} else {
+				response.NotFound = append(response.NotFound, request.SwiftCodes[i])
+			}
+		}
+
+		render(w, r, media, response)
+	}
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLookupSwiftCodesHandler_RejectsLargeBody(t *testing.T) {
+	body := `{"swiftCodes": ["` + strings.Repeat("A", 4*lookupCodeBytes) + `"]}`
+	req := httptest.NewRequest("POST", "/v1/swift-codes/lookup", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	LookupSwiftCodesHandler(nil, 3)(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Oczekiwano status 413, otrzymano %d", rr.Code)
+	}
+}
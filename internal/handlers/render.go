@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"swift-codes/internal/model"
+	"swift-codes/internal/parser"
+)
+
+const (
+	mediaJSON = "application/json"
+	mediaXML  = "application/xml"
+	mediaCSV  = "text/csv"
+)
+
+// offeredMedia to obsługiwane typy odpowiedzi w kolejności preferencji
+// serwera; text/xml jest przyjmowany dla starszych klientów.
+var offeredMedia = []string{mediaJSON, mediaXML, "text/xml", mediaCSV}
+
+// negotiate wybiera typ odpowiedzi na podstawie nagłówka Accept (z wagami q).
+// Brak nagłówka oznacza JSON. Zwraca false, jeśli żaden obsługiwany typ nie
+// jest akceptowany.
+func negotiate(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return mediaJSON, true
+	}
+
+	type mediaRange struct {
+		typ, subtype string
+		q            float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	best, bestQ := "", 0.0
+	for _, offered := range offeredMedia {
+		typ, subtype, _ := strings.Cut(offered, "/")
+		// Najbardziej szczegółowy pasujący zakres decyduje o wadze typu.
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			s := -1
+			switch {
+			case r.typ == typ && r.subtype == subtype:
+				s = 2
+			case r.typ == typ && r.subtype == "*":
+				s = 1
+			case r.typ == "*" && r.subtype == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offered, q
+		}
+	}
+	return best, best != ""
+}
+
+// negotiateMedia ustawia nagłówek Vary i odpowiada 406, jeśli klient nie
+// akceptuje żadnego obsługiwanego typu.
+func negotiateMedia(w http.ResponseWriter, r *http.Request) (string, bool) {
+	w.Header().Add("Vary", "Accept")
+	media, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		http.Error(w, "Obsługiwane typy odpowiedzi: "+strings.Join(offeredMedia, ", "), http.StatusNotAcceptable)
+	}
+	return media, ok
+}
+
+// render zapisuje odpowiedź w wynegocjowanym formacie. CSV zawiera płaską
+// listę rekordów (centrale razem z oddziałami) w układzie pliku źródłowego.
+func render(w http.ResponseWriter, r *http.Request, media string, v any) {
+	var err error
+	switch media {
+	case mediaXML, "text/xml":
+		w.Header().Set("Content-Type", media+"; charset=utf-8")
+		err = writeXML(w, v)
+	case mediaCSV:
+		w.Header().Set("Content-Type", mediaCSV+"; charset=utf-8")
+		err = writeCSVBody(w, v)
+	default:
+		w.Header().Set("Content-Type", mediaJSON)
+		err = json.NewEncoder(w).Encode(v)
+	}
+	if err != nil {
+		internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+	}
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func writeCSV(w io.Writer, records []model.SwiftCode) error {
+	cw := parser.NewWriter(w)
+	for _, sc := range records {
+		if err := cw.Write(sc); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+// writeCSVBody zapisuje zestawienia (kraje, banki) jako tabelę, a pozostałe
+// odpowiedzi jako rekordy w układzie pliku źródłowego.
+func writeCSVBody(w io.Writer, v any) error {
+	switch v := v.(type) {
+	case model.CountryList:
+		rows := make([][]string, len(v.Countries))
+		for i, c := range v.Countries {
+			rows[i] = []string{c.CountryISO2, c.CountryName, strconv.Itoa(c.Headquarters), strconv.Itoa(c.Branches)}
+		}
+		return writeTable(w, []string{"countryISO2", "countryName", "headquarters", "branches"}, rows)
+	case model.BankList:
+		rows := make([][]string, len(v.Banks))
+		for i, b := range v.Banks {
+			rows[i] = []string{b.BIC8, b.BankName, strconv.FormatBool(b.HasHeadquarter), strconv.Itoa(b.Branches)}
+		}
+		return writeTable(w, []string{"bic8", "bankName", "hasHeadquarter", "branches"}, rows)
+	}
+	return writeCSV(w, csvRecords(v))
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+func csvRecords(v any) []model.SwiftCode {
+	switch v := v.(type) {
+	case model.SwiftCode:
+		return parser.Flatten([]model.SwiftCode{v})
+	case model.Bank:
+		if v.Headquarter == nil {
+			return v.Branches
+		}
+		return append([]model.SwiftCode{*v.Headquarter}, v.Branches...)
+	case model.CountrySwiftCodes:
+		return v.SwiftCodes
+	case model.SearchResult:
+		return v.SwiftCodes
+	case model.LookupResult:
+		return v.Found
+	case model.IBANResult:
+		return v.SwiftCodes
+	}
+	return nil
+}
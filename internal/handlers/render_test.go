@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"swift-codes/internal/model"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"", mediaJSON, true},
+		{"*/*", mediaJSON, true},
+		{"application/xml", mediaXML, true},
+		{"text/xml", "text/xml", true},
+		{"text/csv", mediaCSV, true},
+		{"text/*", "text/xml", true},
+		{"application/json;q=0.5, text/csv", mediaCSV, true},
+		{"application/xml;q=0.9, */*;q=0.1", mediaXML, true},
+		{"*/*, application/json;q=0", mediaXML, true},
+		{"image/png", "", false},
+		{"application/json;q=0", "", false},
+	}
+
+	for _, tc := range tests {
+		got, ok := negotiate(tc.accept)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("negotiate(%q): oczekiwano (%q, %v), otrzymano (%q, %v)", tc.accept, tc.want, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestNegotiateMedia_NotAcceptable(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/swift-codes/AAISALTRXXX", nil)
+	req.Header.Set("Accept", "application/pdf")
+	rr := httptest.NewRecorder()
+
+	if _, ok := negotiateMedia(rr, req); ok {
+		t.Fatal("Oczekiwano odrzucenia nieobsługiwanego typu")
+	}
+	if rr.Code != http.StatusNotAcceptable {
+		t.Errorf("Oczekiwano status 406, otrzymano %d", rr.Code)
+	}
+	if rr.Header().Get("Vary") != "Accept" {
+		t.Errorf("Oczekiwano nagłówka Vary: Accept, otrzymano %q", rr.Header().Get("Vary"))
+	}
+}
+
+func TestRender(t *testing.T) {
+	hq := model.SwiftCode{
+		BankName:      "UNITED BANK OF ALBANIA SH.A",
+		Address:       "HYRJA 3 RR. DRITAN HOXHA ND. 11 TIRANA, TIRANA, 1023",
+		CountryISO2:   "AL",
+		CountryName:   "ALBANIA",
+		IsHeadquarter: true,
+		SwiftCode:     "AAISALTRXXX",
+		Branches: []model.SwiftCode{
+			{BankName: "UNITED BANK OF ALBANIA SH.A", CountryISO2: "AL", CountryName: "ALBANIA", SwiftCode: "AAISALTR001"},
+		},
+	}
+	req := httptest.NewRequest("GET", "/v1/swift-codes/AAISALTRXXX", nil)
+
+	rr := httptest.NewRecorder()
+	render(rr, req, mediaXML, hq)
+	if ct := rr.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("xml - nieoczekiwany Content-Type %q", ct)
+	}
+	if !strings.Contains(rr.Body.String(), "<branches><swiftCodeEntry>") {
+		t.Errorf("xml - oczekiwano oddziałów zagnieżdżonych w <branches>, otrzymano %s", rr.Body.String())
+	}
+	var decoded model.SwiftCode
+	if err := xml.Unmarshal(rr.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("xml - błąd dekodowania: %v", err)
+	}
+	if decoded.SwiftCode != hq.SwiftCode || !decoded.IsHeadquarter || len(decoded.Branches) != 1 {
+		t.Errorf("xml - nieoczekiwany wynik dekodowania %+v", decoded)
+	}
+
+	rr = httptest.NewRecorder()
+	render(rr, req, mediaCSV, hq)
+	rows, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("csv - błąd odczytu: %v", err)
+	}
+	if len(rows) != 3 || rows[1][1] != "AAISALTRXXX" || rows[2][1] != "AAISALTR001" {
+		t.Errorf("csv - oczekiwano nagłówka, centrali i oddziału, otrzymano %v", rows)
+	}
+
+	rr = httptest.NewRecorder()
+	render(rr, req, mediaJSON, model.CountrySwiftCodes{CountryISO2: "AL", SwiftCodes: []model.SwiftCode{}})
+	if body := strings.TrimSpace(rr.Body.String()); body != `{"countryISO2":"AL","countryName":"","swiftCodes":[]}` {
+		t.Errorf("json - nieoczekiwana treść %s", body)
+	}
+}
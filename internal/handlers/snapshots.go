@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"swift-codes/internal/cache"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+
+	"github.com/gorilla/mux"
+)
+
+func ListSnapshotsHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshots, err := db.ListSnapshots(dbConn)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		writeJSON(w, r, model.SnapshotList{Snapshots: snapshots})
+	}
+}
+
+func GetSnapshotHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, err := db.GetSnapshot(dbConn, mux.Vars(r)["name"])
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Nie znaleziono wersji danych", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		writeJSON(w, r, s)
+	}
+}
+
+// DiffSnapshotsHandler porównuje wersje z parametrów from i to. Bez to
+// porównuje z wersją aktywną.
+func DiffSnapshotsHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+		if from == "" {
+			http.Error(w, "Brak parametru from", http.StatusBadRequest)
+			return
+		}
+		if to == "" {
+			active, err := db.GetActiveSnapshot(dbConn)
+			if err != nil {
+				internalError(w, r, "Błąd pobierania danych", err)
+				return
+			}
+			to = active.Name
+		}
+
+		diff, err := db.DiffSnapshots(dbConn, from, to)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Nie znaleziono wersji danych", http.StatusNotFound)
+		case errors.Is(err, db.ErrSnapshotState):
+			http.Error(w, "Wersja danych jest w trakcie wczytywania", http.StatusConflict)
+		case err != nil:
+			internalError(w, r, "Błąd pobierania danych", err)
+		default:
+			writeJSON(w, r, diff)
+		}
+	}
+}
+
+// ActivateSnapshotHandler przełącza odczyty na wersję z adresu i czyści
+// cache, który mógł zawierać rekordy poprzedniej wersji.
+func ActivateSnapshotHandler(dbConn *sql.DB, codes *cache.Cache[model.SwiftCode]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		force := false
+		if v := r.URL.Query().Get("force"); v != "" {
+			var err error
+			if force, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, "Parametr force musi mieć wartość true lub false", http.StatusBadRequest)
+				return
+			}
+		}
+
+		activation, err := db.ActivateSnapshot(dbConn, mux.Vars(r)["name"], force)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Nie znaleziono wersji danych", http.StatusNotFound)
+		case errors.Is(err, db.ErrSnapshotState):
+			http.Error(w, "Aktywować można tylko wersję gotową lub nieaktywną", http.StatusConflict)
+		case errors.Is(err, db.ErrSnapshotEdits):
+			http.Error(w, "Aktywacja porzuciłaby zmiany wprowadzone przez API w aktywnej wersji: "+err.Error()+
+				"; porównaj wersje i powtórz zmiany albo aktywuj z force=true", http.StatusConflict)
+		case err != nil:
+			internalError(w, r, "Nie udało się aktywować wersji danych", err)
+		default:
+			codes.Purge()
+			writeJSON(w, r, activation)
+		}
+	}
+}
+
+func DeleteSnapshotHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := db.DeleteSnapshot(dbConn, mux.Vars(r)["name"])
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Nie znaleziono wersji danych", http.StatusNotFound)
+		case errors.Is(err, db.ErrSnapshotState):
+			http.Error(w, "Nie można usunąć aktywnej ani wczytywanej wersji danych", http.StatusConflict)
+		case err != nil:
+			internalError(w, r, "Nie udało się usunąć wersji danych", err)
+		default:
+			writeJSON(w, r, map[string]string{"message": "Wersja danych usunięta pomyślnie"})
+		}
+	}
+}
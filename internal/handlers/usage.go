@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+// UsageReportHandler zwraca liczbę przyjętych i odrzuconych żądań każdego
+// klienta z dnia podanego w parametrze date (domyślnie dzisiaj, UTC).
+// dailyQuota to dzienny limit żądań klienta, dołączany do raportu.
+func UsageReportHandler(dbConn *sql.DB, dailyQuota int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		day := r.URL.Query().Get("date")
+		if day == "" {
+			day = time.Now().UTC().Format(time.DateOnly)
+		} else if _, err := time.Parse(time.DateOnly, day); err != nil {
+			http.Error(w, "Parametr date musi mieć format RRRR-MM-DD", http.StatusBadRequest)
+			return
+		}
+
+		usage, err := db.ListAPIUsage(dbConn, day)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		writeJSON(w, r, usageReport(day, dailyQuota, usage))
+	}
+}
+
+// usageReport grupuje statystyki (posortowane po kliencie) według klientów.
+func usageReport(day string, dailyQuota int64, usage []model.APIUsage) model.UsageReport {
+	report := model.UsageReport{Date: day, DailyQuota: dailyQuota, Clients: []model.ClientUsage{}}
+	for _, u := range usage {
+		n := len(report.Clients)
+		if n == 0 || report.Clients[n-1].Client != u.Client {
+			report.Clients = append(report.Clients, model.ClientUsage{Client: u.Client, RouteClasses: map[string]model.RouteClassUsage{}})
+			n++
+		}
+		c := &report.Clients[n-1]
+		c.Requests += u.Requests
+		c.Rejected += u.Rejected
+		c.RouteClasses[u.RouteClass] = model.RouteClassUsage{Requests: u.Requests, Rejected: u.Rejected}
+	}
+	return report
+}
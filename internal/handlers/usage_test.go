@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"swift-codes/internal/model"
+)
+
+func TestUsageReport(t *testing.T) {
+	report := usageReport("2024-05-01", 1000, []model.APIUsage{
+		{Client: "ip:192.0.2.10", RouteClass: "lookup", Requests: 5},
+		{Client: "key:3f2a9c0b17de", RouteClass: "lookup", Requests: 100, Rejected: 2},
+		{Client: "key:3f2a9c0b17de", RouteClass: "write", Requests: 10, Rejected: 1},
+	})
+	if len(report.Clients) != 2 || report.DailyQuota != 1000 {
+		t.Fatalf("Oczekiwano raportu dla 2 klientów, otrzymano %+v", report)
+	}
+	c := report.Clients[1]
+	if c.Client != "key:3f2a9c0b17de" || c.Requests != 110 || c.Rejected != 3 || c.RouteClasses["write"].Requests != 10 {
+		t.Errorf("Nieoczekiwane zliczenia klienta: %+v", c)
+	}
+}
+
+func TestUsageReportHandler_InvalidDate(t *testing.T) {
+	rr := httptest.NewRecorder()
+	UsageReportHandler(nil, 0).ServeHTTP(rr, httptest.NewRequest("GET", "/v1/admin/usage?date=01.05.2024", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Oczekiwano status 400, otrzymano %d", rr.Code)
+	}
+}
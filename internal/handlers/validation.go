@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"strings"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/country"
+	"swift-codes/internal/model"
+)
+
+// ValidateSwiftCode sprawdza rekord po normalizacji. Zwraca wszystkie błędy
+// naraz, żeby klient mógł poprawić rekord w jednym kroku.
+func ValidateSwiftCode(sc model.SwiftCode) []model.FieldError {
+	var errs []model.FieldError
+	if err := bic.Validate(sc.SwiftCode); err != nil {
+		errs = append(errs, model.FieldError{Field: "swiftCode", Message: err.Error()})
+	} else {
+		if sc.IsHeadquarter != bic.IsHeadquarter(sc.SwiftCode) {
+			errs = append(errs, model.FieldError{Field: "isHeadquarter", Message: "centrala musi mieć kod zakończony na XXX, a oddział nie"})
+		}
+		if sc.CountryISO2 != "" && sc.SwiftCode[4:6] != sc.CountryISO2 {
+			errs = append(errs, model.FieldError{Field: "countryISO2", Message: "kod kraju musi zgadzać się ze znakami 5-6 kodu SWIFT"})
+		}
+	}
+	if _, ok := country.Lookup(sc.CountryISO2); !ok {
+		errs = append(errs, model.FieldError{Field: "countryISO2", Message: "nieznany kod kraju ISO 3166-1"})
+	}
+	if sc.CountryName == "" {
+		errs = append(errs, model.FieldError{Field: "countryName", Message: "pole jest wymagane"})
+	}
+	if sc.BankName == "" {
+		errs = append(errs, model.FieldError{Field: "bankName", Message: "pole jest wymagane"})
+	}
+	return errs
+}
+
+func ValidationMessage(errs []model.FieldError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ": " + e.Message
+	}
+	return "Nieprawidłowe dane: " + strings.Join(parts, "; ")
+}
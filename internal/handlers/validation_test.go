@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+
+	"swift-codes/internal/model"
+)
+
+func TestValidateSwiftCode(t *testing.T) {
+	valid := model.SwiftCode{
+		BankName:      "PKO BANK POLSKI S.A.",
+		CountryISO2:   "PL",
+		CountryName:   "POLAND",
+		IsHeadquarter: true,
+		SwiftCode:     "BPKOPLPWXXX",
+	}
+	if errs := ValidateSwiftCode(valid); len(errs) != 0 {
+		t.Errorf("Oczekiwano poprawnego rekordu, otrzymano błędy %v", errs)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(sc *model.SwiftCode)
+		field  string
+	}{
+		{"zły kod", func(sc *model.SwiftCode) { sc.SwiftCode = "BPKO" }, "swiftCode"},
+		{"oddział oznaczony jako centrala", func(sc *model.SwiftCode) { sc.SwiftCode = "BPKOPLPWBIA" }, "isHeadquarter"},
+		{"kraj niezgodny z kodem", func(sc *model.SwiftCode) { sc.CountryISO2 = "DE" }, "countryISO2"},
+		{"kraj spoza ISO 3166", func(sc *model.SwiftCode) { sc.SwiftCode = "BPKOXXPWXXX"; sc.CountryISO2 = "XX" }, "countryISO2"},
+		{"brak nazwy kraju", func(sc *model.SwiftCode) { sc.CountryName = "" }, "countryName"},
+		{"brak nazwy banku", func(sc *model.SwiftCode) { sc.BankName = "" }, "bankName"},
+	}
+	for _, tc := range tests {
+		sc := valid
+		tc.modify(&sc)
+		errs := ValidateSwiftCode(sc)
+		if len(errs) != 1 || errs[0].Field != tc.field {
+			t.Errorf("%s - oczekiwano błędu pola %s, otrzymano %v", tc.name, tc.field, errs)
+		}
+	}
+}
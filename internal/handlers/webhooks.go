@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/country"
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+	"swift-codes/internal/webhook"
+
+	"github.com/gorilla/mux"
+)
+
+// minSecretLength to minimalna długość sekretu podanego przez subskrybenta.
+const minSecretLength = 16
+
+// NormalizeWebhookSubscription ujednolica filtry subskrypcji tak jak kody
+// w katalogu: kod kraju wielkimi literami, a BIC8 jako pierwsze 8 znaków
+// znormalizowanego kodu.
+func NormalizeWebhookSubscription(sub *model.WebhookSubscription) {
+	sub.URL = strings.TrimSpace(sub.URL)
+	sub.CountryISO2 = strings.ToUpper(strings.TrimSpace(sub.CountryISO2))
+	sub.BIC8 = bic.Normalize(sub.BIC8)
+}
+
+// ValidateWebhookSubscription sprawdza subskrypcję po normalizacji.
+func ValidateWebhookSubscription(sub model.WebhookSubscription) []model.FieldError {
+	var errs []model.FieldError
+	if u, err := url.Parse(sub.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, model.FieldError{Field: "url", Message: "wymagany bezwzględny adres http lub https"})
+	}
+	if sub.Secret != "" && len(sub.Secret) < minSecretLength {
+		errs = append(errs, model.FieldError{Field: "secret", Message: "sekret musi mieć co najmniej 16 znaków"})
+	}
+	if sub.CountryISO2 != "" {
+		if _, ok := country.Lookup(sub.CountryISO2); !ok {
+			errs = append(errs, model.FieldError{Field: "countryISO2", Message: "nieznany kod kraju ISO 3166-1"})
+		}
+	}
+	if sub.BIC8 != "" && (len(sub.BIC8) != 8 || bic.Validate(sub.BIC8) != nil) {
+		errs = append(errs, model.FieldError{Field: "bic8", Message: "wymagany 8-znakowy kod BIC8"})
+	}
+	return errs
+}
+
+// CreateWebhookHandler rejestruje subskrypcję. Bez podanego sekretu serwer
+// go losuje; sekret jest zwracany tylko w tej odpowiedzi.
+func CreateWebhookHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var sub model.WebhookSubscription
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			http.Error(w, "Błędny format danych", http.StatusBadRequest)
+			return
+		}
+		NormalizeWebhookSubscription(&sub)
+		if errs := ValidateWebhookSubscription(sub); len(errs) > 0 {
+			http.Error(w, ValidationMessage(errs), http.StatusBadRequest)
+			return
+		}
+		if sub.Secret == "" {
+			sub.Secret = webhook.NewSecret()
+		}
+
+		created, err := db.CreateWebhookSubscription(dbConn, sub)
+		if err != nil {
+			internalError(w, r, "Nie udało się dodać subskrypcji", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(created); err != nil {
+			internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+			return
+		}
+	}
+}
+
+func ListWebhooksHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subs, err := db.ListWebhookSubscriptions(dbConn)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		writeJSON(w, r, map[string]any{"webhooks": subs})
+	}
+}
+
+func GetWebhookHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		sub, err := db.GetWebhookSubscription(dbConn, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Nie znaleziono subskrypcji", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		writeJSON(w, r, sub)
+	}
+}
+
+// DeleteWebhookHandler usuwa subskrypcję razem z jej niedoręczonymi
+// zdarzeniami.
+func DeleteWebhookHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		err := db.DeleteWebhookSubscription(dbConn, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Nie znaleziono subskrypcji", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			internalError(w, r, "Nie udało się usunąć subskrypcji", err)
+			return
+		}
+		writeJSON(w, r, map[string]string{"message": "Subskrypcja usunięta pomyślnie"})
+	}
+}
+
+// ListDeadLettersHandler zwraca zdarzenia, których nie udało się doręczyć
+// w MaxAttempts próbach, opcjonalnie tylko dla subskrypcji z parametru
+// subscription.
+func ListDeadLettersHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var subscriptionID int64
+		if v := r.URL.Query().Get("subscription"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id <= 0 {
+				http.Error(w, "Parametr subscription musi być identyfikatorem subskrypcji", http.StatusBadRequest)
+				return
+			}
+			subscriptionID = id
+		}
+
+		deliveries, err := db.ListDeadWebhookDeliveries(dbConn, subscriptionID)
+		if err != nil {
+			internalError(w, r, "Błąd pobierania danych", err)
+			return
+		}
+		writeJSON(w, r, map[string]any{"deadLetters": deliveries})
+	}
+}
+
+// RetryDeadLetterHandler przywraca niedoręczone zdarzenie do kolejki.
+func RetryDeadLetterHandler(dbConn *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		err := db.RetryWebhookDelivery(dbConn, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Nie znaleziono niedoręczonego zdarzenia", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			internalError(w, r, "Nie udało się ponowić zdarzenia", err)
+			return
+		}
+		writeJSON(w, r, map[string]string{"message": "Zdarzenie wróciło do kolejki"})
+	}
+}
+
+// pathID odczytuje identyfikator {id} z adresu.
+func pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "Nieprawidłowy identyfikator", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		internalError(w, r, "Błąd podczas kodowania odpowiedzi", err)
+	}
+}
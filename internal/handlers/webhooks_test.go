@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+	"swift-codes/internal/outbox"
+)
+
+func TestValidateWebhookSubscription(t *testing.T) {
+	valid := model.WebhookSubscription{URL: " https://example.com/hook ", CountryISO2: "pl", BIC8: "bpko pl pw"}
+	NormalizeWebhookSubscription(&valid)
+	if valid.URL != "https://example.com/hook" || valid.CountryISO2 != "PL" || valid.BIC8 != "BPKOPLPW" {
+		t.Errorf("Nieoczekiwana normalizacja subskrypcji: %+v", valid)
+	}
+	if errs := ValidateWebhookSubscription(valid); len(errs) != 0 {
+		t.Errorf("Oczekiwano poprawnej subskrypcji, otrzymano błędy %v", errs)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(sub *model.WebhookSubscription)
+		field  string
+	}{
+		{"adres względny", func(sub *model.WebhookSubscription) { sub.URL = "/hook" }, "url"},
+		{"adres spoza http", func(sub *model.WebhookSubscription) { sub.URL = "ftp://example.com/hook" }, "url"},
+		{"za krótki sekret", func(sub *model.WebhookSubscription) { sub.Secret = "tajne" }, "secret"},
+		{"nieznany kraj", func(sub *model.WebhookSubscription) { sub.CountryISO2 = "XX" }, "countryISO2"},
+		{"BIC11 zamiast BIC8", func(sub *model.WebhookSubscription) { sub.BIC8 = "BPKOPLPWXXX" }, "bic8"},
+	}
+	for _, tc := range tests {
+		sub := valid
+		tc.modify(&sub)
+		errs := ValidateWebhookSubscription(sub)
+		if len(errs) != 1 || errs[0].Field != tc.field {
+			t.Errorf("%s - oczekiwano błędu pola %s, otrzymano %v", tc.name, tc.field, errs)
+		}
+	}
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	router, testDB := setupTestServer(t)
+	defer testDB.Close()
+	clearTable(testDB, t)
+	if _, err := testDB.Exec("TRUNCATE TABLE webhook_subscriptions CASCADE"); err != nil {
+		t.Fatalf("Nie udało się wyczyścić subskrypcji: %v", err)
+	}
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		req, err := http.NewRequest(method, path, &payload)
+		if err != nil {
+			t.Fatalf("Błąd tworzenia żądania %s: %v", method, err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("POST", "/v1/webhooks", model.WebhookSubscription{URL: "https://example.com/pl", CountryISO2: "pl"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("POST /v1/webhooks - oczekiwano status 201, otrzymano %d: %s", rr.Code, rr.Body.String())
+	}
+	var created model.WebhookSubscription
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.ID == 0 || len(created.Secret) < minSecretLength {
+		t.Fatalf("Oczekiwano identyfikatora i wylosowanego sekretu, otrzymano %+v", created)
+	}
+	if rr := send("POST", "/v1/webhooks", model.WebhookSubscription{URL: "mailto:x@example.com"}); rr.Code != http.StatusBadRequest {
+		t.Errorf("POST /v1/webhooks z błędnym adresem - oczekiwano status 400, otrzymano %d", rr.Code)
+	}
+
+	rr = send("GET", fmt.Sprintf("/v1/webhooks/%d", created.ID), nil)
+	var fetched model.WebhookSubscription
+	json.NewDecoder(rr.Body).Decode(&fetched)
+	if rr.Code != http.StatusOK || fetched.Secret != "" || fetched.CountryISO2 != "PL" {
+		t.Errorf("GET /v1/webhooks/{id} - oczekiwano subskrypcji bez sekretu, otrzymano %d %+v", rr.Code, fetched)
+	}
+
+	start, err := db.LatestChangeSequence(testDB)
+	if err != nil {
+		t.Fatalf("LatestChangeSequence nie powiodło się: %v", err)
+	}
+	for _, rec := range []model.SwiftCode{
+		{BankName: "HOOK BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "HOOKPLPWXXX"},
+		{BankName: "HOOK BANK", Address: "HQ", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true, SwiftCode: "HOOKDEFFXXX"},
+	} {
+		if rr := send("POST", "/v1/swift-codes", rec); rr.Code != http.StatusOK {
+			t.Fatalf("POST /v1/swift-codes - oczekiwano status 200, otrzymano %d", rr.Code)
+		}
+	}
+
+	// Zmiany trafiają do kolejek webhooków przez outbox.
+	changes, err := db.ListChanges(testDB, start, 10)
+	if err != nil {
+		t.Fatalf("ListChanges nie powiodło się: %v", err)
+	}
+	sink := outbox.NewWebhookSink(testDB)
+	for _, c := range changes {
+		if err := sink.Deliver(context.Background(), c); err != nil {
+			t.Fatalf("Przekazanie zmiany do webhooków nie powiodło się: %v", err)
+		}
+	}
+
+	var eventType, swiftCode string
+	var queued int
+	if err := testDB.QueryRow("SELECT COUNT(*), MIN(event_type), MIN(swift_code) FROM webhook_deliveries").Scan(&queued, &eventType, &swiftCode); err != nil {
+		t.Fatalf("Błąd odczytu kolejki: %v", err)
+	}
+	if queued != 1 || eventType != "swift_code.created" || swiftCode != "HOOKPLPWXXX" {
+		t.Fatalf("Oczekiwano jednego zdarzenia utworzenia HOOKPLPWXXX, otrzymano %d %s %s", queued, eventType, swiftCode)
+	}
+
+	var deliveryID int64
+	testDB.QueryRow("SELECT id FROM webhook_deliveries").Scan(&deliveryID)
+	if err := db.FailWebhookDelivery(testDB, deliveryID, "odpowiedź 500", time.Now(), true); err != nil {
+		t.Fatalf("FailWebhookDelivery nie powiodło się: %v", err)
+	}
+
+	rr = send("GET", fmt.Sprintf("/v1/webhooks/dead-letters?subscription=%d", created.ID), nil)
+	var dead struct{ DeadLetters []model.WebhookDelivery }
+	json.NewDecoder(rr.Body).Decode(&dead)
+	if len(dead.DeadLetters) != 1 || dead.DeadLetters[0].LastError != "odpowiedź 500" || dead.DeadLetters[0].Attempts != 1 {
+		t.Fatalf("Oczekiwano jednego niedoręczonego zdarzenia, otrzymano %+v", dead)
+	}
+
+	if rr := send("POST", fmt.Sprintf("/v1/webhooks/dead-letters/%d/retry", deliveryID), nil); rr.Code != http.StatusOK {
+		t.Errorf("Ponowienie - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+	if rr := send("POST", fmt.Sprintf("/v1/webhooks/dead-letters/%d/retry", deliveryID), nil); rr.Code != http.StatusNotFound {
+		t.Errorf("Ponowienie zdarzenia z kolejki - oczekiwano status 404, otrzymano %d", rr.Code)
+	}
+
+	if rr := send("DELETE", fmt.Sprintf("/v1/webhooks/%d", created.ID), nil); rr.Code != http.StatusOK {
+		t.Errorf("DELETE /v1/webhooks/{id} - oczekiwano status 200, otrzymano %d", rr.Code)
+	}
+	testDB.QueryRow("SELECT COUNT(*) FROM webhook_deliveries").Scan(&queued)
+	if queued != 0 {
+		t.Errorf("Oczekiwano usunięcia zdarzeń razem z subskrypcją, pozostało %d", queued)
+	}
+	if rr := send("GET", fmt.Sprintf("/v1/webhooks/%d", created.ID), nil); rr.Code != http.StatusNotFound {
+		t.Errorf("GET usuniętej subskrypcji - oczekiwano status 404, otrzymano %d", rr.Code)
+	}
+}
@@ -0,0 +1,205 @@
+// Package iban sprawdza numery IBAN (ISO 13616) według formatów krajowych
+// z rejestru IBAN i wyodrębnia z nich krajowy identyfikator banku.
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrCountry   = errors.New("nieobsługiwany kod kraju IBAN")
+	ErrLength    = errors.New("nieprawidłowa długość numeru IBAN")
+	ErrFormat    = errors.New("numer IBAN nie odpowiada formatowi krajowemu")
+	ErrChecksum  = errors.New("nieprawidłowa suma kontrolna numeru IBAN")
+	ErrCharacter = errors.New("numer IBAN może zawierać tylko litery i cyfry")
+)
+
+// Format opisuje krajowy format BBAN (część numeru po kodzie kraju i cyfrach
+// kontrolnych). Structure jest zapisana jak w rejestrze IBAN, np. "8!n16!n":
+// n to cyfry, a wielkie litery, c litery lub cyfry. Bank i Branch to pozycje
+// (od zera) i długości identyfikatorów banku i oddziału w BBAN.
+type Format struct {
+	Structure string
+	Length    int
+	Bank      [2]int
+	Branch    [2]int
+	segments  []segment
+}
+
+// segment to fragment struktury BBAN: length znaków rodzaju kind.
+type segment struct {
+	length int
+	kind   byte
+}
+
+// newFormat odczytuje strukturę w zapisie rejestru IBAN.
+func newFormat(structure string, bank, branch [2]int) Format {
+	f := Format{Structure: structure, Bank: bank, Branch: branch}
+	for rest := structure; rest != ""; {
+		i := strings.IndexByte(rest, '!')
+		length, err := strconv.Atoi(rest[:max(i, 0)])
+		if i < 0 || i+1 >= len(rest) || err != nil || !strings.ContainsRune("nac", rune(rest[i+1])) {
+			panic(fmt.Sprintf("iban: błędna struktura %q", structure))
+		}
+		f.segments = append(f.segments, segment{length: length, kind: rest[i+1]})
+		f.Length += length
+		rest = rest[i+2:]
+	}
+	return f
+}
+
+// formats pochodzi z rejestru IBAN (SWIFT). Kraj, którego tu nie ma, jest
+// odrzucany z ErrCountry.
+var formats = map[string]Format{
+	"AD": newFormat("4!n4!n12!c", [2]int{0, 4}, [2]int{4, 4}),
+	"AL": newFormat("8!n16!c", [2]int{0, 3}, [2]int{3, 5}),
+	"AT": newFormat("5!n11!n", [2]int{0, 5}, [2]int{}),
+	"BE": newFormat("3!n7!n2!n", [2]int{0, 3}, [2]int{}),
+	"BG": newFormat("4!a4!n2!n8!c", [2]int{0, 4}, [2]int{4, 4}),
+	"CH": newFormat("5!n12!c", [2]int{0, 5}, [2]int{}),
+	"CY": newFormat("3!n5!n16!c", [2]int{0, 3}, [2]int{3, 5}),
+	"CZ": newFormat("4!n6!n10!n", [2]int{0, 4}, [2]int{}),
+	"DE": newFormat("8!n10!n", [2]int{0, 8}, [2]int{}),
+	"DK": newFormat("4!n9!n1!n", [2]int{0, 4}, [2]int{}),
+	"EE": newFormat("2!n2!n11!n1!n", [2]int{0, 2}, [2]int{}),
+	"ES": newFormat("4!n4!n1!n1!n10!n", [2]int{0, 4}, [2]int{4, 4}),
+	"FI": newFormat("3!n11!n", [2]int{0, 3}, [2]int{}),
+	"FR": newFormat("5!n5!n11!c2!n", [2]int{0, 5}, [2]int{5, 5}),
+	"GB": newFormat("4!a6!n8!n", [2]int{0, 4}, [2]int{4, 6}),
+	"GR": newFormat("3!n4!n16!c", [2]int{0, 3}, [2]int{3, 4}),
+	"HR": newFormat("7!n10!n", [2]int{0, 7}, [2]int{}),
+	"HU": newFormat("3!n4!n1!n15!n1!n", [2]int{0, 3}, [2]int{3, 4}),
+	"IE": newFormat("4!a6!n8!n", [2]int{0, 4}, [2]int{4, 6}),
+	"IT": newFormat("1!a5!n5!n12!c", [2]int{1, 5}, [2]int{6, 5}),
+	"LT": newFormat("5!n11!n", [2]int{0, 5}, [2]int{}),
+	"LU": newFormat("3!n13!c", [2]int{0, 3}, [2]int{}),
+	"LV": newFormat("4!a13!c", [2]int{0, 4}, [2]int{}),
+	"MT": newFormat("4!a5!n18!c", [2]int{0, 4}, [2]int{4, 5}),
+	"NL": newFormat("4!a10!n", [2]int{0, 4}, [2]int{}),
+	"NO": newFormat("4!n6!n1!n", [2]int{0, 4}, [2]int{}),
+	// Numer rozliczeniowy: 3 cyfry banku, 4 cyfry oddziału i cyfra kontrolna.
+	"PL": newFormat("8!n16!n", [2]int{0, 3}, [2]int{3, 5}),
+	"PT": newFormat("4!n4!n11!n2!n", [2]int{0, 4}, [2]int{4, 4}),
+	"RO": newFormat("4!a16!c", [2]int{0, 4}, [2]int{}),
+	"SE": newFormat("3!n16!n1!n", [2]int{0, 3}, [2]int{}),
+	"SI": newFormat("5!n8!n2!n", [2]int{0, 2}, [2]int{2, 3}),
+	"SK": newFormat("4!n6!n10!n", [2]int{0, 4}, [2]int{}),
+}
+
+func (f Format) matches(bban string) bool {
+	if len(bban) != f.Length {
+		return false
+	}
+	for _, s := range f.segments {
+		for i := 0; i < s.length; i++ {
+			c := bban[i]
+			digit, letter := c >= '0' && c <= '9', c >= 'A' && c <= 'Z'
+			if (s.kind == 'n' && !digit) || (s.kind == 'a' && !letter) || (s.kind == 'c' && !digit && !letter) {
+				return false
+			}
+		}
+		bban = bban[s.length:]
+	}
+	return true
+}
+
+// IBAN to numer IBAN rozłożony na części.
+type IBAN struct {
+	// Number to numer w postaci elektronicznej (bez spacji).
+	Number      string
+	Country     string
+	CheckDigits string
+	BBAN        string
+	BankCode    string
+	BranchCode  string
+}
+
+// NationalID zwraca krajowy identyfikator banku z oddziałem, np. polski
+// numer rozliczeniowy.
+func (n IBAN) NationalID() string {
+	return n.BankCode + n.BranchCode
+}
+
+// Formatted zwraca numer w postaci papierowej, w grupach po 4 znaki.
+func (n IBAN) Formatted() string {
+	var b strings.Builder
+	for i := 0; i < len(n.Number); i += 4 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(n.Number[i:min(i+4, len(n.Number))])
+	}
+	return b.String()
+}
+
+// Normalize usuwa z numeru białe znaki i zamienia litery na wielkie.
+func Normalize(number string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, number))
+}
+
+// Parse sprawdza numer IBAN (po Normalize) i zwraca jego części.
+func Parse(number string) (IBAN, error) {
+	for i := 0; i < len(number); i++ {
+		if c := number[i]; (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return IBAN{}, ErrCharacter
+		}
+	}
+	if len(number) < 4 {
+		return IBAN{}, fmt.Errorf("%w, otrzymano %d znaków", ErrLength, len(number))
+	}
+	country := number[:2]
+	format, ok := formats[country]
+	if !ok {
+		return IBAN{}, fmt.Errorf("%w: %s", ErrCountry, country)
+	}
+	if want := 4 + format.Length; len(number) != want {
+		return IBAN{}, fmt.Errorf("%w: dla %s wymagane %d znaków, otrzymano %d", ErrLength, country, want, len(number))
+	}
+	n := IBAN{Number: number, Country: country, CheckDigits: number[2:4], BBAN: number[4:]}
+	if !isDigits(n.CheckDigits) || !format.matches(n.BBAN) {
+		return n, fmt.Errorf("%w %s (%s)", ErrFormat, country, format.Structure)
+	}
+	if mod97(number) != 1 {
+		return n, ErrChecksum
+	}
+	n.BankCode = n.BBAN[format.Bank[0] : format.Bank[0]+format.Bank[1]]
+	if format.Branch[1] > 0 {
+		n.BranchCode = n.BBAN[format.Branch[0] : format.Branch[0]+format.Branch[1]]
+	}
+	return n, nil
+}
+
+// mod97 liczy resztę z dzielenia przez 97 numeru z przeniesionymi na koniec
+// czterema pierwszymi znakami i literami zamienionymi na liczby (A = 10).
+func mod97(number string) int {
+	rearranged := number[4:] + number[:4]
+	r := 0
+	for i := 0; i < len(rearranged); i++ {
+		c := rearranged[i]
+		if c >= 'A' && c <= 'Z' {
+			v := int(c-'A') + 10
+			r = (r*100 + v) % 97
+		} else {
+			r = (r*10 + int(c-'0')) % 97
+		}
+	}
+	return r
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
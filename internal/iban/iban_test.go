@@ -0,0 +1,70 @@
+package iban
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		number string
+		err    error
+	}{
+		{"PL61109010140000071219812874", nil},
+		{"DE89370400440532013000", nil},
+		{"GB29NWBK60161331926819", nil},
+		{"FR1420041010050500013M02606", nil},
+		{"IT60X0542811101000000123456", nil},
+		{"NL91ABNA0417164300", nil},
+		{"BE68539007547034", nil},
+		{"CH9300762011623852957", nil},
+		{"AT611904300234573201", nil},
+		{"ES9121000418450200051332", nil},
+		{"PL61109010140000071219812875", ErrChecksum},
+		{"PL6110901014000007121981287", ErrLength},
+		{"PL6110901014000007121981287A", ErrFormat},
+		{"PLXX109010140000071219812874", ErrFormat},
+		{"GB29NWBK6016133192681", ErrLength},
+		{"NL91ABN10417164300", ErrFormat},
+		{"US12345678901234", ErrCountry},
+		{"PL61-109010140000071219812874", ErrCharacter},
+		{"PL", ErrLength},
+	}
+
+	for _, tc := range tests {
+		_, err := Parse(tc.number)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("Parse(%q) - oczekiwano błędu %v, otrzymano %v", tc.number, tc.err, err)
+		}
+	}
+}
+
+func TestBankIdentifiers(t *testing.T) {
+	tests := []struct {
+		number, bank, branch string
+	}{
+		{"PL61109010140000071219812874", "109", "01014"},
+		{"PL36109010150000071219812874", "109", "01015"},
+		{"DE89370400440532013000", "37040044", ""},
+		{"GB29NWBK60161331926819", "NWBK", "601613"},
+		{"IT60X0542811101000000123456", "05428", "11101"},
+		{"FR1420041010050500013M02606", "20041", "01005"},
+	}
+	for _, tc := range tests {
+		n, err := Parse(tc.number)
+		if err != nil {
+			t.Fatalf("Parse(%q) nie powiodło się: %v", tc.number, err)
+		}
+		if n.BankCode != tc.bank || n.BranchCode != tc.branch {
+			t.Errorf("%s - oczekiwano banku %q i oddziału %q, otrzymano %q i %q", tc.number, tc.bank, tc.branch, n.BankCode, n.BranchCode)
+		}
+	}
+
+	n, _ := Parse(Normalize(" pl61 1090 1014 0000 0712 1981 2874 "))
+	if n.NationalID() != "10901014" {
+		t.Errorf("Oczekiwano numeru rozliczeniowego 10901014, otrzymano %q", n.NationalID())
+	}
+	if got := n.Formatted(); got != "PL61 1090 1014 0000 0712 1981 2874" {
+		t.Errorf("Nieprawidłowa postać papierowa: %q", got)
+	}
+}
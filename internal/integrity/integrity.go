@@ -0,0 +1,110 @@
+// Package integrity sprawdza spójność hierarchii central i oddziałów.
+// Centrala oddziału jest wyznaczana tak jak w API: to centrala wskazana
+// w rekordzie (HeadquarterCode, po przeniesieniu oddziałów), a bez tego
+// kod BIC8 + "XXX".
+package integrity
+
+import (
+	"sort"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/model"
+)
+
+// Checker zbiera rekordy jeden po drugim, więc może pracować na strumieniu
+// z bazy danych bez ładowania całego katalogu do pamięci naraz.
+type Checker struct {
+	checked      int
+	headquarters map[string]string   // kod centrali -> kraj
+	branches     []model.SwiftCode   // tylko kod, kraj i centrala
+	normalized   map[string][]string // kod znormalizowany -> kody w postaci oryginalnej
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		headquarters: map[string]string{},
+		normalized:   map[string][]string{},
+	}
+}
+
+// Normalize usuwa białe znaki i zamienia kod na wielkie litery
+// (zob. bic.Normalize).
+func Normalize(code string) string {
+	return bic.Normalize(code)
+}
+
+func (c *Checker) Add(sc model.SwiftCode) {
+	c.checked++
+	key := Normalize(sc.SwiftCode)
+	c.normalized[key] = append(c.normalized[key], sc.SwiftCode)
+
+	if bic.IsHeadquarter(key) {
+		c.headquarters[key] = sc.CountryISO2
+	} else {
+		c.branches = append(c.branches, model.SwiftCode{SwiftCode: key, CountryISO2: sc.CountryISO2, HeadquarterCode: Normalize(sc.HeadquarterCode)})
+	}
+}
+
+func (c *Checker) Report() model.IntegrityReport {
+	report := model.IntegrityReport{
+		Checked:           c.checked,
+		OrphanBranches:    []string{},
+		CountryMismatches: []model.CountryMismatch{},
+		Duplicates:        []model.DuplicateGroup{},
+	}
+
+	for _, branch := range c.branches {
+		hq := branch.HeadquarterCode
+		if hq == "" {
+			if len(branch.SwiftCode) < 8 {
+				continue
+			}
+			hq = branch.SwiftCode[:8] + "XXX"
+		}
+		hqCountry, ok := c.headquarters[hq]
+		if !ok {
+			report.OrphanBranches = append(report.OrphanBranches, branch.SwiftCode)
+			continue
+		}
+		if hqCountry != branch.CountryISO2 {
+			report.CountryMismatches = append(report.CountryMismatches, model.CountryMismatch{
+				Headquarter:        hq,
+				HeadquarterCountry: hqCountry,
+				Branch:             branch.SwiftCode,
+				BranchCountry:      branch.CountryISO2,
+			})
+		}
+	}
+
+	for key, codes := range c.normalized {
+		if len(codes) > 1 {
+			sort.Strings(codes)
+			report.Duplicates = append(report.Duplicates, model.DuplicateGroup{Normalized: key, SwiftCodes: codes})
+		}
+	}
+
+	sort.Strings(report.OrphanBranches)
+	sort.Slice(report.CountryMismatches, func(i, j int) bool {
+		return report.CountryMismatches[i].Branch < report.CountryMismatches[j].Branch
+	})
+	sort.Slice(report.Duplicates, func(i, j int) bool {
+		return report.Duplicates[i].Normalized < report.Duplicates[j].Normalized
+	})
+	return report
+}
+
+// Check sprawdza komplet rekordów (z oddziałami zagnieżdżonymi lub płasko).
+func Check(records []model.SwiftCode) model.IntegrityReport {
+	c := NewChecker()
+	var add func(sc model.SwiftCode)
+	add = func(sc model.SwiftCode) {
+		c.Add(sc)
+		for _, branch := range sc.Branches {
+			add(branch)
+		}
+	}
+	for _, sc := range records {
+		add(sc)
+	}
+	return c.Report()
+}
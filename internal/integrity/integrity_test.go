@@ -0,0 +1,61 @@
+package integrity
+
+import (
+	"reflect"
+	"testing"
+
+	"swift-codes/internal/model"
+)
+
+func TestCheck(t *testing.T) {
+	records := []model.SwiftCode{
+		{SwiftCode: "BPKOPLPWXXX", CountryISO2: "PL", IsHeadquarter: true, Branches: []model.SwiftCode{
+			{SwiftCode: "BPKOPLPWKRK", CountryISO2: "PL"},
+			{SwiftCode: "BPKOPLPWBER", CountryISO2: "DE"},
+		}},
+		{SwiftCode: "ORPHPLPWKRK", CountryISO2: "PL"},
+		{SwiftCode: "bpkoplpwkrk ", CountryISO2: "PL"},
+		{SwiftCode: "BREXPLPWXXX", CountryISO2: "PL", IsHeadquarter: true},
+	}
+
+	report := Check(records)
+
+	if report.Checked != 6 {
+		t.Errorf("Oczekiwano 6 sprawdzonych rekordów, otrzymano %d", report.Checked)
+	}
+	if want := []string{"ORPHPLPWKRK"}; !reflect.DeepEqual(report.OrphanBranches, want) {
+		t.Errorf("Osierocone oddziały: oczekiwano %v, otrzymano %v", want, report.OrphanBranches)
+	}
+	wantMismatch := []model.CountryMismatch{{Headquarter: "BPKOPLPWXXX", HeadquarterCountry: "PL", Branch: "BPKOPLPWBER", BranchCountry: "DE"}}
+	if !reflect.DeepEqual(report.CountryMismatches, wantMismatch) {
+		t.Errorf("Niezgodne kraje: oczekiwano %v, otrzymano %v", wantMismatch, report.CountryMismatches)
+	}
+	wantDuplicates := []model.DuplicateGroup{{Normalized: "BPKOPLPWKRK", SwiftCodes: []string{"BPKOPLPWKRK", "bpkoplpwkrk "}}}
+	if !reflect.DeepEqual(report.Duplicates, wantDuplicates) {
+		t.Errorf("Duplikaty: oczekiwano %v, otrzymano %v", wantDuplicates, report.Duplicates)
+	}
+	if report.Issues() != 3 {
+		t.Errorf("Oczekiwano 3 problemów, otrzymano %d", report.Issues())
+	}
+}
+
+func TestCheck_CleanData(t *testing.T) {
+	report := Check([]model.SwiftCode{
+		{SwiftCode: "AAISALTRXXX", CountryISO2: "AL", IsHeadquarter: true},
+		{SwiftCode: "AAISALTR001", CountryISO2: "AL"},
+	})
+	if report.Issues() != 0 {
+		t.Errorf("Oczekiwano braku problemów, otrzymano %+v", report)
+	}
+}
+
+func TestCheck_ReparentedBranches(t *testing.T) {
+	report := Check([]model.SwiftCode{
+		{SwiftCode: "NEWBPLPWXXX", CountryISO2: "PL", IsHeadquarter: true},
+		{SwiftCode: "OLDBPLPWKRK", CountryISO2: "PL", HeadquarterCode: "NEWBPLPWXXX"},
+		{SwiftCode: "OLDBPLPWWAW", CountryISO2: "PL", HeadquarterCode: "GONEPLPWXXX"},
+	})
+	if want := []string{"OLDBPLPWWAW"}; !reflect.DeepEqual(report.OrphanBranches, want) {
+		t.Errorf("Osierocone oddziały: oczekiwano %v, otrzymano %v", want, report.OrphanBranches)
+	}
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Role określają, co klient może zrobić; każda kolejna obejmuje poprzednie.
+const (
+	RoleReader = "reader"
+	RoleWriter = "writer"
+	RoleAdmin  = "admin"
+)
+
+var roleRank = map[string]int{RoleReader: 1, RoleWriter: 2, RoleAdmin: 3}
+
+// ValidRole sprawdza, czy role jest jedną z ról Role*.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// Identity to klient żądania i jego rola.
+type Identity struct {
+	Client string `json:"client"`
+	Role   string `json:"role"`
+	// AuthRequired mówi, czy serwer sprawdza uprawnienia; jest fałszywe
+	// tylko przy jawnie wyłączonej kontroli dostępu (AuthConfig.Disabled).
+	AuthRequired bool `json:"authRequired"`
+}
+
+// Allows sprawdza, czy rola klienta obejmuje role.
+func (id Identity) Allows(role string) bool {
+	return roleRank[id.Role] >= roleRank[role]
+}
+
+type identityKey struct{}
+
+func IdentityFromContext(ctx context.Context) Identity {
+	id, _ := ctx.Value(identityKey{}).(Identity)
+	return id
+}
+
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// ParseAPIKeys odczytuje listę "<klucz>:<rola>,..." przypisującą klucze
+// API do ról.
+func ParseAPIKeys(s string) (map[string]string, error) {
+	keys := map[string]string{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, role, ok := strings.Cut(entry, ":")
+		if !ok || key == "" || !ValidRole(role) {
+			return nil, fmt.Errorf("oczekiwano <klucz>:<rola> z rolą %s, %s lub %s", RoleReader, RoleWriter, RoleAdmin)
+		}
+		keys[key] = role
+	}
+	return keys, nil
+}
+
+// ParseCertRoles odczytuje listę "<podmiot>:<rola>;..." przypisującą
+// certyfikaty klientów do ról. Podmiot to pełna nazwa wyróżniająca
+// certyfikatu, np. "CN=payments,O=Bank,C=PL", albo samo "CN=<nazwa>".
+func ParseCertRoles(s string) (map[string]string, error) {
+	roles := map[string]string{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 || !ValidRole(entry[i+1:]) {
+			return nil, fmt.Errorf("oczekiwano <podmiot>:<rola> z rolą %s, %s lub %s", RoleReader, RoleWriter, RoleAdmin)
+		}
+		roles[strings.TrimSpace(entry[:i])] = entry[i+1:]
+	}
+	return roles, nil
+}
+
+// ClientCertificate zwraca zweryfikowany certyfikat klienta połączenia TLS
+// albo nil.
+func ClientCertificate(r *http.Request) *x509.Certificate {
+	return VerifiedCertificate(r.TLS)
+}
+
+// VerifiedCertificate zwraca zweryfikowany certyfikat klienta ze stanu
+// połączenia TLS (także połączenia gRPC) albo nil.
+func VerifiedCertificate(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+func certRole(roles map[string]string, cert *x509.Certificate) (string, bool) {
+	if role, ok := roles[cert.Subject.String()]; ok {
+		return role, true
+	}
+	role, ok := roles["CN="+cert.Subject.CommonName]
+	return role, ok
+}
+
+type AuthConfig struct {
+	// APIKeys przypisuje klucze API (nagłówek X-API-Key) do ról.
+	APIKeys map[string]string
+	// CertRoles przypisuje podmioty certyfikatów klientów do ról (zob.
+	// ParseCertRoles).
+	CertRoles map[string]string
+	// Disabled wyłącza kontrolę dostępu: każdy klient ma wtedy rolę admin.
+	// Sam brak kluczy API i certyfikatów jej nie wyłącza.
+	Disabled bool
+	// Required zwraca rolę potrzebną do wykonania żądania.
+	Required func(r *http.Request) string
+}
+
+// AccessError to odmowa dostępu: Unauthenticated dla nieznanego klucza albo
+// klienta bez poświadczeń (HTTP 401), w przeciwnym razie zbyt słaba rola
+// (HTTP 403).
+type AccessError struct {
+	Unauthenticated bool
+	Message         string
+}
+
+func (e *AccessError) Error() string { return e.Message }
+
+// Authorize ustala tożsamość klienta z klucza API i zweryfikowanego
+// certyfikatu (oba mogą być puste) i sprawdza, czy ma rolę required. Klient
+// bez klucza i przypisanego certyfikatu ma rolę reader, także gdy serwer nie
+// ma skonfigurowanych kluczy; klient z obydwoma dostaje silniejszą z ich ról.
+// Tylko przy Disabled każdy klient ma rolę admin. Odmowa jest zwracana jako
+// *AccessError.
+func (cfg AuthConfig) Authorize(client, key string, cert *x509.Certificate, required string) (Identity, error) {
+	if cfg.Disabled {
+		return Identity{Client: client, Role: RoleAdmin}, nil
+	}
+
+	id := Identity{Client: client, Role: RoleReader, AuthRequired: true}
+	authenticated := false
+	if cert != nil {
+		if role, ok := certRole(cfg.CertRoles, cert); ok {
+			id.Role = role
+			authenticated = true
+		}
+	}
+	if key != "" {
+		role, ok := cfg.APIKeys[key]
+		if !ok {
+			return id, &AccessError{Unauthenticated: true, Message: "Nieznany klucz API"}
+		}
+		if !id.Allows(role) {
+			id.Role = role
+		}
+		authenticated = true
+	}
+
+	if !id.Allows(required) {
+		if !authenticated {
+			return id, &AccessError{Unauthenticated: true, Message: fmt.Sprintf("Wymagany klucz API lub certyfikat klienta z rolą %s", required)}
+		}
+		return id, &AccessError{Message: fmt.Sprintf("Operacja wymaga roli %s", required)}
+	}
+	return id, nil
+}
+
+// Auth ustala tożsamość klienta (zob. AuthConfig.Authorize) i odrzuca
+// żądania, do których nie ma uprawnień, odpowiedzią 401 albo 403.
+func Auth(cfg AuthConfig) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := cfg.Authorize(ClientID(r, cfg.APIKeys), r.Header.Get(APIKeyHeader), ClientCertificate(r), cfg.Required(r))
+			var denied *AccessError
+			if errors.As(err, &denied) {
+				status := http.StatusForbidden
+				if denied.Unauthenticated {
+					status = http.StatusUnauthorized
+				}
+				http.Error(w, denied.Message, status)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+		})
+	}
+}
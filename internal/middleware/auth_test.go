@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys("klucz-a:writer, klucz-b:admin,")
+	if err != nil || len(keys) != 2 || keys["klucz-a"] != RoleWriter || keys["klucz-b"] != RoleAdmin {
+		t.Errorf("Nieoczekiwany wynik: %v (%v)", keys, err)
+	}
+	for _, in := range []string{"klucz-a", "klucz-a:root", ":reader"} {
+		if _, err := ParseAPIKeys(in); err == nil {
+			t.Errorf("ParseAPIKeys(%q) - oczekiwano błędu", in)
+		}
+	}
+}
+
+func TestAuth(t *testing.T) {
+	var got Identity
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = IdentityFromContext(r.Context())
+	})
+	required := func(r *http.Request) string {
+		if r.Method == http.MethodGet {
+			return RoleReader
+		}
+		return RoleWriter
+	}
+	h := Auth(AuthConfig{APIKeys: map[string]string{"klucz-r": RoleReader, "klucz-w": RoleWriter}, Required: required})(next)
+
+	tests := []struct {
+		method, apiKey string
+		want           int
+		role           string
+	}{
+		{"GET", "", http.StatusOK, RoleReader},
+		{"DELETE", "", http.StatusUnauthorized, ""},
+		{"GET", "nieznany", http.StatusUnauthorized, ""},
+		{"DELETE", "klucz-r", http.StatusForbidden, ""},
+		{"DELETE", "klucz-w", http.StatusOK, RoleWriter},
+	}
+	for _, tt := range tests {
+		got = Identity{}
+		rr := send(h, tt.method, tt.apiKey)
+		if rr.Code != tt.want || got.Role != tt.role {
+			t.Errorf("%s z kluczem %q - oczekiwano %d i roli %q, otrzymano %d i %q", tt.method, tt.apiKey, tt.want, tt.role, rr.Code, got.Role)
+		}
+	}
+
+	got = Identity{}
+	h = Auth(AuthConfig{Required: required})(next)
+	if rr := send(h, "DELETE", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Bez skonfigurowanych kluczy zapis powinien zostać odrzucony, otrzymano %d", rr.Code)
+	}
+	if rr := send(h, "GET", ""); rr.Code != http.StatusOK || got.Role != RoleReader || !got.AuthRequired {
+		t.Errorf("Bez skonfigurowanych kluczy klient powinien mieć rolę reader, otrzymano %d %+v", rr.Code, got)
+	}
+
+	h = Auth(AuthConfig{Disabled: true, Required: required})(next)
+	if rr := send(h, "DELETE", ""); rr.Code != http.StatusOK || got.Role != RoleAdmin || got.AuthRequired {
+		t.Errorf("Przy wyłączonej kontroli dostępu każdy klient powinien mieć rolę admin, otrzymano %d %+v", rr.Code, got)
+	}
+}
+
+func TestParseCertRoles(t *testing.T) {
+	roles, err := ParseCertRoles("CN=payments:writer; CN=ops,O=Example Bank,C=PL:admin")
+	if err != nil || len(roles) != 2 || roles["CN=payments"] != RoleWriter || roles["CN=ops,O=Example Bank,C=PL"] != RoleAdmin {
+		t.Errorf("Nieoczekiwany wynik: %v (%v)", roles, err)
+	}
+	for _, in := range []string{"CN=payments", "CN=payments:root", ":admin"} {
+		if _, err := ParseCertRoles(in); err == nil {
+			t.Errorf("ParseCertRoles(%q) - oczekiwano błędu", in)
+		}
+	}
+}
+
+// withClientCert dodaje do żądania zweryfikowany certyfikat klienta.
+func withClientCert(req *http.Request, subject pkix.Name) *http.Request {
+	cert := &x509.Certificate{Subject: subject}
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return req
+}
+
+func TestAuth_ClientCertificate(t *testing.T) {
+	var got Identity
+	h := Auth(AuthConfig{
+		APIKeys:   map[string]string{"klucz-a": RoleAdmin},
+		CertRoles: map[string]string{"CN=payments": RoleWriter, "CN=ops,O=Example Bank": RoleAdmin},
+		Required:  func(r *http.Request) string { return RoleWriter },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = IdentityFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		subject pkix.Name
+		apiKey  string
+		want    int
+		role    string
+	}{
+		{pkix.Name{CommonName: "payments", Organization: []string{"Example Bank"}}, "", http.StatusOK, RoleWriter},
+		{pkix.Name{CommonName: "ops", Organization: []string{"Example Bank"}}, "", http.StatusOK, RoleAdmin},
+		{pkix.Name{CommonName: "ops", Organization: []string{"Other Bank"}}, "", http.StatusUnauthorized, ""},
+		{pkix.Name{CommonName: "payments"}, "klucz-a", http.StatusOK, RoleAdmin},
+	}
+	for _, tt := range tests {
+		got = Identity{}
+		req := withClientCert(httptest.NewRequest("POST", "/v1/swift-codes", nil), tt.subject)
+		if tt.apiKey != "" {
+			req.Header.Set(APIKeyHeader, tt.apiKey)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != tt.want || got.Role != tt.role {
+			t.Errorf("%s z kluczem %q - oczekiwano %d i roli %q, otrzymano %d i %q", tt.subject, tt.apiKey, tt.want, tt.role, rr.Code, got.Role)
+		}
+	}
+	if got.Client != clientOf("klucz-a") {
+		t.Errorf("Klucz API powinien identyfikować klienta, otrzymano %q", got.Client)
+	}
+
+	req := withClientCert(httptest.NewRequest("GET", "/", nil), pkix.Name{CommonName: "payments"})
+	if id := ClientID(req, nil); id != "cert:payments" {
+		t.Errorf("Oczekiwano cert:payments, otrzymano %q", id)
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSConfig struct {
+	// AllowedOrigins to dozwolone źródła, np. "https://app.example.com".
+	// "*" dopuszcza każde źródło, a "https://*.example.com" każdą
+	// subdomenę. Pusta lista wyłącza CORS.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders to nagłówki odpowiedzi dostępne dla skryptu.
+	ExposedHeaders []string
+	// MaxAge to czas, przez który przeglądarka pamięta wynik zapytania
+	// wstępnego.
+	MaxAge time.Duration
+}
+
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders: []string{"Accept", "Content-Type", "If-Match", "If-None-Match", "Last-Event-ID", APIKeyHeader, RequestIDHeader},
+		ExposedHeaders: []string{"ETag", "Last-Modified", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", RequestIDHeader},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+func (cfg CORSConfig) allowed(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			prefix := scheme + "://"
+			if len(origin) > len(prefix) && strings.EqualFold(origin[:len(prefix)], prefix) &&
+				strings.HasSuffix(strings.ToLower(origin), "."+strings.ToLower(domain)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CORS pozwala wywoływać API ze skryptów stron z dozwolonych źródeł
+// i odpowiada na zapytania wstępne (OPTIONS) przeglądarki. Otacza cały
+// router, bo zapytania wstępne nie pasują do żadnej trasy.
+func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	wildcard := len(cfg.AllowedOrigins) == 1 && cfg.AllowedOrigins[0] == "*"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || len(cfg.AllowedOrigins) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !cfg.allowed(origin) {
+				if preflight {
+					http.Error(w, "Źródło niedozwolone przez CORS", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if wildcard {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if preflight {
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				h.Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set("Access-Control-Expose-Headers", exposed)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func corsRequest(h http.Handler, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/v1/swift-codes/AAISALTRXXX", nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", "PUT")
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCORS(t *testing.T) {
+	cfg := DefaultCORSConfig()
+	cfg.AllowedOrigins = []string{"https://app.example.com", "https://*.intranet.example.com"}
+	h := CORS(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rr := corsRequest(h, "GET", "https://app.example.com")
+	if rr.Code != http.StatusTeapot || rr.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("Oczekiwano dopuszczenia źródła, otrzymano %d %v", rr.Code, rr.Header())
+	}
+	if rr.Header().Get("Access-Control-Expose-Headers") == "" || rr.Header().Get("Vary") != "Origin" {
+		t.Errorf("Brak nagłówków Access-Control-Expose-Headers lub Vary: %v", rr.Header())
+	}
+
+	rr = corsRequest(h, "OPTIONS", "https://panel.intranet.example.com")
+	if rr.Code != http.StatusNoContent || rr.Header().Get("Access-Control-Allow-Methods") == "" || rr.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("Oczekiwano odpowiedzi na zapytanie wstępne, otrzymano %d %v", rr.Code, rr.Header())
+	}
+
+	if rr := corsRequest(h, "OPTIONS", "https://evil.example.com"); rr.Code != http.StatusForbidden {
+		t.Errorf("Zapytanie wstępne z niedozwolonego źródła - oczekiwano 403, otrzymano %d", rr.Code)
+	}
+	rr = corsRequest(h, "GET", "https://intranet.example.com.evil.com")
+	if rr.Code != http.StatusTeapot || rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Niedozwolone źródło nie powinno dostać nagłówków CORS, otrzymano %v", rr.Header())
+	}
+
+	h = CORS(DefaultCORSConfig())(http.NotFoundHandler())
+	if rr := corsRequest(h, "OPTIONS", "https://app.example.com"); rr.Code != http.StatusNotFound || rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Bez dozwolonych źródeł CORS powinien być wyłączony, otrzymano %d %v", rr.Code, rr.Header())
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestRouter(buf *bytes.Buffer) *mux.Router {
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(buf, nil)))
+
+	router := mux.NewRouter()
+	router.Use(Logging(logger))
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", func(w http.ResponseWriter, r *http.Request) {
+		logger.ErrorContext(r.Context(), "błąd testowy")
+		http.Error(w, "Błąd", http.StatusInternalServerError)
+	}).Methods("GET")
+	return router
+}
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var entries []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var entry map[string]any
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("Błąd dekodowania wpisu logu: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogging_PropagatesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	router := newTestRouter(&buf)
+
+	req := httptest.NewRequest("GET", "/v1/swift-codes/AAISALTRXXX", nil)
+	req.Header.Set(RequestIDHeader, "ticket-1234")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get(RequestIDHeader); got != "ticket-1234" {
+		t.Errorf("Oczekiwano nagłówka %s 'ticket-1234', otrzymano '%s'", RequestIDHeader, got)
+	}
+
+	entries := decodeLogLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("Oczekiwano 2 wpisów logu, otrzymano %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry["request_id"] != "ticket-1234" {
+			t.Errorf("Oczekiwano request_id 'ticket-1234', otrzymano '%v'", entry["request_id"])
+		}
+	}
+
+	access := entries[1]
+	if access["route"] != "/v1/swift-codes/{swiftCode}" {
+		t.Errorf("Oczekiwano route '/v1/swift-codes/{swiftCode}', otrzymano '%v'", access["route"])
+	}
+	if access["status"] != float64(http.StatusInternalServerError) {
+		t.Errorf("Oczekiwano status 500, otrzymano %v", access["status"])
+	}
+	if access["bytes"] != float64(rr.Body.Len()) {
+		t.Errorf("Oczekiwano bytes %d, otrzymano %v", rr.Body.Len(), access["bytes"])
+	}
+}
+
+func TestLogging_GeneratesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	router := newTestRouter(&buf)
+
+	req := httptest.NewRequest("GET", "/v1/swift-codes/AAISALTRXXX", nil)
+	req.Header.Set(RequestIDHeader, "zły identyfikator")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	got := rr.Header().Get(RequestIDHeader)
+	if got == "" || got == "zły identyfikator" {
+		t.Errorf("Oczekiwano wygenerowanego identyfikatora żądania, otrzymano '%s'", got)
+	}
+}
@@ -0,0 +1,343 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+var (
+	ErrRateLimited = errors.New("przekroczono limit żądań")
+	ErrDailyQuota  = errors.New("wyczerpano dzienny limit żądań")
+)
+
+// Klasy tras z osobnymi limitami.
+const (
+	RouteLookup = "lookup"
+	RouteSearch = "search"
+	RouteWrite  = "write"
+	RouteExport = "export"
+)
+
+// bucketIdle to czas, po którym nieużywany kubełek jest usuwany z pamięci;
+// przy ponownym użyciu powstaje pełny, więc klient nic nie traci.
+const bucketIdle = 10 * time.Minute
+
+// RateLimit to kubełek żetonów: Burst żądań naraz, uzupełnianych w tempie
+// Rate żądań na sekundę. Zerowy Rate wyłącza limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseRateLimit odczytuje limit w postaci "<liczba>/<okres>[:<burst>]",
+// np. "50/s:100" albo "6/1m" (burst domyślnie równy liczbie), lub "off".
+func ParseRateLimit(s string) (RateLimit, error) {
+	if s == "off" {
+		return RateLimit{}, nil
+	}
+	spec, burstPart, hasBurst := strings.Cut(s, ":")
+	countPart, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("oczekiwano formatu <liczba>/<okres>[:<burst>], otrzymano %q", s)
+	}
+	count, err := strconv.Atoi(countPart)
+	if err != nil || count < 1 {
+		return RateLimit{}, fmt.Errorf("nieprawidłowa liczba żądań %q", countPart)
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("nieprawidłowy okres %q", period)
+	}
+	burst := count
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstPart); err != nil || burst < 1 {
+			return RateLimit{}, fmt.Errorf("nieprawidłowy burst %q", burstPart)
+		}
+	}
+	return RateLimit{Rate: float64(count) / d.Seconds(), Burst: burst}, nil
+}
+
+type RateLimitConfig struct {
+	// Limits to limity dla klas tras; klasa bez limitu nie jest ograniczana.
+	Limits map[string]RateLimit
+	// DailyQuota to liczba żądań przyjmowanych od klienta w ciągu doby
+	// (UTC) przez jedną instancję serwera; 0 wyłącza limit.
+	DailyQuota int64
+	// Classify przypisuje żądanie do klasy tras.
+	Classify func(r *http.Request) string
+	// APIKeys to skonfigurowane klucze API (zob. ClientID).
+	APIKeys map[string]string
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type bucketKey struct {
+	client, class string
+}
+
+type usageKey struct {
+	day, client, class string
+}
+
+// RateLimiter ogranicza liczbę żądań każdego klienta (klucza API albo
+// adresu IP) osobno dla każdej klasy tras i zlicza żądania do raportu
+// dziennego.
+type RateLimiter struct {
+	cfg RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+	day       string
+	daily     map[string]int64
+	// pending to zliczenia, które nie trafiły jeszcze do magazynu.
+	pending map[usageKey]*model.APIUsage
+}
+
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: map[bucketKey]*bucket{},
+		daily:   map[string]int64{},
+		pending: map[usageKey]*model.APIUsage{},
+	}
+}
+
+// ClientID identyfikuje klienta żądania HTTP (zob. IdentifyClient).
+func ClientID(r *http.Request, apiKeys map[string]string) string {
+	return IdentifyClient(r.Header.Get(APIKeyHeader), apiKeys, ClientCertificate(r), r.RemoteAddr)
+}
+
+// IdentifyClient identyfikuje klienta: skrót klucza API (sam klucz nie
+// trafia do raportów), nazwa (CN) zweryfikowanego certyfikatu klienta albo
+// adres IP z remoteAddr. Klucz spoza apiKeys nie identyfikuje klienta, bo
+// każdy losowy klucz dawałby wtedy nowy kubełek i nowy limit dzienny.
+func IdentifyClient(key string, apiKeys map[string]string, cert *x509.Certificate, remoteAddr string) string {
+	if key != "" {
+		if _, ok := apiKeys[key]; ok {
+			sum := sha256.Sum256([]byte(key))
+			return "key:" + hex.EncodeToString(sum[:6])
+		}
+	}
+	if cert != nil {
+		return "cert:" + cert.Subject.CommonName
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+// decision to wynik sprawdzenia limitu dla jednego żądania.
+type decision struct {
+	allowed    bool
+	limit      RateLimit
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+	quota      bool
+}
+
+func (l *RateLimiter) allow(client, class string) decision {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	day := now.UTC().Format(time.DateOnly)
+	if day != l.day {
+		l.day = day
+		l.daily = map[string]int64{}
+	}
+
+	key := bucketKey{client, class}
+	d := decision{allowed: true, limit: l.cfg.Limits[class]}
+	if d.limit.Rate > 0 {
+		b := l.buckets[key]
+		if b == nil {
+			b = &bucket{tokens: float64(d.limit.Burst), updated: now}
+			l.buckets[key] = b
+		}
+		b.tokens = math.Min(float64(d.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*d.limit.Rate)
+		b.updated = now
+		if b.tokens < 1 {
+			d.allowed = false
+			d.retryAfter = seconds((1 - b.tokens) / d.limit.Rate)
+		}
+		if d.allowed && l.cfg.DailyQuota > 0 && l.daily[client] >= l.cfg.DailyQuota {
+			d.allowed, d.quota = false, true
+		}
+		if d.allowed {
+			b.tokens--
+		}
+		d.remaining = int(b.tokens)
+		d.reset = seconds((float64(d.limit.Burst) - b.tokens) / d.limit.Rate)
+	} else if l.cfg.DailyQuota > 0 && l.daily[client] >= l.cfg.DailyQuota {
+		d.allowed, d.quota = false, true
+	}
+	if d.quota {
+		midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		d.retryAfter = midnight.Sub(now)
+	}
+
+	u := l.pending[usageKey{day, client, class}]
+	if u == nil {
+		u = &model.APIUsage{Date: day, Client: client, RouteClass: class}
+		l.pending[usageKey{day, client, class}] = u
+	}
+	if d.allowed {
+		l.daily[client]++
+		u.Requests++
+	} else {
+		u.Rejected++
+	}
+	return d
+}
+
+// sweep usuwa kubełki nieużywane dłużej niż bucketIdle.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdle {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > bucketIdle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// seconds zaokrągla czas podany w sekundach w górę do pełnych sekund.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
+
+// Allow zalicza żądanie klienta do klasy tras class. Żądanie ponad limit
+// daje ErrRateLimited albo ErrDailyQuota i czas, po którym można je
+// ponowić.
+func (l *RateLimiter) Allow(client, class string) (time.Duration, error) {
+	d := l.allow(client, class)
+	switch {
+	case d.quota:
+		return d.retryAfter, ErrDailyQuota
+	case !d.allowed:
+		return d.retryAfter, ErrRateLimited
+	}
+	return 0, nil
+}
+
+// Middleware odrzuca żądania ponad limit odpowiedzią 429 z nagłówkiem
+// Retry-After. Odpowiedzi tras z limitem niosą nagłówki RateLimit-Limit,
+// RateLimit-Remaining i RateLimit-Reset.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := l.cfg.Classify(r)
+		d := l.allow(ClientID(r, l.cfg.APIKeys), class)
+		if d.limit.Rate > 0 {
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(d.limit.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(int(d.reset.Seconds())))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", d.limit.Burst, int(math.Ceil(float64(d.limit.Burst)/d.limit.Rate))))
+		}
+		if !d.allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(d.retryAfter.Seconds())))
+			if d.quota {
+				http.Error(w, "Wyczerpano dzienny limit żądań", http.StatusTooManyRequests)
+			} else {
+				http.Error(w, fmt.Sprintf("Przekroczono limit żądań dla tras %s", class), http.StatusTooManyRequests)
+			}
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// takeUsage zwraca zliczenia zebrane od poprzedniego wywołania.
+func (l *RateLimiter) takeUsage() []model.APIUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	usage := make([]model.APIUsage, 0, len(l.pending))
+	for _, u := range l.pending {
+		usage = append(usage, *u)
+	}
+	l.pending = map[usageKey]*model.APIUsage{}
+	// Stała kolejność zapisu chroni przed zakleszczeniem instancji
+	// zapisujących te same wiersze.
+	sort.Slice(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Client != b.Client {
+			return a.Client < b.Client
+		}
+		return a.RouteClass < b.RouteClass
+	})
+	return usage
+}
+
+// putBackUsage przywraca zliczenia, których nie udało się zapisać.
+func (l *RateLimiter) putBackUsage(usage []model.APIUsage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, u := range usage {
+		key := usageKey{u.Date, u.Client, u.RouteClass}
+		if p := l.pending[key]; p != nil {
+			p.Requests += u.Requests
+			p.Rejected += u.Rejected
+			continue
+		}
+		u := u
+		l.pending[key] = &u
+	}
+}
+
+// FlushUsage co interval przekazuje zliczenia żądań do store, aż do
+// zakończenia ctx. Zliczenia, których nie udało się zapisać, czekają na
+// kolejną próbę.
+func (l *RateLimiter) FlushUsage(ctx context.Context, interval time.Duration, store func([]model.APIUsage) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		usage := l.takeUsage()
+		if len(usage) == 0 {
+			continue
+		}
+		if err := store(usage); err != nil {
+			slog.ErrorContext(ctx, "Błąd zapisywania statystyk żądań", "error", err)
+			l.putBackUsage(usage)
+		}
+	}
+}
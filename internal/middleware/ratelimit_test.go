@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want RateLimit
+	}{
+		{"50/s:100", RateLimit{Rate: 50, Burst: 100}},
+		{"6/1m", RateLimit{Rate: 0.1, Burst: 6}},
+		{"1000/h:10", RateLimit{Rate: 1000.0 / 3600, Burst: 10}},
+		{"off", RateLimit{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRateLimit(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRateLimit(%q) - oczekiwano %+v, otrzymano %+v (%v)", tt.in, tt.want, got, err)
+		}
+	}
+	for _, in := range []string{"", "50", "0/s", "x/s", "5/abc", "5/s:0", "5/-1s"} {
+		if _, err := ParseRateLimit(in); err == nil {
+			t.Errorf("ParseRateLimit(%q) - oczekiwano błędu", in)
+		}
+	}
+}
+
+// testKeys to klucze API znane ogranicznikowi w testach.
+var testKeys = map[string]string{"klucz-a": RoleReader, "klucz-b": RoleReader}
+
+func newTestLimiter(cfg RateLimitConfig, now *time.Time) http.Handler {
+	cfg.Classify = func(r *http.Request) string {
+		if r.Method == http.MethodGet {
+			return RouteLookup
+		}
+		return RouteWrite
+	}
+	l := NewRateLimiter(cfg)
+	l.now = func() time.Time { return *now }
+	return l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+}
+
+func send(h http.Handler, method, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/v1/swift-codes/AAISALTRXXX", nil)
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h := newTestLimiter(RateLimitConfig{Limits: map[string]RateLimit{RouteLookup: {Rate: 1, Burst: 2}}, APIKeys: testKeys}, &now)
+
+	for i := 0; i < 2; i++ {
+		if rr := send(h, "GET", "klucz-a"); rr.Code != http.StatusOK {
+			t.Fatalf("Żądanie %d - oczekiwano status 200, otrzymano %d", i+1, rr.Code)
+		}
+	}
+	rr := send(h, "GET", "klucz-a")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Oczekiwano status 429 po wyczerpaniu limitu, otrzymano %d", rr.Code)
+	}
+	for header, want := range map[string]string{"Retry-After": "1", "RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "2", "RateLimit-Policy": "2;w=2"} {
+		if got := rr.Header().Get(header); got != want {
+			t.Errorf("Nagłówek %s - oczekiwano %q, otrzymano %q", header, want, got)
+		}
+	}
+
+	if rr := send(h, "GET", "klucz-b"); rr.Code != http.StatusOK {
+		t.Errorf("Inny klient nie powinien być ograniczony, otrzymano %d", rr.Code)
+	}
+	if rr := send(h, "POST", "klucz-a"); rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("Klasa bez limitu nie powinna być ograniczona, otrzymano %d %v", rr.Code, rr.Header())
+	}
+
+	now = now.Add(time.Second)
+	if rr := send(h, "GET", "klucz-a"); rr.Code != http.StatusOK {
+		t.Errorf("Po sekundzie oczekiwano nowego żetonu, otrzymano %d", rr.Code)
+	}
+}
+
+func TestRateLimiter_DailyQuota(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	h := newTestLimiter(RateLimitConfig{DailyQuota: 2, APIKeys: testKeys}, &now)
+
+	send(h, "GET", "klucz-a")
+	send(h, "POST", "klucz-a")
+	rr := send(h, "GET", "klucz-a")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "3600" {
+		t.Fatalf("Oczekiwano 429 z Retry-After do północy, otrzymano %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+
+	now = now.Add(time.Hour)
+	if rr := send(h, "GET", "klucz-a"); rr.Code != http.StatusOK {
+		t.Errorf("Limit powinien odnowić się o północy, otrzymano %d", rr.Code)
+	}
+}
+
+func TestRateLimiter_FlushUsage(t *testing.T) {
+	l := NewRateLimiter(RateLimitConfig{
+		Limits:   map[string]RateLimit{RouteLookup: {Rate: 1, Burst: 1}},
+		Classify: func(r *http.Request) string { return RouteLookup },
+		APIKeys:  testKeys,
+	})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	send(h, "GET", "klucz-a")
+	send(h, "GET", "klucz-a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stored := make(chan []model.APIUsage, 1)
+	attempts := 0
+	go l.FlushUsage(ctx, time.Millisecond, func(usage []model.APIUsage) error {
+		// Pierwszy zapis się nie udaje; zliczenia muszą poczekać na kolejny.
+		if attempts++; attempts == 1 {
+			return errors.New("awaria bazy")
+		}
+		stored <- usage
+		cancel()
+		return nil
+	})
+
+	select {
+	case usage := <-stored:
+		if len(usage) != 1 || usage[0].Client != clientOf("klucz-a") || usage[0].Requests != 1 || usage[0].Rejected != 1 {
+			t.Errorf("Nieoczekiwane statystyki: %+v", usage)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Statystyki nie zostały zapisane")
+	}
+}
+
+func TestRateLimiter_UnknownKeys(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(RateLimitConfig{
+		Limits:     map[string]RateLimit{RouteLookup: {Rate: 1, Burst: 2}},
+		DailyQuota: 100,
+		Classify:   func(r *http.Request) string { return RouteLookup },
+		APIKeys:    testKeys,
+	})
+	l.now = func() time.Time { return now }
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		codes = append(codes, send(h, "GET", fmt.Sprintf("losowy-%d", i)).Code)
+	}
+	if codes[2] != http.StatusTooManyRequests {
+		t.Errorf("Nowy nieznany klucz z tego samego adresu nie powinien omijać limitu, otrzymano %v", codes)
+	}
+	if rr := send(h, "GET", "klucz-a"); rr.Code != http.StatusOK {
+		t.Errorf("Skonfigurowany klucz powinien mieć własny kubełek, otrzymano %d", rr.Code)
+	}
+	if len(l.buckets) != 2 || len(l.daily) != 2 {
+		t.Errorf("Oczekiwano kubełków adresu IP i klucza klucz-a, otrzymano %d kubełków i %d liczników", len(l.buckets), len(l.daily))
+	}
+}
+
+func clientOf(apiKey string) string {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(APIKeyHeader, apiKey)
+	return ClientID(req, map[string]string{apiKey: RoleReader})
+}
+
+func TestClientID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	if got := ClientID(req, testKeys); got != "ip:192.0.2.10" {
+		t.Errorf("Oczekiwano ip:192.0.2.10, otrzymano %q", got)
+	}
+	req.Header.Set(APIKeyHeader, "nieznany")
+	if got := ClientID(req, testKeys); got != "ip:192.0.2.10" {
+		t.Errorf("Nieznany klucz nie powinien identyfikować klienta, otrzymano %q", got)
+	}
+	if got := clientOf("klucz-a"); got == clientOf("klucz-b") || len(got) != len("key:")+12 {
+		t.Errorf("Oczekiwano różnych skrótów kluczy, otrzymano %q i %q", got, clientOf("klucz-b"))
+	}
+}
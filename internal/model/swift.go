@@ -1,11 +1,346 @@
 package model
 
+import (
+	"encoding/xml"
+	"time"
+
+	"swift-codes/internal/bic"
+)
+
+// SwiftCode to pojedynczy wpis katalogu. Odpowiedź dla oddziału zawiera
+// odnośnik do centrali (Headquarter); gdy zamiast nieznanego oddziału zwrócono
+// jego centralę, ustawione są RequestedSwiftCode i HeadquarterFallback.
+// HeadquarterCode to zapisane w bazie powiązanie oddziału z centralą.
 type SwiftCode struct {
-	BankName      string      `json:"bankName"`      
-	Address       string      `json:"address"`       
-	CountryISO2   string      `json:"countryISO2"`  
-	CountryName   string      `json:"countryName"`   
-	IsHeadquarter bool        `json:"isHeadquarter"`
-	SwiftCode     string      `json:"swiftCode"`    
-	Branches      []SwiftCode `json:"branches,omitempty"`
+	XMLName             xml.Name        `json:"-" xml:"swiftCodeEntry"`
+	BankName            string          `json:"bankName" xml:"bankName"`
+	Address             string          `json:"address" xml:"address"`
+	CountryISO2         string          `json:"countryISO2" xml:"countryISO2"`
+	CountryName         string          `json:"countryName" xml:"countryName"`
+	IsHeadquarter       bool            `json:"isHeadquarter" xml:"isHeadquarter"`
+	SwiftCode           string          `json:"swiftCode" xml:"swiftCode"`
+	Branches            []SwiftCode     `json:"branches,omitempty" xml:"branches>swiftCodeEntry,omitempty"`
+	Headquarter         *HeadquarterRef `json:"headquarter,omitempty" xml:"headquarter,omitempty"`
+	RequestedSwiftCode  string          `json:"requestedSwiftCode,omitempty" xml:"requestedSwiftCode,omitempty"`
+	HeadquarterFallback bool            `json:"headquarterFallback,omitempty" xml:"headquarterFallback,omitempty"`
+	Version             int64           `json:"-" xml:"-"`
+	UpdatedAt           time.Time       `json:"-" xml:"-"`
+	HeadquarterCode     string          `json:"-" xml:"-"`
+}
+
+type HeadquarterRef struct {
+	SwiftCode string `json:"swiftCode" xml:"swiftCode"`
+	BankName  string `json:"bankName,omitempty" xml:"bankName,omitempty"`
+	Version   int64  `json:"-" xml:"-"`
+}
+
+type CountrySwiftCodes struct {
+	XMLName     xml.Name    `json:"-" xml:"country"`
+	CountryISO2 string      `json:"countryISO2" xml:"countryISO2"`
+	CountryName string      `json:"countryName" xml:"countryName"`
+	SwiftCodes  []SwiftCode `json:"swiftCodes" xml:"swiftCodes>swiftCodeEntry"`
+}
+
+type CountrySummary struct {
+	XMLName      xml.Name `json:"-" xml:"country"`
+	CountryISO2  string   `json:"countryISO2" xml:"countryISO2"`
+	CountryName  string   `json:"countryName" xml:"countryName"`
+	Headquarters int      `json:"headquarters" xml:"headquarters"`
+	Branches     int      `json:"branches" xml:"branches"`
+}
+
+type CountryList struct {
+	XMLName   xml.Name         `json:"-" xml:"countries"`
+	Countries []CountrySummary `json:"countries" xml:"country"`
+}
+
+// Bank to instytucja wyznaczona przez pierwsze 8 znaków kodu SWIFT.
+type Bank struct {
+	XMLName     xml.Name    `json:"-" xml:"bank"`
+	BIC8        string      `json:"bic8" xml:"bic8"`
+	BankName    string      `json:"bankName" xml:"bankName"`
+	CountryISO2 string      `json:"countryISO2" xml:"countryISO2"`
+	CountryName string      `json:"countryName" xml:"countryName"`
+	Headquarter *SwiftCode  `json:"headquarter" xml:"headquarter>swiftCodeEntry,omitempty"`
+	Branches    []SwiftCode `json:"branches" xml:"branches>swiftCodeEntry"`
+}
+
+// NewBank składa instytucję z jej kodów: kod zakończony na XXX jest
+// centralą, pozostałe to oddziały. Lista kodów nie może być pusta.
+func NewBank(bic8 string, codes []SwiftCode) Bank {
+	bank := Bank{BIC8: bic8, Branches: []SwiftCode{}}
+	for i := range codes {
+		if bic.IsHeadquarter(codes[i].SwiftCode) {
+			bank.Headquarter = &codes[i]
+		} else {
+			bank.Branches = append(bank.Branches, codes[i])
+		}
+	}
+
+	source := codes[0]
+	if bank.Headquarter != nil {
+		source = *bank.Headquarter
+	}
+	bank.BankName = source.BankName
+	bank.CountryISO2 = source.CountryISO2
+	bank.CountryName = source.CountryName
+	return bank
+}
+
+type BankSummary struct {
+	XMLName        xml.Name `json:"-" xml:"bank"`
+	BIC8           string   `json:"bic8" xml:"bic8"`
+	BankName       string   `json:"bankName" xml:"bankName"`
+	HasHeadquarter bool     `json:"hasHeadquarter" xml:"hasHeadquarter"`
+	Branches       int      `json:"branches" xml:"branches"`
+}
+
+type BankList struct {
+	XMLName     xml.Name      `json:"-" xml:"banks"`
+	CountryISO2 string        `json:"countryISO2" xml:"countryISO2"`
+	CountryName string        `json:"countryName" xml:"countryName"`
+	Banks       []BankSummary `json:"banks" xml:"bank"`
+}
+
+// SwiftCodeFilter zawęża listę kodów; puste pola nie ograniczają wyniku.
+type SwiftCodeFilter struct {
+	CountryISO2   string
+	BIC8          string
+	IsHeadquarter *bool
+	Phrase        string
+}
+
+type SearchResult struct {
+	XMLName    xml.Name    `json:"-" xml:"searchResult"`
+	Query      string      `json:"query" xml:"query"`
+	SwiftCodes []SwiftCode `json:"swiftCodes" xml:"swiftCodes>swiftCodeEntry"`
+}
+
+type LookupRequest struct {
+	SwiftCodes []string `json:"swiftCodes"`
+}
+
+type LookupResult struct {
+	XMLName  xml.Name    `json:"-" xml:"lookupResult"`
+	Found    []SwiftCode `json:"found" xml:"found>swiftCodeEntry"`
+	NotFound []string    `json:"notFound" xml:"notFound>swiftCode"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type BatchItemResult struct {
+	Index     int          `json:"index"`
+	SwiftCode string       `json:"swiftCode"`
+	Status    string       `json:"status"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type BatchResult struct {
+	Mode      string            `json:"mode"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+type CountryMismatch struct {
+	Headquarter        string `json:"headquarter"`
+	HeadquarterCountry string `json:"headquarterCountry"`
+	Branch             string `json:"branch"`
+	BranchCountry      string `json:"branchCountry"`
+}
+
+// DuplicateGroup to kody, które są tym samym kodem po usunięciu białych
+// znaków i zmianie na wielkie litery.
+type DuplicateGroup struct {
+	Normalized string   `json:"normalized"`
+	SwiftCodes []string `json:"swiftCodes"`
+}
+
+type IntegrityReport struct {
+	Checked           int               `json:"checked"`
+	OrphanBranches    []string          `json:"orphanBranches"`
+	CountryMismatches []CountryMismatch `json:"countryMismatches"`
+	Duplicates        []DuplicateGroup  `json:"duplicates"`
+}
+
+// Issues zwraca łączną liczbę wykrytych problemów.
+func (r IntegrityReport) Issues() int {
+	return len(r.OrphanBranches) + len(r.CountryMismatches) + len(r.Duplicates)
+}
+
+// WebhookSubscription to adres powiadamiany o zmianach w katalogu. Puste
+// CountryISO2 i BIC8 oznaczają powiadomienia o wszystkich kodach. Sekret
+// jest zwracany tylko przy tworzeniu subskrypcji.
+type WebhookSubscription struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	CountryISO2 string    `json:"countryISO2,omitempty"`
+	BIC8        string    `json:"bic8,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery to zdarzenie czekające na doręczenie do subskrypcji albo,
+// po wyczerpaniu prób, odłożone na listę niedoręczonych (Dead == true).
+type WebhookDelivery struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscriptionId"`
+	URL            string    `json:"url"`
+	EventID        string    `json:"eventId"`
+	EventType      string    `json:"eventType"`
+	SwiftCode      string    `json:"swiftCode"`
+	Payload        []byte    `json:"-"`
+	Secret         string    `json:"-"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"lastError,omitempty"`
+	Dead           bool      `json:"-"`
+	NextAttemptAt  time.Time `json:"-"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Typy zmian w katalogu.
+const (
+	ChangeCreated = "swift_code.created"
+	ChangeUpdated = "swift_code.updated"
+	ChangeDeleted = "swift_code.deleted"
+)
+
+// Change to pojedyncza zmiana katalogu. Sequence rośnie z każdą zmianą
+// w kolejności zatwierdzania. Record to stan wpisu po zmianie, a dla
+// ChangeDeleted stan sprzed usunięcia.
+type Change struct {
+	Sequence   int64     `json:"sequence"`
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	SwiftCode  string    `json:"swiftCode"`
+	Record     SwiftCode `json:"record"`
+}
+
+type ChangeFeed struct {
+	Changes        []Change `json:"changes"`
+	NextSince      int64    `json:"nextSince"`
+	LatestSequence int64    `json:"latestSequence"`
+	HasMore        bool     `json:"hasMore"`
+}
+
+// OutboxCursor to pozycja odbiorcy zdarzeń w dzienniku zmian.
+type OutboxCursor struct {
+	Sink      string    `json:"sink"`
+	Position  int64     `json:"position"`
+	Pending   int64     `json:"pending"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// APIUsage to liczba żądań klienta do jednej klasy tras w ciągu doby (UTC):
+// przyjętych (Requests) i odrzuconych przez limity (Rejected).
+type APIUsage struct {
+	Date       string `json:"date"`
+	Client     string `json:"client"`
+	RouteClass string `json:"routeClass"`
+	Requests   int64  `json:"requests"`
+	Rejected   int64  `json:"rejected"`
+}
+
+// UsageReport to dzienny raport wykorzystania API przez klientów.
+type UsageReport struct {
+	Date       string        `json:"date"`
+	DailyQuota int64         `json:"dailyQuota,omitempty"`
+	Clients    []ClientUsage `json:"clients"`
+}
+
+type ClientUsage struct {
+	Client       string                     `json:"client"`
+	Requests     int64                      `json:"requests"`
+	Rejected     int64                      `json:"rejected"`
+	RouteClasses map[string]RouteClassUsage `json:"routeClasses"`
+}
+
+type RouteClassUsage struct {
+	Requests int64 `json:"requests"`
+	Rejected int64 `json:"rejected"`
+}
+
+// Stany wersji danych (Snapshot.Status).
+const (
+	SnapshotLoading  = "loading"
+	SnapshotReady    = "ready"
+	SnapshotInvalid  = "invalid"
+	SnapshotActive   = "active"
+	SnapshotInactive = "inactive"
+)
+
+// Snapshot to nazwana wersja całego katalogu. Import zapisuje nową wersję
+// obok aktywnej; po walidacji można ją aktywować, a wcześniejsze wersje
+// pozostają do porównania i przywrócenia. Problems to błędy walidacji, przez
+// które wersji nie można aktywować.
+type Snapshot struct {
+	ID          int64            `json:"id"`
+	Name        string           `json:"name"`
+	Source      string           `json:"source,omitempty"`
+	Status      string           `json:"status"`
+	Records     int              `json:"records"`
+	Problems    []string         `json:"problems,omitempty"`
+	Integrity   *IntegrityReport `json:"integrity,omitempty"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	ActivatedAt *time.Time       `json:"activatedAt,omitempty"`
+}
+
+type SnapshotList struct {
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// SnapshotDiff to różnice między wersjami From i To: rekordy dodane w To,
+// usunięte z From i zmienione.
+type SnapshotDiff struct {
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Added   []SwiftCode       `json:"added"`
+	Removed []SwiftCode       `json:"removed"`
+	Changed []SwiftCodeChange `json:"changed"`
+}
+
+type SwiftCodeChange struct {
+	Before SwiftCode `json:"before"`
+	After  SwiftCode `json:"after"`
+}
+
+// SnapshotActivation to wynik aktywacji wersji: liczby rekordów dodanych,
+// usuniętych i zmienionych względem poprzednio aktywnej wersji.
+type SnapshotActivation struct {
+	Active   Snapshot `json:"active"`
+	Previous string   `json:"previous"`
+	Added    int      `json:"added"`
+	Removed  int      `json:"removed"`
+	Changed  int      `json:"changed"`
+}
+
+// BankCode przypisuje krajowy identyfikator banku do kodu SWIFT. BankCode
+// może być pełnym identyfikatorem z numeru IBAN (np. polskim numerem
+// rozliczeniowym) albo jego początkiem, np. numerem banku.
+type BankCode struct {
+	CountryISO2 string `json:"countryISO2"`
+	BankCode    string `json:"bankCode"`
+	SwiftCode   string `json:"swiftCode"`
+	BankName    string `json:"bankName,omitempty"`
+}
+
+// IBANResult to sprawdzony numer IBAN z kodami SWIFT banku, wskazanymi przez
+// tabelę krajowych identyfikatorów banków. MatchedBankCode to wpis tabeli,
+// który pasował do identyfikatora z numeru.
+type IBANResult struct {
+	XMLName         xml.Name    `json:"-" xml:"ibanResult"`
+	IBAN            string      `json:"iban" xml:"iban"`
+	Formatted       string      `json:"formatted" xml:"formatted"`
+	CountryISO2     string      `json:"countryISO2" xml:"countryISO2"`
+	CheckDigits     string      `json:"checkDigits" xml:"checkDigits"`
+	BBAN            string      `json:"bban" xml:"bban"`
+	BankCode        string      `json:"bankCode" xml:"bankCode"`
+	BranchCode      string      `json:"branchCode,omitempty" xml:"branchCode,omitempty"`
+	MatchedBankCode string      `json:"matchedBankCode,omitempty" xml:"matchedBankCode,omitempty"`
+	SwiftCodes      []SwiftCode `json:"swiftCodes" xml:"swiftCodes>swiftCodeEntry"`
 }
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNewBank(t *testing.T) {
+	codes := []SwiftCode{
+		{BankName: "PKO BANK POLSKI S.A.", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "BPKOPLPWBIA"},
+		{BankName: "PKO BANK POLSKI S.A.", CountryISO2: "PL", CountryName: "POLAND", SwiftCode: "BPKOPLPWKRK"},
+		{BankName: "PKO BANK POLSKI", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "BPKOPLPWXXX"},
+	}
+
+	bank := NewBank("BPKOPLPW", codes)
+	if bank.Headquarter == nil || bank.Headquarter.SwiftCode != "BPKOPLPWXXX" {
+		t.Fatalf("Oczekiwano centrali BPKOPLPWXXX, otrzymano %+v", bank.Headquarter)
+	}
+	if bank.BankName != "PKO BANK POLSKI" {
+		t.Errorf("Oczekiwano nazwy banku z centrali, otrzymano %q", bank.BankName)
+	}
+	if len(bank.Branches) != 2 {
+		t.Errorf("Oczekiwano 2 oddziałów, otrzymano %d", len(bank.Branches))
+	}
+
+	bank = NewBank("BPKOPLPW", codes[:1])
+	if bank.Headquarter != nil || bank.BankName != "PKO BANK POLSKI S.A." || bank.CountryISO2 != "PL" {
+		t.Errorf("Bank bez centrali - nieoczekiwany wynik %+v", bank)
+	}
+}
@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"embed"
+	"net/http"
+	"strings"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docs []byte
+
+// swaggerUI to pliki swagger-ui-dist 5.18.2 (licencja Apache 2.0) dołączone
+// do programu, więc dokumentacja działa także w sieci bez dostępu do
+// internetu.
+//
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+func Spec() []byte {
+	return spec
+}
+
+func SpecHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// DocsHandler serwuje stronę dokumentacji pod /docs i pliki Swagger UI pod
+// /docs/swagger-ui/.
+func DocsHandler() http.HandlerFunc {
+	assets := http.StripPrefix("/docs/", http.FileServer(http.FS(swaggerUI)))
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/docs":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(docs)
+		case strings.HasPrefix(r.URL.Path, "/docs/swagger-ui/") && !strings.HasSuffix(r.URL.Path, "/"):
+			w.Header().Set("Cache-Control", "public, max-age=86400")
+			assets.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDocsHandler(t *testing.T) {
+	h := DocsHandler()
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	page := get("/docs")
+	if page.Code != http.StatusOK || strings.Contains(page.Body.String(), "https://") {
+		t.Errorf("Strona dokumentacji nie powinna korzystać z zewnętrznych adresów, otrzymano %d: %s", page.Code, page.Body.String())
+	}
+	for path, contentType := range map[string]string{
+		"/docs/swagger-ui/swagger-ui-bundle.js": "text/javascript",
+		"/docs/swagger-ui/swagger-ui.css":       "text/css",
+	} {
+		rr := get(path)
+		if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), contentType) || !strings.Contains(page.Body.String(), path) {
+			t.Errorf("%s - oczekiwano pliku %s wskazanego przez stronę, otrzymano %d %q", path, contentType, rr.Code, rr.Header().Get("Content-Type"))
+		}
+	}
+	for _, path := range []string{"/docs/swagger-ui/", "/docs/missing.js", "/docsx"} {
+		if rr := get(path); rr.Code != http.StatusNotFound {
+			t.Errorf("%s - oczekiwano status 404, otrzymano %d", path, rr.Code)
+		}
+	}
+}
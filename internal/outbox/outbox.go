@@ -0,0 +1,247 @@
+// Package outbox przekazuje zmiany z dziennika zmian (tabela
+// swift_code_changes, zapisywana w tej samej transakcji co zmiana rekordu)
+// do odbiorców: logu, kolejek webhooków, pliku. Każdy odbiorca ma w bazie
+// własną pozycję w dzienniku, przesuwaną dopiero po doręczeniu, więc zmiana
+// dociera co najmniej raz, także po restarcie serwera. Zmiany jednej
+// instytucji (BIC8) docierają w kolejności numerów.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+	"swift-codes/internal/webhook"
+)
+
+// Sink to odbiorca zmian. Deliver może dostać tę samą zmianę więcej niż raz
+// (np. po restarcie przed zapisaniem pozycji); rozpoznaje się ją po ID.
+type Sink interface {
+	// Name identyfikuje pozycję odbiorcy w dzienniku, więc musi być stała
+	// między uruchomieniami.
+	Name() string
+	Deliver(ctx context.Context, c model.Change) error
+}
+
+type Config struct {
+	// PollInterval to odstęp między sprawdzeniami dziennika.
+	PollInterval time.Duration
+	// BatchSize to liczba zmian pobieranych z dziennika naraz.
+	BatchSize int
+	// MaxPending ogranicza liczbę zmian czekających w pamięci na ponowienie;
+	// po jej osiągnięciu nowe zmiany nie są pobierane.
+	MaxPending int
+	// Backoff to odstęp przed drugą próbą; każdy kolejny jest dwa razy
+	// dłuższy, ale nie dłuższy niż MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Lease to czas dzierżawy odbiorcy. Przy kilku instancjach serwera
+	// zmiany do danego odbiorcy przekazuje tylko jedna z nich.
+	Lease time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		MaxPending:   10000,
+		Backoff:      time.Second,
+		MaxBackoff:   5 * time.Minute,
+		Lease:        30 * time.Second,
+	}
+}
+
+type Dispatcher struct {
+	db    *sql.DB
+	cfg   Config
+	sinks []Sink
+	owner string
+}
+
+func NewDispatcher(dbConn *sql.DB, cfg Config, sinks ...Sink) *Dispatcher {
+	owner := make([]byte, 8)
+	rand.Read(owner)
+	return &Dispatcher{db: dbConn, cfg: cfg, sinks: sinks, owner: hex.EncodeToString(owner)}
+}
+
+// Run przekazuje zmiany do wszystkich odbiorców do zakończenia ctx. Każdy
+// odbiorca działa niezależnie, więc awaria jednego nie wstrzymuje pozostałych.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sink := range d.sinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.runSink(ctx, sink)
+		}()
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) runSink(ctx context.Context, sink Sink) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	var r *relay
+	for {
+		var more bool
+		r, more = d.poll(ctx, sink, r)
+		// Pełna porcja oznacza, że w dzienniku mogą czekać kolejne zmiany.
+		if more && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll wykonuje jeden krok przekazywania zmian do odbiorcy. Zwraca stan
+// odbiorcy (nil bez dzierżawy) i true, jeśli pobrano pełną porcję zmian.
+func (d *Dispatcher) poll(ctx context.Context, sink Sink, r *relay) (*relay, bool) {
+	position, ok, err := db.ClaimOutbox(d.db, sink.Name(), d.owner, d.cfg.Lease)
+	if err != nil {
+		slog.ErrorContext(ctx, "Błąd odnawiania dzierżawy odbiorcy zmian", "error", err, "sink", sink.Name())
+		return r, false
+	}
+	if !ok {
+		return nil, false
+	}
+	// Pozycja różna od ostatnio zapisanej oznacza, że odbiorcę obsługiwała
+	// w międzyczasie inna instancja albo zapis pozycji się nie powiódł.
+	if r == nil || r.position != position {
+		r = newRelay(sink, d.cfg, position)
+	}
+
+	r.retry(ctx)
+	var changes []model.Change
+	if !r.full() {
+		if changes, err = db.ListChanges(d.db, r.scanned, d.cfg.BatchSize); err != nil {
+			slog.ErrorContext(ctx, "Błąd pobierania zmian z dziennika", "error", err, "sink", sink.Name())
+			return r, false
+		}
+		r.offer(ctx, changes)
+	}
+
+	if p := r.committed(); p > r.position {
+		err := db.SetOutboxPosition(d.db, sink.Name(), d.owner, p)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Błąd zapisywania pozycji odbiorcy zmian", "error", err, "sink", sink.Name())
+			return r, false
+		}
+		r.position = p
+	}
+	return r, len(changes) == d.cfg.BatchSize
+}
+
+// relay to stan przekazywania zmian do jednego odbiorcy. Zmiana, której nie
+// udało się doręczyć, wstrzymuje późniejsze zmiany tej samej instytucji do
+// czasu ponowienia; zmiany pozostałych instytucji idą dalej.
+type relay struct {
+	sink Sink
+	cfg  Config
+	now  func() time.Time
+
+	// position to numer zapisany w bazie: wszystkie zmiany do niego
+	// włącznie zostały doręczone.
+	position int64
+	// scanned to numer ostatniej zmiany pobranej z dziennika.
+	scanned int64
+	// queues trzyma niedoręczone zmiany wstrzymanych instytucji
+	// w kolejności numerów.
+	queues   map[string][]model.Change
+	attempts map[string]int
+	retryAt  map[string]time.Time
+	pending  int
+}
+
+func newRelay(sink Sink, cfg Config, position int64) *relay {
+	return &relay{
+		sink:     sink,
+		cfg:      cfg,
+		now:      time.Now,
+		position: position,
+		scanned:  position,
+		queues:   map[string][]model.Change{},
+		attempts: map[string]int{},
+		retryAt:  map[string]time.Time{},
+	}
+}
+
+func (r *relay) full() bool {
+	return r.pending >= r.cfg.MaxPending
+}
+
+// offer doręcza nowe zmiany z dziennika albo dołącza je do kolejki
+// wstrzymanej instytucji.
+func (r *relay) offer(ctx context.Context, changes []model.Change) {
+	for _, c := range changes {
+		if ctx.Err() != nil {
+			return
+		}
+		key := db.BIC8(c.SwiftCode)
+		if len(r.queues[key]) > 0 {
+			r.queues[key] = append(r.queues[key], c)
+			r.pending++
+		} else if !r.deliver(ctx, key, c) {
+			r.queues[key] = []model.Change{c}
+			r.pending++
+		}
+		r.scanned = c.Sequence
+	}
+}
+
+// retry ponawia kolejki instytucji, dla których minął czas oczekiwania.
+func (r *relay) retry(ctx context.Context) {
+	for key, queue := range r.queues {
+		if r.now().Before(r.retryAt[key]) {
+			continue
+		}
+		for len(queue) > 0 && r.deliver(ctx, key, queue[0]) {
+			queue = queue[1:]
+			r.pending--
+		}
+		if len(queue) == 0 {
+			delete(r.queues, key)
+		} else {
+			r.queues[key] = queue
+		}
+	}
+}
+
+func (r *relay) deliver(ctx context.Context, key string, c model.Change) bool {
+	err := r.sink.Deliver(ctx, c)
+	if err == nil {
+		delete(r.attempts, key)
+		delete(r.retryAt, key)
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	r.attempts[key]++
+	r.retryAt[key] = r.now().Add(webhook.Backoff(r.attempts[key], r.cfg.Backoff, r.cfg.MaxBackoff))
+	slog.WarnContext(ctx, "Nie udało się przekazać zmiany", "sink", r.sink.Name(), "sequence", c.Sequence, "swiftCode", c.SwiftCode, "attempts", r.attempts[key], "error", err)
+	return false
+}
+
+// committed zwraca numer, do którego włącznie wszystkie zmiany zostały
+// doręczone.
+func (r *relay) committed() int64 {
+	p := r.scanned
+	for _, queue := range r.queues {
+		p = min(p, queue[0].Sequence-1)
+	}
+	return p
+}
@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+// fakeSink zapisuje doręczone zmiany i odrzuca zmiany instytucji z failing.
+type fakeSink struct {
+	delivered []int64
+	failing   map[string]bool
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Deliver(ctx context.Context, c model.Change) error {
+	if s.failing[db.BIC8(c.SwiftCode)] {
+		return errors.New("odbiorca niedostępny")
+	}
+	s.delivered = append(s.delivered, c.Sequence)
+	return nil
+}
+
+func change(seq int64, code string) model.Change {
+	return model.Change{Sequence: seq, ID: code, Type: model.ChangeUpdated, SwiftCode: code}
+}
+
+func TestRelayKeepsOrderPerInstitution(t *testing.T) {
+	sink := &fakeSink{failing: map[string]bool{"AAAAPLPW": true}}
+	cfg := DefaultConfig()
+	r := newRelay(sink, cfg, 10)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	r.offer(context.Background(), []model.Change{
+		change(11, "BBBBPLPWXXX"),
+		change(12, "AAAAPLPWXXX"),
+		change(13, "BBBBPLPWKRK"),
+		change(14, "AAAAPLPWKRK"),
+	})
+	if got := sink.delivered; len(got) != 2 || got[0] != 11 || got[1] != 13 {
+		t.Fatalf("Oczekiwano doręczenia zmian 11 i 13, otrzymano %v", got)
+	}
+	if p := r.committed(); p != 11 {
+		t.Errorf("Pozycja nie może minąć niedoręczonej zmiany 12, otrzymano %d", p)
+	}
+
+	// Przed upływem odstępu zmiany wstrzymanej instytucji nie są ponawiane.
+	delete(sink.failing, "AAAAPLPW")
+	r.retry(context.Background())
+	if len(sink.delivered) != 2 {
+		t.Fatalf("Ponowienie przed upływem odstępu, doręczono %v", sink.delivered)
+	}
+
+	now = now.Add(cfg.Backoff)
+	r.retry(context.Background())
+	if got := sink.delivered; len(got) != 4 || got[2] != 12 || got[3] != 14 {
+		t.Fatalf("Oczekiwano doręczenia zmian 12 i 14 w kolejności, otrzymano %v", got)
+	}
+	if p := r.committed(); p != 14 || r.pending != 0 {
+		t.Errorf("Oczekiwano pozycji 14 bez zaległości, otrzymano %d (%d zaległych)", p, r.pending)
+	}
+}
+
+func TestRelayStopsReadingWhenFull(t *testing.T) {
+	sink := &fakeSink{failing: map[string]bool{"AAAAPLPW": true}}
+	cfg := DefaultConfig()
+	cfg.MaxPending = 2
+	r := newRelay(sink, cfg, 0)
+	r.offer(context.Background(), []model.Change{change(1, "AAAAPLPWXXX"), change(2, "AAAAPLPWKRK")})
+	if !r.full() {
+		t.Error("Oczekiwano wstrzymania pobierania po osiągnięciu MaxPending")
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.ndjson")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink nie powiodło się: %v", err)
+	}
+	for _, c := range []model.Change{change(1, "AAAAPLPWXXX"), change(2, "BBBBPLPWXXX")} {
+		if err := sink.Deliver(context.Background(), c); err != nil {
+			t.Fatalf("Deliver nie powiodło się: %v", err)
+		}
+	}
+	sink.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Nie udało się otworzyć pliku: %v", err)
+	}
+	defer f.Close()
+	var got []int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var c model.Change
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			t.Fatalf("Nieprawidłowa linia %q: %v", scanner.Text(), err)
+		}
+		got = append(got, c.Sequence)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("Oczekiwano zmian 1 i 2, otrzymano %v", got)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	connStr := os.Getenv("TEST_DB_CONN")
+	if connStr == "" {
+		t.Fatal("Brak ustawionej zmiennej środowiskowej TEST_DB_CONN")
+	}
+	testDB, err := db.InitDB(connStr)
+	if err != nil {
+		t.Fatalf("InitDB nie powiodło się: %v", err)
+	}
+	defer testDB.Close()
+	if _, err := testDB.Exec("DELETE FROM outbox_cursors WHERE sink = 'fake'"); err != nil {
+		t.Fatalf("Nie udało się usunąć pozycji odbiorcy: %v", err)
+	}
+
+	sink := &fakeSink{}
+	cfg := DefaultConfig()
+	d := NewDispatcher(testDB, cfg, sink)
+	r, _ := d.poll(context.Background(), sink, nil)
+	if r == nil {
+		t.Fatal("Oczekiwano przejęcia dzierżawy odbiorcy")
+	}
+	start := r.position
+
+	record := model.SwiftCode{BankName: "OUTBOX BANK", Address: "HQ", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "OUTBPLPWXXX"}
+	if err := db.InsertSwiftCode(testDB, record); err != nil {
+		t.Fatalf("InsertSwiftCode nie powiodło się: %v", err)
+	}
+	if err := db.DeleteSwiftCode(testDB, record.SwiftCode); err != nil {
+		t.Fatalf("DeleteSwiftCode nie powiodło się: %v", err)
+	}
+
+	r, _ = d.poll(context.Background(), sink, r)
+	if len(sink.delivered) < 2 || r.position != sink.delivered[len(sink.delivered)-1] {
+		t.Fatalf("Oczekiwano doręczenia zmian po %d i zapisania pozycji, otrzymano %v (pozycja %d)", start, sink.delivered, r.position)
+	}
+
+	other := NewDispatcher(testDB, cfg, sink)
+	if r, _ := other.poll(context.Background(), sink, nil); r != nil {
+		t.Error("Druga instancja nie powinna przejąć dzierżawionego odbiorcy")
+	}
+	position, ok, err := db.ClaimOutbox(testDB, sink.Name(), d.owner, cfg.Lease)
+	if err != nil || !ok || position != r.position {
+		t.Errorf("Oczekiwano zapisanej pozycji %d, otrzymano %d (%v, %v)", r.position, position, ok, err)
+	}
+}
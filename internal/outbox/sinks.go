@@ -0,0 +1,73 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+
+	"swift-codes/internal/model"
+	"swift-codes/internal/webhook"
+)
+
+// LogSink zapisuje każdą zmianę w logu serwera.
+type LogSink struct{}
+
+func (LogSink) Name() string { return "log" }
+
+func (LogSink) Deliver(ctx context.Context, c model.Change) error {
+	slog.InfoContext(ctx, "Zmiana w katalogu", "sequence", c.Sequence, "id", c.ID, "type", c.Type, "swiftCode", c.SwiftCode)
+	return nil
+}
+
+// WebhookSink dodaje zmianę do kolejek pasujących subskrypcji webhooków.
+// Zmiana już obecna w kolejce nie jest dodawana ponownie.
+type WebhookSink struct {
+	db *sql.DB
+}
+
+func NewWebhookSink(dbConn *sql.DB) *WebhookSink {
+	return &WebhookSink{db: dbConn}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Deliver(ctx context.Context, c model.Change) error {
+	return webhook.Enqueue(s.db, c)
+}
+
+// FileSink dopisuje zmiany do pliku, po jednym obiekcie JSON w linii.
+// Zmiana jest uznawana za doręczoną dopiero po zapisaniu pliku na dysk.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: f}, nil
+}
+
+func (s *FileSink) Name() string { return "file" }
+
+func (s *FileSink) Deliver(ctx context.Context, c model.Change) error {
+	line, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"swift-codes/internal/bic"
+	"swift-codes/internal/model"
+)
+
+// ParseBankCodesCSV wczytuje tabelę krajowych identyfikatorów banków
+// (np. data/bank_codes.csv) z kolumnami COUNTRY ISO2 CODE, NATIONAL BANK CODE,
+// SWIFT CODE i opcjonalnie NAME. Kod SWIFT może być kodem BIC8, który
+// wskazuje całą instytucję, albo kodem BIC11 konkretnego oddziału.
+func ParseBankCodesCSV(filePath string) ([]model.BankCode, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("nie udało się otworzyć pliku: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd odczytu CSV: %w", err)
+	}
+
+	var codes []model.BankCode
+	for i, record := range records {
+		if i == 0 {
+			continue
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("nieprawidłowy format w wierszu %d", i+1)
+		}
+
+		bc := model.BankCode{
+			CountryISO2: strings.ToUpper(strings.TrimSpace(record[0])),
+			BankCode:    bic.Normalize(record[1]),
+			SwiftCode:   bic.Normalize(record[2]),
+		}
+		if len(record) > 3 {
+			bc.BankName = strings.ToUpper(strings.TrimSpace(record[3]))
+		}
+		if len(bc.CountryISO2) != 2 || bc.BankCode == "" {
+			return nil, fmt.Errorf("brak kodu kraju lub identyfikatora banku w wierszu %d", i+1)
+		}
+		if err := bic.Validate(bc.SwiftCode); err != nil {
+			return nil, fmt.Errorf("nieprawidłowy kod SWIFT w wierszu %d: %w", i+1, err)
+		}
+		codes = append(codes, bc)
+	}
+	return codes, nil
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseBankCodesCSV(t *testing.T) {
+	path, err := createTempCSV(`COUNTRY ISO2 CODE,NATIONAL BANK CODE,SWIFT CODE,NAME
+pl,102,BPKOPLPW,PKO Bank Polski S.A.
+PL,1140 2004, brexplpwxxx,
+`)
+	if err != nil {
+		t.Fatalf("Nie udało się utworzyć pliku tymczasowego: %v", err)
+	}
+	defer os.Remove(path)
+
+	codes, err := ParseBankCodesCSV(path)
+	if err != nil {
+		t.Fatalf("ParseBankCodesCSV nie powiodło się: %v", err)
+	}
+	if len(codes) != 2 {
+		t.Fatalf("Oczekiwano 2 wpisów, otrzymano %d", len(codes))
+	}
+	if c := codes[0]; c.CountryISO2 != "PL" || c.BankCode != "102" || c.SwiftCode != "BPKOPLPW" || c.BankName != "PKO BANK POLSKI S.A." {
+		t.Errorf("Nieprawidłowy wpis: %+v", c)
+	}
+	if c := codes[1]; c.BankCode != "11402004" || c.SwiftCode != "BREXPLPWXXX" {
+		t.Errorf("Oczekiwano znormalizowanego identyfikatora i kodu, otrzymano %+v", c)
+	}
+}
+
+func TestParseBankCodesCSV_InvalidSwiftCode(t *testing.T) {
+	path, err := createTempCSV(`COUNTRY ISO2 CODE,NATIONAL BANK CODE,SWIFT CODE,NAME
+PL,102,BPKO1,PKO BANK POLSKI S.A.
+`)
+	if err != nil {
+		t.Fatalf("Nie udało się utworzyć pliku tymczasowego: %v", err)
+	}
+	defer os.Remove(path)
+
+	if _, err := ParseBankCodesCSV(path); err == nil {
+		t.Error("Oczekiwano błędu dla nieprawidłowego kodu SWIFT")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"swift-codes/internal/bic"
 	"swift-codes/internal/model"
 )
 
@@ -35,7 +36,7 @@ func ParseCSV(filePath string) ([]model.SwiftCode, error) {
 		}
 
 		countryISO2 := strings.ToUpper(strings.TrimSpace(record[0]))
-		swiftCode := strings.TrimSpace(record[1])
+		swiftCode := bic.Normalize(record[1])
 		bankName := strings.ToUpper(strings.TrimSpace(record[3]))
 		address := strings.TrimSpace(record[4])
 		countryName := strings.ToUpper(strings.TrimSpace(record[6]))
@@ -54,28 +55,20 @@ func ParseCSV(filePath string) ([]model.SwiftCode, error) {
 		swiftCodes = append(swiftCodes, sc)
 	}
 
-	headquarterMap := make(map[string]*model.SwiftCode)
+	headquarterMap := make(map[string]int)
 	var result []model.SwiftCode
 
-	for i, sc := range swiftCodes {
+	for _, sc := range swiftCodes {
 		if sc.IsHeadquarter {
-			prefix := sc.SwiftCode
-			if len(sc.SwiftCode) >= 8 {
-				prefix = sc.SwiftCode[:8]
-			}
-			headquarterMap[prefix] = &swiftCodes[i]
-			result = append(result, swiftCodes[i])
+			headquarterMap[bic8(sc.SwiftCode)] = len(result)
+			result = append(result, sc)
 		}
 	}
 
 	for _, sc := range swiftCodes {
 		if !sc.IsHeadquarter {
-			prefix := sc.SwiftCode
-			if len(sc.SwiftCode) >= 8 {
-				prefix = sc.SwiftCode[:8]
-			}
-			if hq, exists := headquarterMap[prefix]; exists {
-				hq.Branches = append(hq.Branches, sc)
+			if i, exists := headquarterMap[bic8(sc.SwiftCode)]; exists {
+				result[i].Branches = append(result[i].Branches, sc)
 			} else {
 				result = append(result, sc)
 			}
@@ -84,3 +77,23 @@ func ParseCSV(filePath string) ([]model.SwiftCode, error) {
 
 	return result, nil
 }
+
+func bic8(code string) string {
+	if len(code) >= 8 {
+		return code[:8]
+	}
+	return code
+}
+
+// Flatten zamienia wynik ParseCSV z powrotem na płaską listę, w której oddziały
+// występują bezpośrednio po swojej centrali.
+func Flatten(records []model.SwiftCode) []model.SwiftCode {
+	var flat []model.SwiftCode
+	for _, sc := range records {
+		branches := sc.Branches
+		sc.Branches = nil
+		flat = append(flat, sc)
+		flat = append(flat, branches...)
+	}
+	return flat
+}
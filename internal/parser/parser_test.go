@@ -92,3 +92,43 @@ BG,ABIEBGS1XXX,BIC11,ABV INVESTMENTS LTD,"TSAR ASEN 20  VARNA, VARNA",BULGARIA
 		t.Error("Oczekiwano błędu parsowania dla nieprawidłowego formatu, ale błąd nie został zgłoszony")
 	}
 }
+
+func TestParseCSV_AttachesBranchesToHeadquarter(t *testing.T) {
+	csvContent := `COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE
+PL,BREXPLPWMBK,BIC11,MBANK S.A. (FORMERLY BRE BANK S.A.),"SENATORSKA 18  WARSZAWA, MAZOWIECKIE, 00-950",WARSZAWA,POLAND,Europe/Warsaw
+PL,BREXPLPWXXX,BIC11,MBANK S.A. (FORMERLY BRE BANK S.A.),"PROSTA 18  WARSZAWA, MAZOWIECKIE, 00-850",WARSZAWA,POLAND,Europe/Warsaw
+PL,BPKOPLPWXXX,BIC11,PKO BANK POLSKI S.A.,"PULAWSKA 15  WARSZAWA, MAZOWIECKIE, 02-515",WARSZAWA,POLAND,Europe/Warsaw
+PL,ALBPPLPWCUS,BIC11,ALIOR BANK SPOLKA AKCYJNA,"LOPUSZANSKA BUSINESS PARK LOPUSZANSKA 38 D  WARSZAWA, MAZOWIECKIE, 02-232",WARSZAWA,POLAND,Europe/Warsaw
+`
+	tmpFile, err := createTempCSV(csvContent)
+	if err != nil {
+		t.Fatalf("Nie udało się utworzyć tymczasowego pliku CSV: %v", err)
+	}
+	defer os.Remove(tmpFile)
+
+	records, err := ParseCSV(tmpFile)
+	if err != nil {
+		t.Fatalf("Błąd parsowania CSV: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Oczekiwano 3 rekordów najwyższego poziomu, otrzymano %d", len(records))
+	}
+	if records[0].SwiftCode != "BREXPLPWXXX" || len(records[0].Branches) != 1 {
+		t.Fatalf("Oczekiwano centrali BREXPLPWXXX z 1 oddziałem, otrzymano %s z %d", records[0].SwiftCode, len(records[0].Branches))
+	}
+	if records[0].Branches[0].SwiftCode != "BREXPLPWMBK" {
+		t.Errorf("Oczekiwano oddziału BREXPLPWMBK, otrzymano %s", records[0].Branches[0].SwiftCode)
+	}
+	if records[2].SwiftCode != "ALBPPLPWCUS" {
+		t.Errorf("Oczekiwano oddziału bez centrali ALBPPLPWCUS na końcu, otrzymano %s", records[2].SwiftCode)
+	}
+
+	flat := Flatten(records)
+	if len(flat) != 4 {
+		t.Fatalf("Oczekiwano 4 rekordów po spłaszczeniu, otrzymano %d", len(flat))
+	}
+	if flat[1].SwiftCode != "BREXPLPWMBK" || flat[0].Branches != nil {
+		t.Errorf("Oczekiwano oddziału BREXPLPWMBK zaraz po centrali bez zagnieżdżonych oddziałów")
+	}
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"encoding/csv"
+	"io"
+
+	"swift-codes/internal/model"
+)
+
+// CSVHeader to układ kolumn pliku data/swiftcodes_data.csv. Plik zapisany
+// przez Writer można ponownie wczytać przez ParseCSV.
+var CSVHeader = []string{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"}
+
+type Writer struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: csv.NewWriter(w)}
+}
+
+// Write zapisuje rekord bez oddziałów. Nazwa miasta i strefa czasowa nie są
+// przechowywane w bazie, więc kolumny TOWN NAME i TIME ZONE pozostają puste.
+func (w *Writer) Write(sc model.SwiftCode) error {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if err := w.w.Write(CSVHeader); err != nil {
+			return err
+		}
+	}
+	codeType := "BIC11"
+	if len(sc.SwiftCode) == 8 {
+		codeType = "BIC8"
+	}
+	return w.w.Write([]string{sc.CountryISO2, sc.SwiftCode, codeType, sc.BankName, sc.Address, "", sc.CountryName, ""})
+}
+
+func (w *Writer) Flush() error {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.w.Write(CSVHeader)
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"os"
+	"testing"
+
+	"swift-codes/internal/model"
+)
+
+func TestWriter_RoundTrip(t *testing.T) {
+	records := []model.SwiftCode{
+		{BankName: "PKO BANK POLSKI S.A.", Address: "PULAWSKA 15  WARSZAWA, MAZOWIECKIE, 02-515", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, SwiftCode: "BPKOPLPWXXX"},
+		{BankName: "PKO BANK POLSKI S.A.", Address: "", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: false, SwiftCode: "BPKOPLPWBIA"},
+	}
+
+	tmpFile, err := os.CreateTemp("", "export_*.csv")
+	if err != nil {
+		t.Fatalf("Nie udało się utworzyć pliku tymczasowego: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	w := NewWriter(tmpFile)
+	for _, sc := range records {
+		if err := w.Write(sc); err != nil {
+			t.Fatalf("Write nie powiodło się: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush nie powiodło się: %v", err)
+	}
+	tmpFile.Close()
+
+	parsed, err := ParseCSV(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Błąd parsowania wyeksportowanego pliku: %v", err)
+	}
+	flat := Flatten(parsed)
+	if len(flat) != len(records) {
+		t.Fatalf("Oczekiwano %d rekordów, otrzymano %d", len(records), len(flat))
+	}
+	for i := range records {
+		if flat[i].SwiftCode != records[i].SwiftCode || flat[i].Address != records[i].Address || flat[i].IsHeadquarter != records[i].IsHeadquarter {
+			t.Errorf("Rekord %d - oczekiwano %+v, otrzymano %+v", i, records[i], flat[i])
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package tlsreload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile to pakiet certyfikatów CA (PEM), którymi weryfikowane są
+	// certyfikaty klientów. Pusty oznacza brak weryfikacji klientów.
+	ClientCAFile string
+	ClientAuth   tls.ClientAuthType
+	// Interval to odstęp sprawdzania, czy pliki się zmieniły.
+	Interval time.Duration
+}
+
+// ParseClientAuth odczytuje tryb uwierzytelniania klientów: "none" (bez
+// certyfikatów klientów), "request" (weryfikacja certyfikatu, jeśli klient go
+// przedstawi) lub "require" (certyfikat wymagany).
+func ParseClientAuth(s string) (tls.ClientAuthType, error) {
+	switch s {
+	case "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("oczekiwano none, request lub require, otrzymano %q", s)
+}
+
+// fileState to stan pliku, po którym rozpoznawana jest jego zmiana.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader trzyma konfigurację TLS wczytaną z plików i wczytuje ją ponownie,
+// gdy pliki się zmienią, bez przerywania działania serwera. Nowe połączenia
+// dostają aktualny certyfikat i pakiet CA; nieudane wczytanie zostawia
+// poprzednią konfigurację.
+type Reloader struct {
+	cfg     Config
+	current atomic.Pointer[tls.Config]
+	files   []fileState
+}
+
+func New(cfg Config) (*Reloader, error) {
+	if cfg.ClientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, errors.New("weryfikacja certyfikatów klientów wymaga pakietu CA")
+	}
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) paths() []string {
+	paths := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		paths = append(paths, r.cfg.ClientCAFile)
+	}
+	return paths
+}
+
+func (r *Reloader) stat() ([]fileState, error) {
+	var states []fileState
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, fileState{info.ModTime(), info.Size()})
+	}
+	return states, nil
+}
+
+// Reload wczytuje certyfikat, klucz i pakiet CA.
+func (r *Reloader) Reload() error {
+	states, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("błąd wczytywania certyfikatu: %w", err)
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   r.cfg.ClientAuth,
+		// Konfiguracja zastępuje w całości tę z http.Server i gRPC, więc
+		// musi sama ogłaszać HTTP/2.
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("błąd wczytywania pakietu CA: %w", err)
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("pakiet CA %s nie zawiera certyfikatów", r.cfg.ClientCAFile)
+		}
+	}
+	r.current.Store(config)
+	r.files = states
+	return nil
+}
+
+// ServerConfig zwraca konfigurację dla http.Server albo gRPC, która przy
+// każdym połączeniu używa aktualnie wczytanych plików.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+func (r *Reloader) changed() bool {
+	states, err := r.stat()
+	if err != nil {
+		// Plik może chwilowo nie istnieć w trakcie podmiany.
+		return false
+	}
+	for i := range states {
+		if states[i] != r.files[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Run co cfg.Interval sprawdza pliki i wczytuje je ponownie po zmianie, aż do
+// zakończenia ctx.
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !r.changed() {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			slog.ErrorContext(ctx, "Błąd ponownego wczytywania konfiguracji TLS", "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "Wczytano nową konfigurację TLS", "cert", r.cfg.CertFile)
+	}
+}
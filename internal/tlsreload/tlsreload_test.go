@@ -0,0 +1,187 @@
+package tlsreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issue tworzy certyfikat podpisany przez parent (albo samopodpisany CA, gdy
+// parent jest nil) i zwraca go razem z kluczem.
+func issue(t *testing.T, cn string, parent *tls.Certificate, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"Test Bank"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	signer, signerKey := template, any(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func writePEM(t *testing.T, certPath, keyPath string, cert tls.Certificate) {
+	t.Helper()
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	if err := os.WriteFile(certPath, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyPath == "" {
+		return
+	}
+	der, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake łączy się z serwerem i zwraca nazwę (CN) jego certyfikatu.
+func handshake(t *testing.T, addr string, ca *x509.Certificate, client *tls.Certificate) (string, error) {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	cfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if client != nil {
+		cfg.Certificates = []tls.Certificate{*client}
+	}
+	conn, err := tls.Dial("tcp", addr, cfg)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	// W TLS 1.3 serwer odrzuca certyfikat klienta już po zakończeniu
+	// uzgadniania, więc błąd pojawia się dopiero przy odczycie.
+	conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !isTimeout(err) {
+		return "", err
+	}
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath, caPath := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+	ca := issue(t, "Test CA", nil, x509.ExtKeyUsageAny)
+	writePEM(t, caPath, "", ca)
+	writePEM(t, certPath, keyPath, issue(t, "server-1", &ca, x509.ExtKeyUsageServerAuth))
+
+	r, err := New(Config{CertFile: certPath, KeyFile: keyPath, ClientCAFile: caPath, ClientAuth: tls.RequireAndVerifyClientCert, Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("New nie powiodło się: %v", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", r.ServerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+				conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				conn.Read(make([]byte, 1))
+			}()
+		}
+	}()
+	addr := listener.Addr().String()
+
+	client := issue(t, "payments", &ca, x509.ExtKeyUsageClientAuth)
+	if cn, err := handshake(t, addr, ca.Leaf, &client); err != nil || cn != "server-1" {
+		t.Fatalf("Oczekiwano połączenia z server-1, otrzymano %q (%v)", cn, err)
+	}
+	if _, err := handshake(t, addr, ca.Leaf, nil); err == nil {
+		t.Error("Połączenie bez certyfikatu klienta powinno zostać odrzucone")
+	}
+	other := issue(t, "Other CA", nil, x509.ExtKeyUsageAny)
+	stranger := issue(t, "stranger", &other, x509.ExtKeyUsageClientAuth)
+	if _, err := handshake(t, addr, ca.Leaf, &stranger); err == nil {
+		t.Error("Certyfikat spoza pakietu CA powinien zostać odrzucony")
+	}
+
+	if r.changed() {
+		t.Error("Pliki nie zmieniły się, a zgłoszono zmianę")
+	}
+	writePEM(t, certPath, keyPath, issue(t, "server-2", &ca, x509.ExtKeyUsageServerAuth))
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certPath, future, future)
+	if !r.changed() {
+		t.Fatal("Oczekiwano wykrycia zmiany certyfikatu")
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload nie powiodło się: %v", err)
+	}
+	if cn, err := handshake(t, addr, ca.Leaf, &client); err != nil || cn != "server-2" {
+		t.Errorf("Po zmianie plików oczekiwano certyfikatu server-2, otrzymano %q (%v)", cn, err)
+	}
+
+	os.WriteFile(keyPath, []byte("uszkodzony"), 0o600)
+	if err := r.Reload(); err == nil {
+		t.Error("Oczekiwano błędu dla uszkodzonego klucza")
+	}
+	if cn, err := handshake(t, addr, ca.Leaf, &client); err != nil || cn != "server-2" {
+		t.Errorf("Nieudane wczytanie powinno zostawić poprzedni certyfikat, otrzymano %q (%v)", cn, err)
+	}
+}
+
+func TestParseClientAuth(t *testing.T) {
+	for in, want := range map[string]tls.ClientAuthType{
+		"none":    tls.NoClientCert,
+		"request": tls.VerifyClientCertIfGiven,
+		"require": tls.RequireAndVerifyClientCert,
+	} {
+		if got, err := ParseClientAuth(in); err != nil || got != want {
+			t.Errorf("ParseClientAuth(%q) - oczekiwano %v, otrzymano %v (%v)", in, want, got, err)
+		}
+	}
+	if _, err := ParseClientAuth("always"); err == nil {
+		t.Error("Oczekiwano błędu dla nieznanego trybu")
+	}
+	if _, err := New(Config{CertFile: "a", KeyFile: "b", ClientAuth: tls.RequireAndVerifyClientCert}); err == nil {
+		t.Error("Weryfikacja klientów bez pakietu CA powinna być odrzucona")
+	}
+}
@@ -0,0 +1,310 @@
+"use strict";
+
+// The UI talks only to the public JSON API. The API key is kept in
+// sessionStorage, so it is forgotten when the browser tab is closed.
+
+const keyStorage = "swift-codes-api-key";
+const view = document.getElementById("view");
+const message = document.getElementById("message");
+let identity = { role: "reader", authRequired: true };
+// flash is shown after the next navigation.
+let flash = "";
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    if (name.startsWith("on")) {
+      node.addEventListener(name.slice(2), value);
+    } else if (value === true) {
+      node.setAttribute(name, "");
+    } else if (value !== false && value != null) {
+      node.setAttribute(name, value);
+    }
+  }
+  for (const child of children.flat()) {
+    if (child != null) {
+      node.append(child);
+    }
+  }
+  return node;
+}
+
+function link(href, text) {
+  return el("a", { href }, text);
+}
+
+function navigate(hash, text) {
+  flash = text;
+  location.hash = hash;
+}
+
+function notify(text, isError) {
+  message.textContent = text;
+  message.className = isError ? "error" : "";
+  message.hidden = !text;
+}
+
+class APIError extends Error {
+  constructor(status, text) {
+    super(text || `HTTP ${status}`);
+    this.status = status;
+  }
+}
+
+async function api(method, path, { body, headers } = {}) {
+  const init = { method, headers: { Accept: "application/json", ...headers } };
+  const key = sessionStorage.getItem(keyStorage);
+  if (key) {
+    init.headers["X-API-Key"] = key;
+  }
+  if (body !== undefined) {
+    init.headers["Content-Type"] = "application/json";
+    init.body = JSON.stringify(body);
+  }
+  const response = await fetch(path, init);
+  if (!response.ok) {
+    throw new APIError(response.status, (await response.text()).trim());
+  }
+  const data = response.status === 204 ? null : await response.json();
+  return { data, etag: response.headers.get("ETag") };
+}
+
+function canWrite() {
+  return identity.role === "writer" || identity.role === "admin";
+}
+
+async function loadIdentity() {
+  try {
+    identity = (await api("GET", "/v1/auth/whoami")).data;
+  } catch (err) {
+    identity = { role: "reader", authRequired: true };
+    if (err.status === 401) {
+      sessionStorage.removeItem(keyStorage);
+      notify("Unknown API key", true);
+    }
+  }
+  const signedIn = sessionStorage.getItem(keyStorage) !== null;
+  document.getElementById("identity").textContent = identity.authRequired ? `role: ${identity.role}` : "";
+  document.getElementById("api-key").hidden = signedIn || !identity.authRequired;
+  document.querySelector("#auth button[type=submit]").hidden = signedIn || !identity.authRequired;
+  document.getElementById("sign-out").hidden = !signedIn;
+  document.getElementById("nav-new").hidden = !canWrite();
+}
+
+function codeLink(code) {
+  return link(`#/codes/${encodeURIComponent(code)}`, code);
+}
+
+function table(headers, rows) {
+  if (rows.length === 0) {
+    return el("p", { class: "muted" }, "Nothing found.");
+  }
+  return el("table", {},
+    el("thead", {}, el("tr", {}, headers.map((h) => el("th", {}, h)))),
+    el("tbody", {}, rows.map((cells) => el("tr", {}, cells.map((c) =>
+      typeof c === "number" ? el("td", { class: "number" }, String(c)) : el("td", {}, c))))));
+}
+
+function codeRows(codes) {
+  return codes.map((sc) => [codeLink(sc.swiftCode), sc.bankName, sc.address, sc.countryISO2, sc.isHeadquarter ? "headquarter" : "branch"]);
+}
+
+const codeHeaders = ["SWIFT code", "Bank", "Address", "Country", "Type"];
+
+async function searchView(params) {
+  const q = params.get("q") || "";
+  const input = el("input", { name: "q", value: q, placeholder: "Code, bank name or address", size: 40, autofocus: true });
+  view.append(el("form", {
+    class: "toolbar",
+    onsubmit: (event) => {
+      event.preventDefault();
+      location.hash = `#/?q=${encodeURIComponent(input.value.trim())}`;
+    },
+  }, input, el("button", { type: "submit" }, "Search")));
+  if (q.trim().length < 2) {
+    view.append(el("p", { class: "muted" }, "Type at least 2 characters."));
+    return;
+  }
+  const { data } = await api("GET", `/v1/swift-codes/search?q=${encodeURIComponent(q)}&limit=100`);
+  view.append(table(codeHeaders, codeRows(data.swiftCodes)));
+}
+
+async function countriesView() {
+  const { data } = await api("GET", "/v1/countries");
+  view.append(el("h2", {}, "Countries"), table(["Country", "ISO2", "Headquarters", "Branches"],
+    data.countries.map((c) => [link(`#/countries/${c.countryISO2}`, c.countryName), c.countryISO2, c.headquarters, c.branches])));
+}
+
+async function banksView(iso2) {
+  const { data } = await api("GET", `/v1/banks?country=${encodeURIComponent(iso2)}`);
+  view.append(el("h2", {}, `Banks in ${data.countryName || data.countryISO2}`), table(["BIC8", "Bank", "Headquarter", "Branches"],
+    data.banks.map((b) => [link(`#/banks/${b.bic8}`, b.bic8), b.bankName, b.hasHeadquarter ? "yes" : "no", b.branches])));
+}
+
+async function bankView(bic8) {
+  const { data } = await api("GET", `/v1/banks/${encodeURIComponent(bic8)}`);
+  const codes = data.headquarter ? [data.headquarter, ...data.branches] : data.branches;
+  view.append(
+    el("h2", {}, `${data.bankName} (${data.bic8})`),
+    el("p", {}, link(`#/countries/${data.countryISO2}`, data.countryName)),
+    table(codeHeaders, codeRows(codes)));
+}
+
+function recordForm(record, submitLabel, onsubmit) {
+  const fields = {
+    swiftCode: el("input", { id: "swiftCode", name: "swiftCode", value: record.swiftCode || "", required: true, maxlength: 11, readonly: Boolean(record.swiftCode) }),
+    bankName: el("input", { id: "bankName", name: "bankName", value: record.bankName || "", required: true }),
+    address: el("input", { id: "address", name: "address", value: record.address || "" }),
+    countryISO2: el("input", { id: "countryISO2", name: "countryISO2", value: record.countryISO2 || "", required: true, maxlength: 2 }),
+    countryName: el("input", { id: "countryName", name: "countryName", value: record.countryName || "", required: true }),
+  };
+  const labels = { swiftCode: "SWIFT code", bankName: "Bank name", address: "Address", countryISO2: "Country ISO2", countryName: "Country name" };
+  return el("form", {
+    class: "record",
+    onsubmit: async (event) => {
+      event.preventDefault();
+      const values = Object.fromEntries(Object.entries(fields).map(([name, input]) => [name, input.value.trim()]));
+      values.isHeadquarter = values.swiftCode.toUpperCase().endsWith("XXX");
+      try {
+        await onsubmit(values);
+      } catch (err) {
+        notify(err.message, true);
+      }
+    },
+  },
+  Object.entries(fields).map(([name, input]) => [el("label", { for: name }, labels[name]), input]),
+  el("div", { class: "actions" }, el("button", { type: "submit" }, submitLabel)));
+}
+
+function deleteForm(record, etag) {
+  const hasBranches = record.isHeadquarter && (record.branches || []).length > 0;
+  const mode = el("select", { name: "branches" },
+    el("option", { value: "" }, "server default"),
+    el("option", { value: "refuse" }, "refuse"),
+    el("option", { value: "cascade" }, "delete branches"),
+    el("option", { value: "reparent" }, "move branches to"));
+  const newHeadquarter = el("input", { name: "newHeadquarter", placeholder: "New headquarter", maxlength: 11, hidden: true });
+  mode.addEventListener("change", () => {
+    newHeadquarter.hidden = mode.value !== "reparent";
+  });
+  return el("form", {
+    class: "toolbar",
+    onsubmit: async (event) => {
+      event.preventDefault();
+      if (!confirm(`Delete ${record.swiftCode}?`)) {
+        return;
+      }
+      const params = new URLSearchParams();
+      if (mode.value) {
+        params.set("branches", mode.value);
+      }
+      if (mode.value === "reparent") {
+        params.set("newHeadquarter", newHeadquarter.value.trim());
+      }
+      try {
+        const { data } = await api("DELETE", `/v1/swift-codes/${encodeURIComponent(record.swiftCode)}?${params}`, { headers: { "If-Match": etag } });
+        navigate(`#/banks/${record.swiftCode.slice(0, 8)}`, data.message);
+      } catch (err) {
+        notify(err.status === 412 ? "The record was changed by someone else, reload it and try again." : err.message, true);
+      }
+    },
+  },
+  hasBranches ? [el("label", {}, "Branches:"), mode, newHeadquarter] : [],
+  el("button", { type: "submit", class: "danger" }, "Delete"));
+}
+
+async function codeView(code) {
+  const { data: record, etag } = await api("GET", `/v1/swift-codes/${encodeURIComponent(code)}`);
+  view.append(
+    el("h2", {}, record.swiftCode),
+    el("dl", {},
+      el("dt", {}, "Bank"), el("dd", {}, link(`#/banks/${record.swiftCode.slice(0, 8)}`, record.bankName)),
+      el("dt", {}, "Address"), el("dd", {}, record.address || "—"),
+      el("dt", {}, "Country"), el("dd", {}, link(`#/countries/${record.countryISO2}`, `${record.countryName} (${record.countryISO2})`)),
+      el("dt", {}, "Type"), el("dd", {}, record.isHeadquarter ? "headquarter" : "branch"),
+      record.headquarter ? [el("dt", {}, "Headquarter"), el("dd", {}, codeLink(record.headquarter.swiftCode), ` ${record.headquarter.bankName}`)] : []));
+  if (record.headquarterFallback) {
+    notify(`${record.requestedSwiftCode} is not in the directory, showing its headquarter.`);
+  }
+  if (record.branches && record.branches.length > 0) {
+    view.append(el("h3", {}, "Branches"), table(codeHeaders, codeRows(record.branches)));
+  }
+  if (!canWrite() || record.headquarterFallback) {
+    return;
+  }
+  view.append(el("h3", {}, "Edit"), recordForm(record, "Save", async (values) => {
+    const { data } = await api("PUT", `/v1/swift-codes/${encodeURIComponent(record.swiftCode)}`, { body: values, headers: { "If-Match": etag } })
+      .catch((err) => {
+        if (err.status === 412) {
+          err.message = "The record was changed by someone else, reload it and try again.";
+        }
+        throw err;
+      });
+    await render();
+    notify(data.message);
+  }), deleteForm(record, etag));
+}
+
+async function newView() {
+  if (!canWrite()) {
+    view.append(el("p", { class: "muted" }, "Sign in with an API key with the writer role to add records."));
+    return;
+  }
+  view.append(el("h2", {}, "New record"), recordForm({}, "Create", async (values) => {
+    const { data } = await api("POST", "/v1/swift-codes", { body: values });
+    navigate(`#/codes/${encodeURIComponent(values.swiftCode.toUpperCase())}`, data.message);
+  }));
+}
+
+const routes = [
+  [/^\/?$/, (_, params) => searchView(params)],
+  [/^\/countries$/, () => countriesView()],
+  [/^\/countries\/([A-Za-z]{2})$/, (m) => banksView(m[1])],
+  [/^\/banks\/([A-Za-z0-9]{8})$/, (m) => bankView(m[1])],
+  [/^\/codes\/([A-Za-z0-9]{8,11})$/, (m) => codeView(m[1])],
+  [/^\/new$/, () => newView()],
+];
+
+async function render() {
+  const [path, query] = location.hash.replace(/^#/, "").split("?");
+  view.replaceChildren();
+  for (const [pattern, show] of routes) {
+    const match = pattern.exec(path);
+    if (match) {
+      try {
+        await show(match, new URLSearchParams(query));
+      } catch (err) {
+        notify(err.message, true);
+      }
+      return;
+    }
+  }
+  view.append(el("p", { class: "muted" }, "Page not found."));
+}
+
+document.getElementById("auth").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const input = document.getElementById("api-key");
+  if (input.value) {
+    sessionStorage.setItem(keyStorage, input.value);
+    input.value = "";
+  }
+  notify("");
+  await loadIdentity();
+  await render();
+});
+
+document.getElementById("sign-out").addEventListener("click", async () => {
+  sessionStorage.removeItem(keyStorage);
+  notify("");
+  await loadIdentity();
+  await render();
+});
+
+window.addEventListener("hashchange", () => {
+  notify(flash);
+  flash = "";
+  render();
+});
+
+loadIdentity().then(render);
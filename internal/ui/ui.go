@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serwuje panel WWW pod /ui/. Panel korzysta wyłącznie z publicznego
+// API JSON, więc widzi te same dane i uprawnienia co inni klienci.
+func Handler() http.HandlerFunc {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	server := http.StripPrefix("/ui/", http.FileServer(http.FS(files)))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ui" {
+			http.Redirect(w, r, "/ui/", http.StatusMovedPermanently)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/ui/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		server.ServeHTTP(w, r)
+	}
+}
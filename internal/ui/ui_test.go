@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	h := Handler()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/ui/", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "app.js") {
+		t.Fatalf("Oczekiwano strony panelu, otrzymano %d %q", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Content-Security-Policy") == "" {
+		t.Error("Brak nagłówka Content-Security-Policy")
+	}
+
+	for path, want := range map[string]int{
+		"/ui":          http.StatusMovedPermanently,
+		"/ui/app.js":   http.StatusOK,
+		"/ui/brak.js":  http.StatusNotFound,
+		"/uiinnastron": http.StatusNotFound,
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != want {
+			t.Errorf("%s - oczekiwano status %d, otrzymano %d", path, want, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package webhook powiadamia subskrybentów o zmianach w katalogu. Zmiany
+// są zapisywane w bazie (tabela webhook_deliveries) osobno dla każdej
+// pasującej subskrypcji, a Worker doręcza je w tle z ponowieniami.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+// Nagłówki żądania ze zdarzeniem.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// NewSecret losuje sekret subskrypcji.
+func NewSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Sign zwraca podpis HMAC-SHA256 znacznika czasu i treści żądania w postaci
+// "sha256=<hex>". Podpisywany jest tekst "<timestamp>.<body>", więc
+// odbiorca może odrzucać stare, powtórzone żądania.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify sprawdza podpis z nagłówka X-Webhook-Signature w stałym czasie.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Enqueue dodaje zmiany do kolejek pasujących subskrypcji. Treścią żądania
+// jest zmiana w postaci JSON (model.Change). Wywoływane wewnątrz transakcji
+// dodaje zmiany do kolejki razem z ich zapisem w dzienniku.
+func Enqueue(q db.Querier, changes ...model.Change) error {
+	for _, c := range changes {
+		payload, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if _, err := db.EnqueueWebhookEvent(q, c.ID, c.Type, c.SwiftCode, c.Record.CountryISO2, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
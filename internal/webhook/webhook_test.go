@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"swift_code.created"}`)
+	signature := Sign("sekret-subskrypcji", 1700000000, body)
+	if !Verify("sekret-subskrypcji", 1700000000, body, signature) {
+		t.Error("Oczekiwano poprawnego podpisu")
+	}
+	if Verify("inny-sekret-subskrypcji", 1700000000, body, signature) {
+		t.Error("Podpis nie powinien pasować do innego sekretu")
+	}
+	if Verify("sekret-subskrypcji", 1700000001, body, signature) {
+		t.Error("Podpis nie powinien pasować do innego znacznika czasu")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, 10*time.Second, time.Hour); got != tt.want {
+			t.Errorf("Backoff(%d) - oczekiwano %v, otrzymano %v", tt.attempts, tt.want, got)
+		}
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::":    true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"0.0.0.0":              false,
+		"224.0.0.1":            false,
+		"::ffff:192.168.1.1":   false,
+		"::ffff:93.184.216.34": true,
+	}
+	for addr, want := range tests {
+		if got := isPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublic(%s) - oczekiwano %v, otrzymano %v", addr, want, got)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if !Verify("sekret-subskrypcji", timestamp, body, r.Header.Get(HeaderSignature)) {
+			t.Error("Odebrano żądanie z nieprawidłowym podpisem")
+		}
+		if r.Header.Get(HeaderEvent) != model.ChangeDeleted || r.Header.Get(HeaderID) != "zdarzenie-1" {
+			t.Errorf("Nieoczekiwane nagłówki zdarzenia: %v", r.Header)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	d := model.WebhookDelivery{URL: server.URL, Secret: "sekret-subskrypcji", EventID: "zdarzenie-1", EventType: model.ChangeDeleted, Payload: []byte(`{}`)}
+	if err := NewWorker(nil, DefaultWorkerConfig()).deliver(context.Background(), d); !errors.Is(err, ErrPrivateTarget) {
+		t.Errorf("Domyślnie oczekiwano odrzucenia adresu pętli zwrotnej, otrzymano %v", err)
+	}
+
+	cfg := DefaultWorkerConfig()
+	cfg.AllowPrivateTargets = true
+	w := NewWorker(nil, cfg)
+	if err := w.deliver(context.Background(), d); err != nil {
+		t.Errorf("Oczekiwano doręczenia, otrzymano %v", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := w.deliver(context.Background(), d); err == nil {
+		t.Error("Odpowiedź 503 powinna oznaczać nieudane doręczenie")
+	}
+}
+
+func TestWorker(t *testing.T) {
+	connStr := os.Getenv("TEST_DB_CONN")
+	if connStr == "" {
+		t.Fatal("Brak ustawionej zmiennej środowiskowej TEST_DB_CONN")
+	}
+	testDB, err := db.InitDB(connStr)
+	if err != nil {
+		t.Fatalf("InitDB nie powiodło się: %v", err)
+	}
+	defer testDB.Close()
+	if _, err := testDB.Exec("TRUNCATE TABLE webhook_subscriptions CASCADE"); err != nil {
+		t.Fatalf("Nie udało się wyczyścić subskrypcji: %v", err)
+	}
+
+	var received atomic.Int32
+	var lastEvent model.Change
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		json.NewDecoder(r.Body).Decode(&lastEvent)
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	for _, sub := range []model.WebhookSubscription{
+		{URL: ok.URL, Secret: "sekret-subskrypcji", BIC8: "BPKOPLPW"},
+		{URL: failing.URL, Secret: "sekret-subskrypcji"},
+		{URL: ok.URL, Secret: "sekret-subskrypcji", CountryISO2: "DE"},
+	} {
+		if _, err := db.CreateWebhookSubscription(testDB, sub); err != nil {
+			t.Fatalf("CreateWebhookSubscription nie powiodło się: %v", err)
+		}
+	}
+
+	event := model.Change{ID: "zdarzenie-1", Type: model.ChangeUpdated, SwiftCode: "BPKOPLPWKRK", Record: model.SwiftCode{SwiftCode: "BPKOPLPWKRK", CountryISO2: "PL"}}
+	if err := Enqueue(testDB, event); err != nil {
+		t.Fatalf("Enqueue nie powiodło się: %v", err)
+	}
+
+	cfg := DefaultWorkerConfig()
+	cfg.MaxAttempts = 2
+	cfg.Backoff = 0
+	cfg.AllowPrivateTargets = true
+	w := NewWorker(testDB, cfg)
+	for i := 0; i < cfg.MaxAttempts; i++ {
+		w.deliverDue(context.Background())
+	}
+
+	if received.Load() != 1 || lastEvent.ID != event.ID || lastEvent.Record.SwiftCode != "BPKOPLPWKRK" {
+		t.Errorf("Oczekiwano jednego doręczenia zdarzenia %s, otrzymano %d (%+v)", event.ID, received.Load(), lastEvent)
+	}
+	dead, err := db.ListDeadWebhookDeliveries(testDB, 0)
+	if err != nil {
+		t.Fatalf("ListDeadWebhookDeliveries nie powiodło się: %v", err)
+	}
+	if len(dead) != 1 || dead[0].URL != failing.URL || dead[0].Attempts != cfg.MaxAttempts {
+		t.Errorf("Oczekiwano zdarzenia niedoręczonego do %s po %d próbach, otrzymano %+v", failing.URL, cfg.MaxAttempts, dead)
+	}
+	if n := w.deliverDue(context.Background()); n != 0 {
+		t.Errorf("Niedoręczone zdarzenia nie powinny być ponawiane, pobrano %d", n)
+	}
+}
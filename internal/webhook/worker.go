@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/model"
+)
+
+// maxErrorLength ogranicza długość zapisywanego opisu błędu doręczenia.
+const maxErrorLength = 500
+
+type WorkerConfig struct {
+	// PollInterval to odstęp między sprawdzeniami kolejki.
+	PollInterval time.Duration
+	// BatchSize to liczba zdarzeń pobieranych z kolejki naraz.
+	BatchSize int
+	// MaxAttempts to liczba prób, po której zdarzenie trafia na listę
+	// niedoręczonych.
+	MaxAttempts int
+	// Backoff to odstęp przed drugą próbą; każdy kolejny jest dwa razy
+	// dłuższy, ale nie dłuższy niż MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout ogranicza czas pojedynczego żądania do subskrybenta.
+	Timeout time.Duration
+	// AllowPrivateTargets pozwala doręczać na adresy pętli zwrotnej, sieci
+	// prywatnych i link-local, np. do odbiorcy w tej samej sieci docker.
+	AllowPrivateTargets bool
+}
+
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		MaxAttempts:  8,
+		Backoff:      10 * time.Second,
+		MaxBackoff:   time.Hour,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// Worker doręcza zdarzenia z kolejki. Może działać w wielu instancjach
+// serwera naraz: pobrane zdarzenia są blokowane na czas doręczania.
+type Worker struct {
+	db     *sql.DB
+	cfg    WorkerConfig
+	client *http.Client
+}
+
+// ErrPrivateTarget oznacza adres subskrybenta wskazujący na sieć wewnętrzną.
+var ErrPrivateTarget = errors.New("adres docelowy webhooka nie jest publiczny")
+
+func NewWorker(dbConn *sql.DB, cfg WorkerConfig) *Worker {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowPrivateTargets {
+		// Adres jest sprawdzany przy każdym połączeniu, już po rozwiązaniu
+		// nazwy, więc nie obejdzie go ani wpis DNS zmieniony po rejestracji,
+		// ani przekierowanie. Pośrednik z otoczenia ominąłby to sprawdzenie.
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: publicOnly}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &Worker{db: dbConn, cfg: cfg, client: &http.Client{Timeout: cfg.Timeout, Transport: transport}}
+}
+
+// publicOnly odrzuca połączenie z adresem, który nie jest publiczny.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, ip)
+	}
+	return nil
+}
+
+// isPublic odrzuca pętlę zwrotną, sieci prywatne, adresy link-local (w tym
+// 169.254.169.254 z metadanymi chmury), adres nieokreślony i multicast.
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// Run doręcza zdarzenia do zakończenia ctx.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Pełna porcja oznacza, że w kolejce mogą czekać kolejne zdarzenia.
+		if w.deliverDue(ctx) == w.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue doręcza jedną porcję zdarzeń i zwraca jej wielkość.
+func (w *Worker) deliverDue(ctx context.Context) int {
+	// Blokada musi przetrwać wszystkie żądania porcji, także przekroczenie
+	// czasu każdego z nich.
+	lease := time.Duration(w.cfg.BatchSize)*w.cfg.Timeout + time.Minute
+	deliveries, err := db.ClaimWebhookDeliveries(w.db, w.cfg.BatchSize, lease)
+	if err != nil {
+		slog.ErrorContext(ctx, "Błąd pobierania zdarzeń webhook", "error", err)
+		return 0
+	}
+	for _, d := range deliveries {
+		w.process(ctx, d)
+	}
+	return len(deliveries)
+}
+
+func (w *Worker) process(ctx context.Context, d model.WebhookDelivery) {
+	err := w.deliver(ctx, d)
+	if ctx.Err() != nil {
+		// Zatrzymanie serwera nie jest winą subskrybenta; zdarzenie wróci do
+		// kolejki po wygaśnięciu blokady.
+		return
+	}
+	if err == nil {
+		if err := db.CompleteWebhookDelivery(w.db, d.ID); err != nil {
+			slog.ErrorContext(ctx, "Błąd usuwania doręczonego zdarzenia webhook", "error", err, "delivery", d.ID)
+		}
+		return
+	}
+
+	attempts := d.Attempts + 1
+	dead := attempts >= w.cfg.MaxAttempts
+	message := err.Error()
+	if len(message) > maxErrorLength {
+		message = strings.ToValidUTF8(message[:maxErrorLength], "")
+	}
+	if dead {
+		slog.WarnContext(ctx, "Zdarzenie webhook przeniesione na listę niedoręczonych", "delivery", d.ID, "url", d.URL, "attempts", attempts, "error", message)
+	}
+	if err := db.FailWebhookDelivery(w.db, d.ID, message, time.Now().Add(Backoff(attempts, w.cfg.Backoff, w.cfg.MaxBackoff)), dead); err != nil {
+		slog.ErrorContext(ctx, "Błąd zapisywania nieudanej próby webhook", "error", err, "delivery", d.ID)
+	}
+}
+
+// deliver wysyła podpisane zdarzenie. Sukcesem jest każda odpowiedź 2xx.
+func (w *Worker) deliver(ctx context.Context, d model.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderID, d.EventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, timestamp, d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("odpowiedź %s", resp.Status)
+	}
+	return nil
+}
+
+// Backoff zwraca odstęp przed kolejną próbą po attempts nieudanych:
+// base, 2*base, 4*base, ... ale nie więcej niż max.
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempts && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
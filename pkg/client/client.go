@@ -0,0 +1,384 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"swift-codes/internal/model"
+)
+
+// Aliasy pozwalają używać typów modelu poza tym modułem, bo pakiet
+// internal/model nie jest dla nich importowalny.
+type (
+	SwiftCode         = model.SwiftCode
+	CountrySwiftCodes = model.CountrySwiftCodes
+	CountrySummary    = model.CountrySummary
+	Bank              = model.Bank
+	BankList          = model.BankList
+	IntegrityReport   = model.IntegrityReport
+	SearchResult      = model.SearchResult
+	LookupResult      = model.LookupResult
+	BatchResult       = model.BatchResult
+	IBANResult        = model.IBANResult
+)
+
+var (
+	ErrNotFound           = errors.New("nie znaleziono wpisu")
+	ErrValidation         = errors.New("nieprawidłowe dane")
+	ErrPreconditionFailed = errors.New("wpis został zmieniony lub brak ETag")
+	ErrConflict           = errors.New("wpis już istnieje lub koliduje z bieżącym stanem")
+	ErrRateLimited        = errors.New("przekroczono limit żądań")
+	// ErrUnauthorized oznacza brak klucza API, nieznany klucz albo klucz
+	// z rolą niewystarczającą do wykonania operacji.
+	ErrUnauthorized = errors.New("brak uprawnień")
+)
+
+type APIError struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+	// RetryAfter to czas oczekiwania z nagłówka Retry-After (odpowiedzi 429).
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("swift-codes API: %d %s (request id %s)", e.StatusCode, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("swift-codes API: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed || e.StatusCode == http.StatusPreconditionRequired
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	headers     http.Header
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	retryWrites bool
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.headers.Set("X-API-Key", key) }
+}
+
+// WithTLSConfig ustawia konfigurację TLS połączeń, np. certyfikat klienta
+// dla serwera wymagającego mTLS albo własny pakiet CA.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = cfg
+		hc := *c.httpClient
+		hc.Transport = transport
+		c.httpClient = &hc
+	}
+}
+
+func WithBearerToken(token string) Option {
+	return func(c *Client) { c.headers.Set("Authorization", "Bearer "+token) }
+}
+
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.headers.Set(key, value) }
+}
+
+// WithRetries ustawia liczbę ponowień po błędach 5xx i błędach sieci oraz
+// opóźnienie pierwszego ponowienia, podwajane przy kolejnych próbach (zero
+// oznacza ponawianie bez czekania). Takie
+// błędy są ponawiane tylko dla żądań idempotentnych (GET, HEAD, PUT
+// i wyszukiwania wielu kodów), chyba że ustawiono WithRetryWrites.
+// Odpowiedź 429 jest ponawiana dla każdego żądania po czasie z Retry-After,
+// jeśli nie jest on dłuższy niż maksymalne opóźnienie (5 s); inaczej
+// zwracany jest ErrRateLimited.
+func WithRetries(maxRetries int, baseBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.baseBackoff = baseBackoff
+	}
+}
+
+// WithRetryWrites włącza ponawianie po błędach 5xx i błędach sieci także
+// dla POST i DELETE. Serwer mógł już wykonać takie żądanie przed błędem, więc
+// ponowienie może np. dodać rekord drugi raz albo zwrócić 404 dla usuniętego.
+func WithRetryWrites() Option {
+	return func(c *Client) { c.retryWrites = true }
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		headers:     make(http.Header),
+		maxRetries:  3,
+		baseBackoff: 200 * time.Millisecond,
+		maxBackoff:  5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) GetSwiftCode(ctx context.Context, code string) (SwiftCode, error) {
+	sc, _, err := c.GetSwiftCodeWithETag(ctx, code)
+	return sc, err
+}
+
+// GetHeadquarter zwraca centralę instytucji, do której należy kod; sam kod
+// oddziału nie musi być w katalogu.
+func (c *Client) GetHeadquarter(ctx context.Context, code string) (SwiftCode, error) {
+	var sc SwiftCode
+	_, err := c.do(ctx, http.MethodGet, "/v1/swift-codes/"+url.PathEscape(code)+"/headquarter", nil, nil, &sc)
+	return sc, err
+}
+
+// GetSwiftCodeWithETag zwraca też ETag potrzebny do UpdateSwiftCode i DeleteSwiftCode.
+func (c *Client) GetSwiftCodeWithETag(ctx context.Context, code string) (SwiftCode, string, error) {
+	var sc SwiftCode
+	resp, err := c.do(ctx, http.MethodGet, "/v1/swift-codes/"+url.PathEscape(code), nil, nil, &sc)
+	if err != nil {
+		return sc, "", err
+	}
+	return sc, resp.Header.Get("ETag"), nil
+}
+
+func (c *Client) ListByCountry(ctx context.Context, countryISO2 string) (CountrySwiftCodes, error) {
+	var result CountrySwiftCodes
+	_, err := c.do(ctx, http.MethodGet, "/v1/swift-codes/country/"+url.PathEscape(countryISO2), nil, nil, &result)
+	return result, err
+}
+
+// ListCountries zwraca kraje obecne w katalogu z liczbą central i oddziałów.
+func (c *Client) ListCountries(ctx context.Context) ([]CountrySummary, error) {
+	var result model.CountryList
+	_, err := c.do(ctx, http.MethodGet, "/v1/countries", nil, nil, &result)
+	return result.Countries, err
+}
+
+// GetBank zwraca instytucję o podanym BIC8 z centralą i oddziałami.
+func (c *Client) GetBank(ctx context.Context, bic8 string) (Bank, error) {
+	var result Bank
+	_, err := c.do(ctx, http.MethodGet, "/v1/banks/"+url.PathEscape(bic8), nil, nil, &result)
+	return result, err
+}
+
+// ListBanks zwraca banki danego kraju z liczbą oddziałów.
+func (c *Client) ListBanks(ctx context.Context, countryISO2 string) (BankList, error) {
+	var result BankList
+	_, err := c.do(ctx, http.MethodGet, "/v1/banks?"+url.Values{"country": {countryISO2}}.Encode(), nil, nil, &result)
+	return result, err
+}
+
+// LookupIBAN sprawdza numer IBAN i zwraca kody SWIFT jego banku. Nieprawidłowy
+// numer daje błąd ErrValidation.
+func (c *Client) LookupIBAN(ctx context.Context, iban string) (IBANResult, error) {
+	var result IBANResult
+	_, err := c.do(ctx, http.MethodGet, "/v1/iban/"+url.PathEscape(iban), nil, nil, &result)
+	return result, err
+}
+
+// Integrity zwraca raport spójności hierarchii central i oddziałów.
+func (c *Client) Integrity(ctx context.Context) (IntegrityReport, error) {
+	var report IntegrityReport
+	_, err := c.do(ctx, http.MethodGet, "/v1/admin/integrity", nil, nil, &report)
+	return report, err
+}
+
+// Search szuka frazy w kodach SWIFT i nazwach banków; limit 0 oznacza domyślny limit serwera.
+func (c *Client) Search(ctx context.Context, phrase string, limit int) (SearchResult, error) {
+	params := url.Values{"q": {phrase}}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	var result SearchResult
+	_, err := c.do(ctx, http.MethodGet, "/v1/swift-codes/search?"+params.Encode(), nil, nil, &result)
+	return result, err
+}
+
+// Lookup rozwiązuje wiele kodów BIC8 lub BIC11 jednym żądaniem.
+func (c *Client) Lookup(ctx context.Context, codes []string) (LookupResult, error) {
+	var result LookupResult
+	// Wyszukiwanie niczego nie zmienia, więc jest ponawiane jak GET.
+	_, err := c.roundTrip(ctx, http.MethodPost, "/v1/swift-codes/lookup", nil, model.LookupRequest{SwiftCodes: codes}, &result, true)
+	return result, err
+}
+
+func (c *Client) CreateSwiftCode(ctx context.Context, sc SwiftCode) error {
+	_, err := c.do(ctx, http.MethodPost, "/v1/swift-codes", nil, sc, nil)
+	return err
+}
+
+// Batch zapisuje wiele rekordów naraz. Przy atomic = true serwer zapisuje
+// wszystkie rekordy albo żaden; raport dla poszczególnych rekordów jest
+// zwracany także razem z błędem ErrValidation.
+func (c *Client) Batch(ctx context.Context, records []SwiftCode, atomic bool) (BatchResult, error) {
+	mode := "best-effort"
+	if atomic {
+		mode = "atomic"
+	}
+	var result BatchResult
+	_, err := c.do(ctx, http.MethodPost, "/v1/swift-codes/batch?mode="+mode, nil, records, &result)
+	return result, err
+}
+
+func (c *Client) UpdateSwiftCode(ctx context.Context, sc SwiftCode, etag string) (string, error) {
+	resp, err := c.do(ctx, http.MethodPut, "/v1/swift-codes/"+url.PathEscape(sc.SwiftCode), ifMatch(etag), sc, nil)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *Client) DeleteSwiftCode(ctx context.Context, code, etag string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/v1/swift-codes/"+url.PathEscape(code), ifMatch(etag), nil, nil)
+	return err
+}
+
+func ifMatch(etag string) http.Header {
+	h := make(http.Header)
+	if etag != "" {
+		h.Set("If-Match", etag)
+	}
+	return h
+}
+
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, out any) (*http.Response, error) {
+	idempotent := method == http.MethodGet || method == http.MethodHead || method == http.MethodPut
+	return c.roundTrip(ctx, method, path, header, body, out, idempotent)
+}
+
+// roundTrip wysyła żądanie i ponawia je po 429, a po błędach 5xx i błędach
+// sieci tylko wtedy, gdy jest idempotentne albo włączono WithRetryWrites.
+func (c *Client) roundTrip(ctx context.Context, method, path string, header http.Header, body, out any, idempotent bool) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("błąd kodowania JSON: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, header, payload)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			defer resp.Body.Close()
+			if resp.StatusCode >= http.StatusBadRequest {
+				return resp, readAPIError(resp, out)
+			}
+			if out != nil && resp.StatusCode != http.StatusNoContent {
+				if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+					return resp, fmt.Errorf("błąd dekodowania odpowiedzi: %w", err)
+				}
+			}
+			return resp, nil
+		}
+
+		delay := c.backoff(attempt)
+		retry := idempotent || c.retryWrites
+		if err == nil {
+			err = readAPIError(resp, nil)
+			resp.Body.Close()
+			apiErr := err.(*APIError)
+			if apiErr.RetryAfter > 0 {
+				delay = apiErr.RetryAfter
+			}
+			// Odpowiedź 429 oznacza, że serwer nie wykonał żądania.
+			retry = retry || apiErr.StatusCode == http.StatusTooManyRequests
+		}
+		if !retry || ctx.Err() != nil || attempt >= c.maxRetries || delay > c.maxBackoff {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.httpClient.Do(req)
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	if c.baseBackoff <= 0 {
+		return 0
+	}
+	d := c.baseBackoff << attempt
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// readAPIError czyta treść odpowiedzi z błędem. Jeśli serwer odesłał JSON
+// (np. raport z walidacji), jest on dodatkowo dekodowany do out.
+func readAPIError(resp *http.Response, out any) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if out != nil && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		json.Unmarshal(msg, out)
+	}
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(msg)),
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"swift-codes/internal/db"
+	"swift-codes/internal/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestAPI(t *testing.T) *Client {
+	connStr := os.Getenv("TEST_DB_CONN")
+	if connStr == "" {
+		t.Fatal("Brak ustawionej zmiennej środowiskowej TEST_DB_CONN")
+	}
+	testDB, err := db.InitDB(connStr)
+	if err != nil {
+		t.Fatalf("InitDB nie powiodło się: %v", err)
+	}
+	t.Cleanup(func() { testDB.Close() })
+	if _, err := testDB.Exec("TRUNCATE TABLE swift_codes"); err != nil {
+		t.Fatalf("Nie udało się wyczyścić tabeli: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.GetSwiftCodeHandler(testDB, nil)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes/country/{countryISO2code}", handlers.GetSwiftCodesByCountryHandler(testDB)).Methods("GET")
+	router.HandleFunc("/v1/swift-codes", handlers.CreateSwiftCodeHandler(testDB, nil)).Methods("POST")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.UpdateSwiftCodeHandler(testDB, nil)).Methods("PUT")
+	router.HandleFunc("/v1/swift-codes/{swiftCode}", handlers.DeleteSwiftCodeHandler(testDB, nil, handlers.DeleteOrphan)).Methods("DELETE")
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return New(server.URL, WithRetries(0, 0))
+}
+
+func TestClientAgainstHandlers(t *testing.T) {
+	c := setupTestAPI(t)
+	ctx := context.Background()
+
+	record := SwiftCode{
+		BankName:      "CLIENT TEST BANK",
+		Address:       "CLIENT TEST ADDRESS",
+		CountryISO2:   "PL",
+		CountryName:   "POLAND",
+		IsHeadquarter: true,
+		SwiftCode:     "CLNTPLPWXXX",
+	}
+	if err := c.CreateSwiftCode(ctx, record); err != nil {
+		t.Fatalf("CreateSwiftCode nie powiodło się: %v", err)
+	}
+	if err := c.CreateSwiftCode(ctx, record); !errors.Is(err, ErrConflict) {
+		t.Errorf("Ponowne CreateSwiftCode - oczekiwano ErrConflict, otrzymano %v", err)
+	}
+
+	got, etag, err := c.GetSwiftCodeWithETag(ctx, record.SwiftCode)
+	if err != nil {
+		t.Fatalf("GetSwiftCodeWithETag nie powiodło się: %v", err)
+	}
+	if got.BankName != record.BankName {
+		t.Errorf("Oczekiwano BankName %s, otrzymano %s", record.BankName, got.BankName)
+	}
+
+	country, err := c.ListByCountry(ctx, "pl")
+	if err != nil {
+		t.Fatalf("ListByCountry nie powiodło się: %v", err)
+	}
+	if len(country.SwiftCodes) != 1 {
+		t.Errorf("Oczekiwano 1 rekordu dla kraju PL, otrzymano %d", len(country.SwiftCodes))
+	}
+
+	if err := c.DeleteSwiftCode(ctx, record.SwiftCode, `"nieaktualny"`); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Oczekiwano ErrPreconditionFailed, otrzymano %v", err)
+	}
+	if err := c.DeleteSwiftCode(ctx, record.SwiftCode, etag); err != nil {
+		t.Fatalf("DeleteSwiftCode nie powiodło się: %v", err)
+	}
+
+	_, err = c.GetSwiftCode(ctx, record.SwiftCode)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Oczekiwano ErrNotFound po usunięciu, otrzymano %v", err)
+	}
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "Błąd", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"swiftCode":"AAISALTRXXX","bankName":"UNITED BANK OF ALBANIA SH.A"}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithRetries(3, time.Millisecond))
+	sc, err := c.GetSwiftCode(context.Background(), "AAISALTRXXX")
+	if err != nil {
+		t.Fatalf("GetSwiftCode nie powiodło się: %v", err)
+	}
+	if sc.SwiftCode != "AAISALTRXXX" {
+		t.Errorf("Oczekiwano SwiftCode 'AAISALTRXXX', otrzymano '%s'", sc.SwiftCode)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Oczekiwano 3 prób, otrzymano %d", calls.Load())
+	}
+}
+
+func TestClient_Backoff(t *testing.T) {
+	c := New("http://localhost", WithRetries(3, 0))
+	for attempt := 0; attempt < 3; attempt++ {
+		if d := c.backoff(attempt); d != 0 {
+			t.Errorf("Zerowe opóźnienie - próba %d: oczekiwano 0, otrzymano %v", attempt, d)
+		}
+	}
+	c = New("http://localhost", WithRetries(3, 100*time.Millisecond))
+	for attempt, limit := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		if d := c.backoff(attempt); d < limit/2 || d > limit {
+			t.Errorf("Próba %d: oczekiwano opóźnienia od %v do %v, otrzymano %v", attempt, limit/2, limit, d)
+		}
+	}
+	if d := c.backoff(62); d < c.maxBackoff/2 || d > c.maxBackoff {
+		t.Errorf("Oczekiwano opóźnienia ograniczonego do %v, otrzymano %v", c.maxBackoff, d)
+	}
+}
+
+func TestClient_RetriesWritesOnlyWhenSafe(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "Błąd", status)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	sc := SwiftCode{SwiftCode: "AAISALTRXXX"}
+
+	c := New(server.URL, WithRetries(3, time.Millisecond))
+	if err := c.CreateSwiftCode(context.Background(), sc); err == nil || calls.Load() != 1 {
+		t.Errorf("POST po błędzie 503 nie powinien być ponawiany, otrzymano %v po %d próbach", err, calls.Load())
+	}
+
+	calls.Store(0)
+	status = http.StatusTooManyRequests
+	if err := c.CreateSwiftCode(context.Background(), sc); err != nil || calls.Load() != 2 {
+		t.Errorf("POST po odpowiedzi 429 powinien być ponowiony, otrzymano %v po %d próbach", err, calls.Load())
+	}
+
+	calls.Store(0)
+	status = http.StatusServiceUnavailable
+	c = New(server.URL, WithRetries(3, time.Millisecond), WithRetryWrites())
+	if err := c.CreateSwiftCode(context.Background(), sc); err != nil || calls.Load() != 2 {
+		t.Errorf("Z WithRetryWrites POST powinien być ponowiony, otrzymano %v po %d próbach", err, calls.Load())
+	}
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("X-API-Key") != "sekret" {
+			t.Errorf("Oczekiwano nagłówka X-API-Key 'sekret', otrzymano '%s'", r.Header.Get("X-API-Key"))
+		}
+		w.Header().Set("X-Request-ID", "abc")
+		http.Error(w, "Błędny format danych", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithAPIKey("sekret"), WithRetries(3, time.Millisecond))
+	err := c.CreateSwiftCode(context.Background(), SwiftCode{SwiftCode: "AAISALTRXXX"})
+
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Oczekiwano ErrValidation, otrzymano %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RequestID != "abc" || apiErr.Message != "Błędny format danych" {
+		t.Errorf("Oczekiwano APIError z identyfikatorem żądania i komunikatem, otrzymano %#v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Oczekiwano 1 próby, otrzymano %d", calls.Load())
+	}
+}
+
+func TestClient_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Operacja wymaga roli writer", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithAPIKey("czytelnik"))
+	if err := c.CreateSwiftCode(context.Background(), SwiftCode{SwiftCode: "AAISALTRXXX"}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Oczekiwano ErrUnauthorized, otrzymano %v", err)
+	}
+}
+
+func TestClient_RateLimited(t *testing.T) {
+	var calls atomic.Int32
+	retryAfter := "0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 || retryAfter != "0" {
+			w.Header().Set("Retry-After", retryAfter)
+			http.Error(w, "Przekroczono limit żądań", http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"swiftCode": "AAISALTRXXX"}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithRetries(3, time.Millisecond))
+	if _, err := c.GetSwiftCode(context.Background(), "AAISALTRXXX"); err != nil || calls.Load() != 2 {
+		t.Fatalf("Oczekiwano sukcesu po ponowieniu, otrzymano %v po %d próbach", err, calls.Load())
+	}
+
+	calls.Store(0)
+	retryAfter = "3600"
+	_, err := c.GetSwiftCode(context.Background(), "AAISALTRXXX")
+	var apiErr *APIError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Hour {
+		t.Errorf("Oczekiwano ErrRateLimited z RetryAfter 1h, otrzymano %#v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Odległy Retry-After nie powinien być ponawiany, wykonano %d prób", calls.Load())
+	}
+}
+
+func TestClient_StopsRetryingWhenContextIsDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Błąd", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := New(server.URL, WithRetries(100, 20*time.Millisecond))
+	if _, err := c.GetSwiftCode(ctx, "AAISALTRXXX"); err == nil {
+		t.Error("Oczekiwano błędu po przekroczeniu czasu kontekstu")
+	}
+}
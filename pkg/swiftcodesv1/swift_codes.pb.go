@@ -0,0 +1,1042 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: swiftcodes/v1/swift_codes.proto
+
+package swiftcodesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BranchMode selects what happens to the branches of a deleted headquarter.
+type BranchMode int32
+
+const (
+	// Use the server policy (DELETE_HIERARCHY).
+	BranchMode_BRANCH_MODE_UNSPECIFIED BranchMode = 0
+	// Keep the branches without a headquarter; allowed only when the server
+	// policy is orphan.
+	BranchMode_BRANCH_MODE_ORPHAN BranchMode = 1
+	// Fail with FAILED_PRECONDITION.
+	BranchMode_BRANCH_MODE_REFUSE BranchMode = 2
+	// Delete the branches together with the headquarter.
+	BranchMode_BRANCH_MODE_CASCADE BranchMode = 3
+	// Move the branches to new_headquarter.
+	BranchMode_BRANCH_MODE_REPARENT BranchMode = 4
+)
+
+// Enum value maps for BranchMode.
+var (
+	BranchMode_name = map[int32]string{
+		0: "BRANCH_MODE_UNSPECIFIED",
+		1: "BRANCH_MODE_ORPHAN",
+		2: "BRANCH_MODE_REFUSE",
+		3: "BRANCH_MODE_CASCADE",
+		4: "BRANCH_MODE_REPARENT",
+	}
+	BranchMode_value = map[string]int32{
+		"BRANCH_MODE_UNSPECIFIED": 0,
+		"BRANCH_MODE_ORPHAN":      1,
+		"BRANCH_MODE_REFUSE":      2,
+		"BRANCH_MODE_CASCADE":     3,
+		"BRANCH_MODE_REPARENT":    4,
+	}
+)
+
+func (x BranchMode) Enum() *BranchMode {
+	p := new(BranchMode)
+	*p = x
+	return p
+}
+
+func (x BranchMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BranchMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_swiftcodes_v1_swift_codes_proto_enumTypes[0].Descriptor()
+}
+
+func (BranchMode) Type() protoreflect.EnumType {
+	return &file_swiftcodes_v1_swift_codes_proto_enumTypes[0]
+}
+
+func (x BranchMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BranchMode.Descriptor instead.
+func (BranchMode) EnumDescriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{0}
+}
+
+type SwiftCode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	BankName      string                 `protobuf:"bytes,2,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	CountryIso2   string                 `protobuf:"bytes,4,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,5,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	IsHeadquarter bool                   `protobuf:"varint,6,opt,name=is_headquarter,json=isHeadquarter,proto3" json:"is_headquarter,omitempty"`
+	// Set for headquarters returned by Get.
+	Branches []*SwiftCode `protobuf:"bytes,7,rep,name=branches,proto3" json:"branches,omitempty"`
+	// Set for branches returned by Get when the headquarter exists.
+	Headquarter *HeadquarterRef `protobuf:"bytes,8,opt,name=headquarter,proto3" json:"headquarter,omitempty"`
+	// Record version for optimistic concurrency; ignored by Create.
+	Version       int64 `protobuf:"varint,9,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SwiftCode) Reset() {
+	*x = SwiftCode{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwiftCode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwiftCode) ProtoMessage() {}
+
+func (x *SwiftCode) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwiftCode.ProtoReflect.Descriptor instead.
+func (*SwiftCode) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SwiftCode) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetIsHeadquarter() bool {
+	if x != nil {
+		return x.IsHeadquarter
+	}
+	return false
+}
+
+func (x *SwiftCode) GetBranches() []*SwiftCode {
+	if x != nil {
+		return x.Branches
+	}
+	return nil
+}
+
+func (x *SwiftCode) GetHeadquarter() *HeadquarterRef {
+	if x != nil {
+		return x.Headquarter
+	}
+	return nil
+}
+
+func (x *SwiftCode) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type HeadquarterRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	BankName      string                 `protobuf:"bytes,2,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeadquarterRef) Reset() {
+	*x = HeadquarterRef{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeadquarterRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeadquarterRef) ProtoMessage() {}
+
+func (x *HeadquarterRef) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeadquarterRef.ProtoReflect.Descriptor instead.
+func (*HeadquarterRef) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HeadquarterRef) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *HeadquarterRef) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     *SwiftCode             `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetResponse) GetSwiftCode() *SwiftCode {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return nil
+}
+
+type ListByCountryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryIso2   string                 `protobuf:"bytes,1,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListByCountryRequest) Reset() {
+	*x = ListByCountryRequest{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListByCountryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListByCountryRequest) ProtoMessage() {}
+
+func (x *ListByCountryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListByCountryRequest.ProtoReflect.Descriptor instead.
+func (*ListByCountryRequest) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListByCountryRequest) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+type ListByCountryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryIso2   string                 `protobuf:"bytes,1,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,2,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	SwiftCodes    []*SwiftCode           `protobuf:"bytes,3,rep,name=swift_codes,json=swiftCodes,proto3" json:"swift_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListByCountryResponse) Reset() {
+	*x = ListByCountryResponse{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListByCountryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListByCountryResponse) ProtoMessage() {}
+
+func (x *ListByCountryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListByCountryResponse.ProtoReflect.Descriptor instead.
+func (*ListByCountryResponse) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListByCountryResponse) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *ListByCountryResponse) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *ListByCountryResponse) GetSwiftCodes() []*SwiftCode {
+	if x != nil {
+		return x.SwiftCodes
+	}
+	return nil
+}
+
+type BatchLookupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCodes    []string               `protobuf:"bytes,1,rep,name=swift_codes,json=swiftCodes,proto3" json:"swift_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchLookupRequest) Reset() {
+	*x = BatchLookupRequest{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchLookupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchLookupRequest) ProtoMessage() {}
+
+func (x *BatchLookupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchLookupRequest.ProtoReflect.Descriptor instead.
+func (*BatchLookupRequest) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchLookupRequest) GetSwiftCodes() []string {
+	if x != nil {
+		return x.SwiftCodes
+	}
+	return nil
+}
+
+type BatchLookupResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Found []*SwiftCode           `protobuf:"bytes,1,rep,name=found,proto3" json:"found,omitempty"`
+	// Requested codes, as sent, that are not in the directory.
+	NotFound      []string `protobuf:"bytes,2,rep,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchLookupResponse) Reset() {
+	*x = BatchLookupResponse{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchLookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchLookupResponse) ProtoMessage() {}
+
+func (x *BatchLookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchLookupResponse.ProtoReflect.Descriptor instead.
+func (*BatchLookupResponse) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchLookupResponse) GetFound() []*SwiftCode {
+	if x != nil {
+		return x.Found
+	}
+	return nil
+}
+
+func (x *BatchLookupResponse) GetNotFound() []string {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
+type CreateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// branches, headquarter and version are ignored.
+	SwiftCode     *SwiftCode `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateRequest) Reset() {
+	*x = CreateRequest{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRequest) ProtoMessage() {}
+
+func (x *CreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateRequest) GetSwiftCode() *SwiftCode {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return nil
+}
+
+type CreateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The stored record after normalization, with its new version.
+	SwiftCode     *SwiftCode `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateResponse) Reset() {
+	*x = CreateResponse{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateResponse) ProtoMessage() {}
+
+func (x *CreateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
+func (*CreateResponse) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateResponse) GetSwiftCode() *SwiftCode {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode       string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,2,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Branches        BranchMode             `protobuf:"varint,3,opt,name=branches,proto3,enum=swiftcodes.v1.BranchMode" json:"branches,omitempty"`
+	// Headquarter (BIC11 ending with XXX) receiving the branches in
+	// BRANCH_MODE_REPARENT.
+	NewHeadquarter string `protobuf:"bytes,4,opt,name=new_headquarter,json=newHeadquarter,proto3" json:"new_headquarter,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *DeleteRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *DeleteRequest) GetBranches() BranchMode {
+	if x != nil {
+		return x.Branches
+	}
+	return BranchMode_BRANCH_MODE_UNSPECIFIED
+}
+
+func (x *DeleteRequest) GetNewHeadquarter() string {
+	if x != nil {
+		return x.NewHeadquarter
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ExportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{12}
+}
+
+type ExportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     *SwiftCode             `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportResponse) Reset() {
+	*x = ExportResponse{}
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportResponse) ProtoMessage() {}
+
+func (x *ExportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swiftcodes_v1_swift_codes_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportResponse.ProtoReflect.Descriptor instead.
+func (*ExportResponse) Descriptor() ([]byte, []int) {
+	return file_swiftcodes_v1_swift_codes_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ExportResponse) GetSwiftCode() *SwiftCode {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return nil
+}
+
+var File_swiftcodes_v1_swift_codes_proto protoreflect.FileDescriptor
+
+var file_swiftcodes_v1_swift_codes_proto_rawDesc = string([]byte{
+	0x0a, 0x1f, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2f, 0x76, 0x31, 0x2f,
+	0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x0d, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31,
+	0x22, 0xdf, 0x02, 0x0a, 0x09, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1b, 0x0a,
+	0x09, 0x62, 0x61, 0x6e, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x62, 0x61, 0x6e, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x5f,
+	0x69, 0x73, 0x6f, 0x32, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x72, 0x79, 0x49, 0x73, 0x6f, 0x32, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x73,
+	0x5f, 0x68, 0x65, 0x61, 0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0d, 0x69, 0x73, 0x48, 0x65, 0x61, 0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65,
+	0x72, 0x12, 0x34, 0x0a, 0x08, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x65, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x62,
+	0x72, 0x61, 0x6e, 0x63, 0x68, 0x65, 0x73, 0x12, 0x3f, 0x0a, 0x0b, 0x68, 0x65, 0x61, 0x64, 0x71,
+	0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x73,
+	0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61,
+	0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x52, 0x65, 0x66, 0x52, 0x0b, 0x68, 0x65, 0x61,
+	0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x22, 0x4c, 0x0a, 0x0e, 0x48, 0x65, 0x61, 0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65,
+	0x72, 0x52, 0x65, 0x66, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x61, 0x6e, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x61, 0x6e, 0x6b, 0x4e, 0x61, 0x6d, 0x65,
+	0x22, 0x2b, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x22, 0x46, 0x0a,
+	0x0b, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x0a,
+	0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66,
+	0x74, 0x43, 0x6f, 0x64, 0x65, 0x22, 0x39, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a,
+	0x0c, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x69, 0x73, 0x6f, 0x32, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x49, 0x73, 0x6f, 0x32,
+	0x22, 0x98, 0x01, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x69, 0x73, 0x6f, 0x32, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x49, 0x73, 0x6f, 0x32, 0x12, 0x21, 0x0a,
+	0x0c, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x39, 0x0a, 0x0b, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64,
+	0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52,
+	0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x35, 0x0a, 0x12, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64,
+	0x65, 0x73, 0x22, 0x62, 0x0a, 0x13, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75,
+	0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x05, 0x66, 0x6f, 0x75,
+	0x6e, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74,
+	0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f,
+	0x64, 0x65, 0x52, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x74,
+	0x5f, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x6f,
+	0x74, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x48, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x37, 0x0a, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74,
+	0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66,
+	0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65,
+	0x22, 0x49, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x37, 0x0a, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f,
+	0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65,
+	0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x22, 0xb9, 0x01, 0x0a, 0x0d,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x29, 0x0a, 0x10,
+	0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x35, 0x0a, 0x08, 0x62, 0x72, 0x61, 0x6e, 0x63,
+	0x68, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x73, 0x77, 0x69, 0x66,
+	0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x72, 0x61, 0x6e, 0x63, 0x68,
+	0x4d, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x65, 0x73, 0x12, 0x27,
+	0x0a, 0x0f, 0x6e, 0x65, 0x77, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6e, 0x65, 0x77, 0x48, 0x65, 0x61, 0x64,
+	0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x22, 0x2a, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x22, 0x0f, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x49, 0x0a, 0x0e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x0a, 0x73, 0x77, 0x69, 0x66, 0x74, 0x5f,
+	0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x77, 0x69,
+	0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x77, 0x69, 0x66, 0x74,
+	0x43, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x73, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x2a,
+	0x8c, 0x01, 0x0a, 0x0a, 0x42, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1b,
+	0x0a, 0x17, 0x42, 0x52, 0x41, 0x4e, 0x43, 0x48, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x42,
+	0x52, 0x41, 0x4e, 0x43, 0x48, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x4f, 0x52, 0x50, 0x48, 0x41,
+	0x4e, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x42, 0x52, 0x41, 0x4e, 0x43, 0x48, 0x5f, 0x4d, 0x4f,
+	0x44, 0x45, 0x5f, 0x52, 0x45, 0x46, 0x55, 0x53, 0x45, 0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x42,
+	0x52, 0x41, 0x4e, 0x43, 0x48, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x43, 0x41, 0x53, 0x43, 0x41,
+	0x44, 0x45, 0x10, 0x03, 0x12, 0x18, 0x0a, 0x14, 0x42, 0x52, 0x41, 0x4e, 0x43, 0x48, 0x5f, 0x4d,
+	0x4f, 0x44, 0x45, 0x5f, 0x52, 0x45, 0x50, 0x41, 0x52, 0x45, 0x4e, 0x54, 0x10, 0x04, 0x32, 0xd9,
+	0x03, 0x0a, 0x10, 0x53, 0x77, 0x69, 0x66, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x3c, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x19, 0x2e, 0x73, 0x77, 0x69,
+	0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64,
+	0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x5a, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x23, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63,
+	0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a,
+	0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x12, 0x21, 0x2e, 0x73,
+	0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x22, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x2e,
+	0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x06, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x12, 0x1c, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x77, 0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x47, 0x0a, 0x06, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x2e, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x77, 0x69, 0x66,
+	0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x2b, 0x5a, 0x29, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x2d, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x77,
+	0x69, 0x66, 0x74, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x76, 0x31, 0x3b, 0x73, 0x77, 0x69, 0x66, 0x74,
+	0x63, 0x6f, 0x64, 0x65, 0x73, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_swiftcodes_v1_swift_codes_proto_rawDescOnce sync.Once
+	file_swiftcodes_v1_swift_codes_proto_rawDescData []byte
+)
+
+func file_swiftcodes_v1_swift_codes_proto_rawDescGZIP() []byte {
+	file_swiftcodes_v1_swift_codes_proto_rawDescOnce.Do(func() {
+		file_swiftcodes_v1_swift_codes_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_swiftcodes_v1_swift_codes_proto_rawDesc), len(file_swiftcodes_v1_swift_codes_proto_rawDesc)))
+	})
+	return file_swiftcodes_v1_swift_codes_proto_rawDescData
+}
+
+var file_swiftcodes_v1_swift_codes_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_swiftcodes_v1_swift_codes_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_swiftcodes_v1_swift_codes_proto_goTypes = []any{
+	(BranchMode)(0),               // 0: swiftcodes.v1.BranchMode
+	(*SwiftCode)(nil),             // 1: swiftcodes.v1.SwiftCode
+	(*HeadquarterRef)(nil),        // 2: swiftcodes.v1.HeadquarterRef
+	(*GetRequest)(nil),            // 3: swiftcodes.v1.GetRequest
+	(*GetResponse)(nil),           // 4: swiftcodes.v1.GetResponse
+	(*ListByCountryRequest)(nil),  // 5: swiftcodes.v1.ListByCountryRequest
+	(*ListByCountryResponse)(nil), // 6: swiftcodes.v1.ListByCountryResponse
+	(*BatchLookupRequest)(nil),    // 7: swiftcodes.v1.BatchLookupRequest
+	(*BatchLookupResponse)(nil),   // 8: swiftcodes.v1.BatchLookupResponse
+	(*CreateRequest)(nil),         // 9: swiftcodes.v1.CreateRequest
+	(*CreateResponse)(nil),        // 10: swiftcodes.v1.CreateResponse
+	(*DeleteRequest)(nil),         // 11: swiftcodes.v1.DeleteRequest
+	(*DeleteResponse)(nil),        // 12: swiftcodes.v1.DeleteResponse
+	(*ExportRequest)(nil),         // 13: swiftcodes.v1.ExportRequest
+	(*ExportResponse)(nil),        // 14: swiftcodes.v1.ExportResponse
+}
+var file_swiftcodes_v1_swift_codes_proto_depIdxs = []int32{
+	1,  // 0: swiftcodes.v1.SwiftCode.branches:type_name -> swiftcodes.v1.SwiftCode
+	2,  // 1: swiftcodes.v1.SwiftCode.headquarter:type_name -> swiftcodes.v1.HeadquarterRef
+	1,  // 2: swiftcodes.v1.GetResponse.swift_code:type_name -> swiftcodes.v1.SwiftCode
+	1,  // 3: swiftcodes.v1.ListByCountryResponse.swift_codes:type_name -> swiftcodes.v1.SwiftCode
+	1,  // 4: swiftcodes.v1.BatchLookupResponse.found:type_name -> swiftcodes.v1.SwiftCode
+	1,  // 5: swiftcodes.v1.CreateRequest.swift_code:type_name -> swiftcodes.v1.SwiftCode
+	1,  // 6: swiftcodes.v1.CreateResponse.swift_code:type_name -> swiftcodes.v1.SwiftCode
+	0,  // 7: swiftcodes.v1.DeleteRequest.branches:type_name -> swiftcodes.v1.BranchMode
+	1,  // 8: swiftcodes.v1.ExportResponse.swift_code:type_name -> swiftcodes.v1.SwiftCode
+	3,  // 9: swiftcodes.v1.SwiftCodeService.Get:input_type -> swiftcodes.v1.GetRequest
+	5,  // 10: swiftcodes.v1.SwiftCodeService.ListByCountry:input_type -> swiftcodes.v1.ListByCountryRequest
+	7,  // 11: swiftcodes.v1.SwiftCodeService.BatchLookup:input_type -> swiftcodes.v1.BatchLookupRequest
+	9,  // 12: swiftcodes.v1.SwiftCodeService.Create:input_type -> swiftcodes.v1.CreateRequest
+	11, // 13: swiftcodes.v1.SwiftCodeService.Delete:input_type -> swiftcodes.v1.DeleteRequest
+	13, // 14: swiftcodes.v1.SwiftCodeService.Export:input_type -> swiftcodes.v1.ExportRequest
+	4,  // 15: swiftcodes.v1.SwiftCodeService.Get:output_type -> swiftcodes.v1.GetResponse
+	6,  // 16: swiftcodes.v1.SwiftCodeService.ListByCountry:output_type -> swiftcodes.v1.ListByCountryResponse
+	8,  // 17: swiftcodes.v1.SwiftCodeService.BatchLookup:output_type -> swiftcodes.v1.BatchLookupResponse
+	10, // 18: swiftcodes.v1.SwiftCodeService.Create:output_type -> swiftcodes.v1.CreateResponse
+	12, // 19: swiftcodes.v1.SwiftCodeService.Delete:output_type -> swiftcodes.v1.DeleteResponse
+	14, // 20: swiftcodes.v1.SwiftCodeService.Export:output_type -> swiftcodes.v1.ExportResponse
+	15, // [15:21] is the sub-list for method output_type
+	9,  // [9:15] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_swiftcodes_v1_swift_codes_proto_init() }
+func file_swiftcodes_v1_swift_codes_proto_init() {
+	if File_swiftcodes_v1_swift_codes_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_swiftcodes_v1_swift_codes_proto_rawDesc), len(file_swiftcodes_v1_swift_codes_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_swiftcodes_v1_swift_codes_proto_goTypes,
+		DependencyIndexes: file_swiftcodes_v1_swift_codes_proto_depIdxs,
+		EnumInfos:         file_swiftcodes_v1_swift_codes_proto_enumTypes,
+		MessageInfos:      file_swiftcodes_v1_swift_codes_proto_msgTypes,
+	}.Build()
+	File_swiftcodes_v1_swift_codes_proto = out.File
+	file_swiftcodes_v1_swift_codes_proto_goTypes = nil
+	file_swiftcodes_v1_swift_codes_proto_depIdxs = nil
+}